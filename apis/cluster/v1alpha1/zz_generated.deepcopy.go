@@ -107,7 +107,6 @@ func (in *ClusterList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 	*out = *in
-	out.MultiCluster = in.MultiCluster
 	in.Connection.DeepCopyInto(&out.Connection)
 	return
 }
@@ -183,18 +182,3 @@ func (in *Connection) DeepCopy() *Connection {
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MultiClusterConfig) DeepCopyInto(out *MultiClusterConfig) {
-	*out = *in
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterConfig.
-func (in *MultiClusterConfig) DeepCopy() *MultiClusterConfig {
-	if in == nil {
-		return nil
-	}
-	out := new(MultiClusterConfig)
-	in.DeepCopyInto(out)
-	return out
-}
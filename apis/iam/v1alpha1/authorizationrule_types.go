@@ -0,0 +1,78 @@
+/*
+Copyright 2022 Captain Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ResourceKindAuthorizationRule      = "AuthorizationRule"
+	ResourcesSingularAuthorizationRule = "authorizationrule"
+	ResourcesPluralAuthorizationRule   = "authorizationrules"
+)
+
+// AuthorizationRuleSpec grants the users and groups the verbs on resources in the given regions, clusters
+// and namespaces, the same as rules of the policy file of captain-server
+type AuthorizationRuleSpec struct {
+	Users  []string `json:"users,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+
+	// Regions left empty match every region
+	Regions []string `json:"regions,omitempty"`
+	// Clusters left empty match the host cluster only, "*" matches the host and every member cluster
+	Clusters []string `json:"clusters,omitempty"`
+	// Namespaces left empty match every namespace
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	APIGroups []string `json:"apiGroups,omitempty"`
+	Resources []string `json:"resources,omitempty"`
+	Verbs     []string `json:"verbs"`
+
+	// NonResourceURLs is a set of partial urls that a user should have access to, "*" is allowed as the final step.
+	NonResourceURLs []string `json:"nonResourceURLs,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=true
+// +genclient:nonNamespaced
+// +kubebuilder:printcolumn:name="Users",type="string",JSONPath=".spec.users"
+// +kubebuilder:printcolumn:name="Groups",type="string",JSONPath=".spec.groups"
+// +kubebuilder:printcolumn:name="Verbs",type="string",JSONPath=".spec.verbs"
+// +kubebuilder:resource:scope=Cluster
+
+// AuthorizationRule is the schema for the authorizationrules API, rules are read by captain-server in RuleBased mode
+type AuthorizationRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AuthorizationRuleSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AuthorizationRuleList contains a list of AuthorizationRule
+type AuthorizationRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AuthorizationRule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AuthorizationRule{}, &AuthorizationRuleList{})
+}
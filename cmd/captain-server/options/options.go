@@ -4,13 +4,25 @@ import (
 	"captain/pkg/server"
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/spf13/pflag"
+	"k8s.io/apiserver/pkg/server/dynamiccertificates"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog"
 
 	"captain/pkg/informers"
+	"captain/pkg/server/auditing"
+	"captain/pkg/server/authentication"
+	"captain/pkg/server/authorization"
 	captainserverconfig "captain/pkg/server/config"
+	"captain/pkg/server/impersonation"
+	"captain/pkg/server/logging"
+	"captain/pkg/server/tracing"
+	"captain/pkg/simple/client/cache"
 	"captain/pkg/simple/client/k8s"
 	genericoptions "captain/pkg/simple/server/options"
 
@@ -20,6 +32,7 @@ import (
 type ServerRunOptions struct {
 	ConfigFile              string
 	GenericServerRunOptions *genericoptions.ServerRunOptions
+	ConfigMapOptions        *captainserverconfig.ConfigMapOptions
 	*captainserverconfig.Config
 
 	//
@@ -29,6 +42,7 @@ type ServerRunOptions struct {
 func NewServerRunOptions() *ServerRunOptions {
 	s := &ServerRunOptions{
 		GenericServerRunOptions: genericoptions.NewServerRunOptions(),
+		ConfigMapOptions:        captainserverconfig.NewConfigMapOptions(),
 		Config:                  captainserverconfig.New(),
 	}
 
@@ -36,14 +50,9 @@ func NewServerRunOptions() *ServerRunOptions {
 }
 
 func (s *ServerRunOptions) Flags() (fss cliflag.NamedFlagSets) {
-	fs := fss.FlagSet("generic")
-	fs.BoolVar(&s.DebugMode, "debug", false, "Don't enable this if you don't know what it means.")
-	s.GenericServerRunOptions.AddFlags(fs, s.GenericServerRunOptions)
-	s.KubernetesOptions.AddFlags(fss.FlagSet("kubernetes"), s.KubernetesOptions)
+	fss = s.configFlags()
 
-	s.RedisOptions.AddFlags(fss.FlagSet("redis"), s.RedisOptions)
-
-	fs = fss.FlagSet("klog")
+	fs := fss.FlagSet("klog")
 	local := flag.NewFlagSet("klog", flag.ExitOnError)
 	klog.InitFlags(local)
 	local.VisitAll(func(fl *flag.Flag) {
@@ -54,9 +63,75 @@ func (s *ServerRunOptions) Flags() (fss cliflag.NamedFlagSets) {
 	return fss
 }
 
+// configFlags returns flags of s without klog flags, which are bound to the global state of klog,
+// so that they can be built again on every reload of configuration
+func (s *ServerRunOptions) configFlags() (fss cliflag.NamedFlagSets) {
+	fs := fss.FlagSet("generic")
+	fs.BoolVar(&s.DebugMode, "debug", false, "Don't enable this if you don't know what it means.")
+	s.GenericServerRunOptions.AddFlags(fs, s.GenericServerRunOptions)
+	s.ConfigMapOptions.AddFlags(fs, s.ConfigMapOptions)
+	s.KubernetesOptions.AddFlags(fss.FlagSet("kubernetes"), s.KubernetesOptions)
+
+	s.RedisOptions.AddFlags(fss.FlagSet("redis"), s.RedisOptions)
+
+	s.AuthenticationOptions.AddFlags(fss.FlagSet("authentication"), s.AuthenticationOptions)
+	s.AuthorizationOptions.AddFlags(fss.FlagSet("authorization"), s.AuthorizationOptions)
+	s.AuditingOptions.AddFlags(fss.FlagSet("auditing"), s.AuditingOptions)
+	s.ImpersonationOptions.AddFlags(fss.FlagSet("impersonation"), s.ImpersonationOptions)
+	s.TracingOptions.AddFlags(fss.FlagSet("tracing"), s.TracingOptions)
+	s.SearchOptions.AddFlags(fss.FlagSet("search"), s.SearchOptions)
+
+	return fss
+}
+
+// NewConfigLoader creates the loader of configuration merged from flags changed in fs, ConfigMap
+// and configuration file
+func (s *ServerRunOptions) NewConfigLoader(fs *pflag.FlagSet) (*captainserverconfig.Loader, error) {
+	var client kubernetes.Interface
+	if s.ConfigMapOptions.Enabled() {
+		kubernetesClient, err := k8s.NewKubernetesClient(s.KubernetesOptions)
+		if err != nil {
+			return nil, err
+		}
+		client = kubernetesClient.Kubernetes()
+	}
+
+	overrideFlags := func(conf *captainserverconfig.Config) error {
+		o := &ServerRunOptions{
+			GenericServerRunOptions: genericoptions.NewServerRunOptions(),
+			ConfigMapOptions:        captainserverconfig.NewConfigMapOptions(),
+			Config:                  conf,
+		}
+		target := pflag.NewFlagSet("captain-server", pflag.ContinueOnError)
+		for _, f := range o.configFlags().FlagSets {
+			target.AddFlagSet(f)
+		}
+		return captainserverconfig.OverrideChangedFlags(fs, target)
+	}
+
+	validate := func(conf *captainserverconfig.Config) []error {
+		o := &ServerRunOptions{
+			GenericServerRunOptions: s.GenericServerRunOptions,
+			ConfigMapOptions:        s.ConfigMapOptions,
+			Config:                  conf,
+		}
+		return o.Validate()
+	}
+
+	return captainserverconfig.NewLoader(client, s.ConfigMapOptions, "captain-server", overrideFlags, validate), nil
+}
+
 func (s *ServerRunOptions) NewAPIServer(stopCh <-chan struct{}) (*server.CaptainAPIServer, error) {
 	apiServer := &server.CaptainAPIServer{
-		Config: s.Config,
+		Config:                s.Config,
+		ShutdownDelayDuration: s.GenericServerRunOptions.ShutdownDelayDuration,
+		ShutdownGracePeriod:   s.GenericServerRunOptions.ShutdownGracePeriod,
+	}
+
+	if s.LoggingOptions.Verbosity != 0 {
+		if err := logging.SetVerbosity(s.LoggingOptions.Verbosity); err != nil {
+			return nil, err
+		}
 	}
 
 	kubernetesClient, err := k8s.NewKubernetesClient(s.KubernetesOptions)
@@ -68,11 +143,61 @@ func (s *ServerRunOptions) NewAPIServer(stopCh <-chan struct{}) (*server.Captain
 	informerFactory := informers.NewInformerFactories(kubernetesClient.Kubernetes(), kubernetesClient.Crd())
 	apiServer.InformerFactory = informerFactory
 
-	captainServer := &http.Server{
-		Addr: fmt.Sprintf(":%d", s.GenericServerRunOptions.InsecurePort),
+	if s.RedisOptions != nil && len(s.RedisOptions.Host) != 0 {
+		cacheClient, err := cache.NewRedisClient(s.RedisOptions, stopCh)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to redis service, please check redis status, error: %v", err)
+		}
+		apiServer.CacheClient = cacheClient
+	}
+
+	clientCA, err := authentication.NewClientCA(s.AuthenticationOptions)
+	if err != nil {
+		return nil, err
+	}
+	apiServer.ClientCA = clientCA
+
+	authenticator, err := authentication.NewAuthenticator(s.AuthenticationOptions, clientCA)
+	if err != nil {
+		return nil, err
+	}
+	apiServer.Authenticator = authenticator
+
+	authorizer, err := authorization.NewAuthorizer(s.AuthorizationOptions, kubernetesClient.Config(), stopCh)
+	if err != nil {
+		return nil, err
+	}
+	apiServer.Authorizer = authorizer
+
+	apiServer.Auditing = auditing.NewAuditing(s.AuditingOptions)
+
+	apiServer.Impersonator = impersonation.NewImpersonator(s.ImpersonationOptions)
+
+	tracerProvider, err := tracing.NewTracerProvider(s.TracingOptions, "captain-server")
+	if err != nil {
+		return nil, err
+	}
+	if tracerProvider != nil {
+		apiServer.TracerProvider = tracerProvider
 	}
 
-	apiServer.Server = captainServer
+	if s.GenericServerRunOptions.InsecurePort != 0 {
+		apiServer.Server = &http.Server{
+			Addr: net.JoinHostPort(s.GenericServerRunOptions.BindAddress, strconv.Itoa(s.GenericServerRunOptions.InsecurePort)),
+		}
+	}
+
+	if s.GenericServerRunOptions.SecurePort != 0 {
+		servingCert, err := dynamiccertificates.NewDynamicServingContentFromFiles("serving-cert",
+			s.GenericServerRunOptions.TlsCertFile, s.GenericServerRunOptions.TlsPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		apiServer.ServingCert = servingCert
+		apiServer.SecureServer = &http.Server{
+			Addr: net.JoinHostPort(s.GenericServerRunOptions.BindAddress, strconv.Itoa(s.GenericServerRunOptions.SecurePort)),
+		}
+	}
 
 	return apiServer, nil
 }
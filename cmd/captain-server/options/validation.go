@@ -1,4 +1,3 @@
-
 package options
 
 // Validate validates server run options, to find
@@ -8,8 +7,21 @@ func (s *ServerRunOptions) Validate() []error {
 
 	errors = append(errors, s.GenericServerRunOptions.Validate()...)
 
+	errors = append(errors, s.ConfigMapOptions.Validate()...)
+
 	errors = append(errors, s.KubernetesOptions.Validate()...)
 
+	errors = append(errors, s.AuthenticationOptions.Validate()...)
+
+	errors = append(errors, s.AuthorizationOptions.Validate()...)
+
+	errors = append(errors, s.AuditingOptions.Validate()...)
+
+	errors = append(errors, s.LoggingOptions.Validate()...)
+
+	errors = append(errors, s.TracingOptions.Validate()...)
+
+	errors = append(errors, s.SearchOptions.Validate()...)
 
 	return errors
 }
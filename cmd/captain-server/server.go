@@ -25,6 +25,7 @@ func NewAPIServerCommand() *cobra.Command {
 	if err == nil {
 		s = &options.ServerRunOptions{
 			GenericServerRunOptions: s.GenericServerRunOptions,
+			ConfigMapOptions:        s.ConfigMapOptions,
 			Config:                  conf,
 		}
 	} else {
@@ -40,7 +41,20 @@ cluster's shared state through which all other components interact.`,
 			if errs := s.Validate(); len(errs) != 0 {
 				return utilerrors.NewAggregate(errs)
 			}
-			return Run(s, captainserverconfig.WatchConfigChange(), signals.SetupSignalHandler())
+			ctx := signals.SetupSignalHandler()
+
+			// flags take precedence over ConfigMap, and ConfigMap takes precedence over configuration file
+			loader, err := s.NewConfigLoader(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			conf, err := loader.Load()
+			if err != nil {
+				return err
+			}
+			s.Config = conf
+
+			return Run(s, loader.Watch(ctx.Done()), ctx)
 		},
 		SilenceUsage: true,
 	}
@@ -71,41 +85,9 @@ cluster's shared state through which all other components interact.`,
 	return cmd
 }
 
+// Run runs captain-server until ctx is done, configuration changes received from configCh are applied
+// to the running server, the server is never restarted for them
 func Run(s *options.ServerRunOptions, configCh <-chan captainserverconfig.Config, ctx context.Context) error {
-	ictx, cancelFunc := context.WithCancel(context.TODO())
-	errCh := make(chan error)
-	defer close(errCh)
-	go func() {
-		if err := run(s, ictx); err != nil {
-			errCh <- err
-		}
-	}()
-
-	// The ctx (signals.SetupSignalHandler()) is to control the entire program life cycle,
-	// The ictx(internal context)  is created here to control the life cycle of the captain-server(http server, sharedInformer etc.)
-	// when config change, stop server and renew context, start new server
-	for {
-		select {
-		case <-ctx.Done():
-			cancelFunc()
-			return nil
-		case cfg := <-configCh:
-			cancelFunc()
-			s.Config = &cfg
-			ictx, cancelFunc = context.WithCancel(context.TODO())
-			go func() {
-				if err := run(s, ictx); err != nil {
-					errCh <- err
-				}
-			}()
-		case err := <-errCh:
-			cancelFunc()
-			return err
-		}
-	}
-}
-
-func run(s *options.ServerRunOptions, ctx context.Context) error {
 	apiserver, err := s.NewAPIServer(ctx.Done())
 	if err != nil {
 		return err
@@ -116,9 +98,23 @@ func run(s *options.ServerRunOptions, ctx context.Context) error {
 		return err
 	}
 
-	err = apiserver.Run(ctx)
-	if err == http.ErrServerClosed {
-		return nil
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- apiserver.Run(ctx)
+	}()
+
+	for {
+		select {
+		case cfg := <-configCh:
+			klog.V(0).Info("Configuration changed, applying it")
+			if err := apiserver.ApplyConfig(&cfg); err != nil {
+				klog.Errorf("Failed to apply configuration change: %v", err)
+			}
+		case err := <-errCh:
+			if err == http.ErrServerClosed {
+				return nil
+			}
+			return err
+		}
 	}
-	return err
 }
@@ -1,6 +1,8 @@
 package app
 
 import (
+	"time"
+
 	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
@@ -10,25 +12,35 @@ import (
 	"captain/pkg/simple/client/multicluster"
 )
 
+// resyncPeriodSetter is implemented by controllers whose resync period can be changed at runtime
+type resyncPeriodSetter interface {
+	SetResyncPeriod(period time.Duration)
+}
+
+// addControllers adds enabled controllers to mgr, controllers whose resync period can be changed at
+// runtime are returned
 func addControllers(
 	mgr manager.Manager,
 	client k8s.Client,
 	informerFactory informers.InformerFactory,
 	options *k8s.KubernetesOptions,
 	multiClusterOptions *multicluster.Options,
-	stopCh <-chan struct{}) error {
+	stopCh <-chan struct{}) ([]resyncPeriodSetter, error) {
 
 	captainInformer := informerFactory.CaptainSharedInformerFactory()
 
 	multiClusterEnabled := multiClusterOptions.Enable
 
 	var clusterController manager.Runnable
+	var resyncPeriodSetters []resyncPeriodSetter
 	if multiClusterEnabled {
-		clusterController = cluster.NewClusterController(
+		c := cluster.NewClusterController(
 			client.Kubernetes(),
 			client.Config(),
 			captainInformer.Cluster().V1alpha1().Clusters(),
 			client.Crd().V1beta1().Clusters(), multiClusterOptions)
+		clusterController = c
+		resyncPeriodSetters = append(resyncPeriodSetters, c)
 	}
 
 	controllers := map[string]manager.Runnable{
@@ -43,9 +55,9 @@ func addControllers(
 
 		if err := mgr.Add(ctrl); err != nil {
 			klog.Error(err, "add controller to manager failed", "name", name)
-			return err
+			return nil, err
 		}
 	}
 
-	return nil
+	return resyncPeriodSetters, nil
 }
@@ -6,10 +6,12 @@ import (
 	"time"
 
 	"github.com/spf13/pflag"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/leaderelection"
 	cliflag "k8s.io/component-base/cli/flag"
 	"k8s.io/klog"
 
+	controllerconfig "captain/pkg/server/config"
 	"captain/pkg/simple/client/k8s"
 	"captain/pkg/simple/client/multicluster"
 )
@@ -20,6 +22,7 @@ type CaptainControllerManagerOptions struct {
 	LeaderElect         bool
 	LeaderElection      *leaderelection.LeaderElectionConfig
 	WebhookCertDir      string
+	ConfigMapOptions    *controllerconfig.ConfigMapOptions
 }
 
 func NewCaptainControllerManagerOptions() *CaptainControllerManagerOptions {
@@ -31,20 +34,39 @@ func NewCaptainControllerManagerOptions() *CaptainControllerManagerOptions {
 			RenewDeadline: 15 * time.Second,
 			RetryPeriod:   5 * time.Second,
 		},
-		LeaderElect:    false,
-		WebhookCertDir: "",
+		LeaderElect:      false,
+		WebhookCertDir:   "",
+		ConfigMapOptions: controllerconfig.NewConfigMapOptions(),
 	}
 
 	return s
 }
 
 func (s *CaptainControllerManagerOptions) Flags() cliflag.NamedFlagSets {
+	fss := s.configFlags()
+
+	kfs := fss.FlagSet("klog")
+	local := flag.NewFlagSet("klog", flag.ExitOnError)
+	klog.InitFlags(local)
+	local.VisitAll(func(fl *flag.Flag) {
+		fl.Name = strings.Replace(fl.Name, "_", "-", -1)
+		kfs.AddGoFlag(fl)
+	})
+
+	return fss
+}
+
+// configFlags returns flags of s without klog flags, which are bound to the global state of klog,
+// so that they can be built again on every reload of configuration
+func (s *CaptainControllerManagerOptions) configFlags() cliflag.NamedFlagSets {
 	fss := cliflag.NamedFlagSets{}
 
 	s.KubernetesOptions.AddFlags(fss.FlagSet("kubernetes"), s.KubernetesOptions)
 
 	s.MultiClusterOptions.AddFlags(fss.FlagSet("multicluster"), s.MultiClusterOptions)
 
+	s.ConfigMapOptions.AddFlags(fss.FlagSet("configmap"), s.ConfigMapOptions)
+
 	fs := fss.FlagSet("leaderelection")
 	s.bindLeaderElectionFlags(s.LeaderElection, fs)
 
@@ -57,14 +79,6 @@ func (s *CaptainControllerManagerOptions) Flags() cliflag.NamedFlagSets {
 		"if not set, webhook server would look up the server key and certificate in"+
 		"{TempDir}/k8s-webhook-server/serving-certs")
 
-	kfs := fss.FlagSet("klog")
-	local := flag.NewFlagSet("klog", flag.ExitOnError)
-	klog.InitFlags(local)
-	local.VisitAll(func(fl *flag.Flag) {
-		fl.Name = strings.Replace(fl.Name, "_", "-", -1)
-		kfs.AddGoFlag(fl)
-	})
-
 	return fss
 }
 
@@ -72,9 +86,43 @@ func (s *CaptainControllerManagerOptions) Validate() []error {
 	var errs []error
 	errs = append(errs, s.KubernetesOptions.Validate()...)
 	errs = append(errs, s.MultiClusterOptions.Validate()...)
+	errs = append(errs, s.ConfigMapOptions.Validate()...)
 	return errs
 }
 
+// NewConfigLoader creates the loader of configuration merged from flags changed in fs, ConfigMap
+// and configuration file, only kubernetes and multicluster options are used by controller-manager
+func (s *CaptainControllerManagerOptions) NewConfigLoader(fs *pflag.FlagSet) (*controllerconfig.Loader, error) {
+	var client kubernetes.Interface
+	if s.ConfigMapOptions.Enabled() {
+		kubernetesClient, err := k8s.NewKubernetesClient(s.KubernetesOptions)
+		if err != nil {
+			return nil, err
+		}
+		client = kubernetesClient.Kubernetes()
+	}
+
+	overrideFlags := func(conf *controllerconfig.Config) error {
+		o := NewCaptainControllerManagerOptions()
+		o.KubernetesOptions = conf.KubernetesOptions
+		o.MultiClusterOptions = conf.MultiClusterOptions
+		target := pflag.NewFlagSet("controller-manager", pflag.ContinueOnError)
+		for _, f := range o.configFlags().FlagSets {
+			target.AddFlagSet(f)
+		}
+		return controllerconfig.OverrideChangedFlags(fs, target)
+	}
+
+	validate := func(conf *controllerconfig.Config) []error {
+		var errs []error
+		errs = append(errs, conf.KubernetesOptions.Validate()...)
+		errs = append(errs, conf.MultiClusterOptions.Validate()...)
+		return errs
+	}
+
+	return controllerconfig.NewLoader(client, s.ConfigMapOptions, "controller-manager", overrideFlags, validate), nil
+}
+
 func (s *CaptainControllerManagerOptions) bindLeaderElectionFlags(l *leaderelection.LeaderElectionConfig, fs *pflag.FlagSet) {
 	fs.DurationVar(&l.LeaseDuration, "leader-elect-lease-duration", l.LeaseDuration, ""+
 		"The duration that non-leader candidates will wait after observing a leadership "+
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"reflect"
 
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,6 +34,7 @@ func NewControllerManagerCommand() *cobra.Command {
 			LeaderElection:      s.LeaderElection,
 			LeaderElect:         s.LeaderElect,
 			WebhookCertDir:      s.WebhookCertDir,
+			ConfigMapOptions:    s.ConfigMapOptions,
 		}
 	} else {
 		klog.Fatal("Failed to load configuration from disk", err)
@@ -47,7 +49,22 @@ func NewControllerManagerCommand() *cobra.Command {
 				os.Exit(1)
 			}
 
-			if err = run(s, signals.SetupSignalHandler()); err != nil {
+			// flags take precedence over ConfigMap, and ConfigMap takes precedence over configuration file
+			loader, err := s.NewConfigLoader(cmd.Flags())
+			if err != nil {
+				klog.Error(err)
+				os.Exit(1)
+			}
+			conf, err := loader.Load()
+			if err != nil {
+				klog.Error(err)
+				os.Exit(1)
+			}
+			s.KubernetesOptions = conf.KubernetesOptions
+			s.MultiClusterOptions = conf.MultiClusterOptions
+
+			ctx := signals.SetupSignalHandler()
+			if err = run(s, loader.Watch(ctx.Done()), ctx); err != nil {
 				klog.Error(err)
 				os.Exit(1)
 			}
@@ -82,7 +99,7 @@ func NewControllerManagerCommand() *cobra.Command {
 	return cmd
 }
 
-func run(s *options.CaptainControllerManagerOptions, ctx context.Context) error {
+func run(s *options.CaptainControllerManagerOptions, configCh <-chan controllerconfig.Config, ctx context.Context) error {
 
 	kubernetesClient, err := k8s.NewKubernetesClient(s.KubernetesOptions)
 	if err != nil {
@@ -131,15 +148,18 @@ func run(s *options.CaptainControllerManagerOptions, ctx context.Context) error
 	// register common meta types into schemas.
 	metav1.AddToGroupVersion(mgr.GetScheme(), metav1.SchemeGroupVersion)
 
-	if err = addControllers(mgr,
+	resyncPeriodSetters, err := addControllers(mgr,
 		kubernetesClient,
 		informerFactory,
 		s.KubernetesOptions,
 		s.MultiClusterOptions,
-		ctx.Done()); err != nil {
+		ctx.Done())
+	if err != nil {
 		klog.Fatalf("unable to register controllers to the manager: %v", err)
 	}
 
+	go watchConfigChange(s, configCh, resyncPeriodSetters, ctx.Done())
+
 	// Start cache data after all informer is registered
 	klog.V(0).Info("Starting cache resource from apiserver...")
 	informerFactory.Start(ctx.Done())
@@ -151,3 +171,37 @@ func run(s *options.CaptainControllerManagerOptions, ctx context.Context) error
 
 	return nil
 }
+
+// watchConfigChange applies configuration changes at runtime. Only the resync period of cluster controller
+// can be changed without restarting, changes of other options controller-manager uses are reported as warnings
+func watchConfigChange(s *options.CaptainControllerManagerOptions, configCh <-chan controllerconfig.Config,
+	resyncPeriodSetters []resyncPeriodSetter, stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case cfg := <-configCh:
+			if cfg.MultiClusterOptions != nil {
+				current := *s.MultiClusterOptions
+				if period := cfg.MultiClusterOptions.ClusterControllerResyncPeriod; period != current.ClusterControllerResyncPeriod {
+					if period <= 0 {
+						klog.Errorf("Invalid cluster controller resync period %s, keep using %s", period, current.ClusterControllerResyncPeriod)
+					} else {
+						for _, setter := range resyncPeriodSetters {
+							setter.SetResyncPeriod(period)
+						}
+						klog.V(0).Infof("Cluster controller resync period changed to %s", period)
+						current.ClusterControllerResyncPeriod = period
+					}
+				}
+				if !reflect.DeepEqual(&current, cfg.MultiClusterOptions) {
+					klog.Warning("configuration multicluster changed, restart controller-manager to apply the change")
+				}
+				s.MultiClusterOptions = &current
+			}
+			if cfg.KubernetesOptions != nil && !reflect.DeepEqual(s.KubernetesOptions, cfg.KubernetesOptions) {
+				klog.Warning("configuration kubernetes changed, restart controller-manager to apply the change")
+			}
+		}
+	}
+}
@@ -16,6 +16,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -36,6 +37,12 @@ func (cp clusterProvider) Get(namespace, name string) (runtime.Object, error) {
 }
 
 func (cp clusterProvider) List(namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	if err := alpha1.ValidateContinue(query.Continue); err != nil {
+		return nil, err
+	}
+	if err := alpha1.ValidateJSONPath(query); err != nil {
+		return nil, err
+	}
 	raw, err := cp.sharedInformers.Cluster().V1alpha1().Clusters().Lister().List(query.GetSelector())
 	if err != nil {
 		return nil, err
@@ -46,9 +53,23 @@ func (cp clusterProvider) List(namespace string, query *query.QueryInfo) (*respo
 		result = append(result, deploy)
 	}
 
+	result, err = alpha1.SelectFields(result, query, cp.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+// SelectableFields returns fields of clusters supported by field selectors
+func (cp clusterProvider) SelectableFields(object runtime.Object) fields.Set {
+	cluster, ok := object.(*v1alpha1.Cluster)
+	if !ok {
+		cluster = &v1alpha1.Cluster{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&cluster.ObjectMeta, false)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	cluster, ok := object.(*v1alpha1.Cluster)
 	if !ok {
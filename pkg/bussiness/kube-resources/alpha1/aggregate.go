@@ -0,0 +1,80 @@
+package alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+)
+
+const (
+	// AnnotationRegion and AnnotationCluster tag objects of aggregated lists with the member cluster
+	// they are listed from
+	AnnotationRegion  = "resources.captain.io/region"
+	AnnotationCluster = "resources.captain.io/cluster"
+)
+
+// MemberQuery returns the query of listing objects matching q from every member cluster of an
+// aggregated list. Objects are filtered from caches of member clusters, and all of them are returned,
+// so that they are sorted and paged globally by AggregatedList. Objects are projected after they are sorted
+func MemberQuery(q *query.QueryInfo) *query.QueryInfo {
+	member := *q
+	member.Pagination = query.NoPagination
+	member.Limit = 0
+	member.Continue = ""
+	member.Fields = nil
+	// managed fields are stripped from objects of pages only, objects of caches are not copied
+	member.ShowManagedFields = true
+	return &member
+}
+
+// TagObject returns a copy of obj annotated with region and cluster it is listed from
+func TagObject(obj runtime.Object, region, cluster string) runtime.Object {
+	obj = obj.DeepCopyObject()
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return obj
+	}
+	annotations := o.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if len(region) != 0 {
+		annotations[AnnotationRegion] = region
+	}
+	annotations[AnnotationCluster] = cluster
+	o.SetAnnotations(annotations)
+	return obj
+}
+
+// ClusterObjects are objects listed from a member cluster by MemberQuery
+type ClusterObjects struct {
+	Region  string
+	Cluster string
+	Objects []runtime.Object
+}
+
+// AggregatedList sorts and pages objects listed from member clusters by MemberQuery. Objects are
+// already filtered by providers of member clusters, so only sortBy and pagination of q are applied.
+// Objects of the page only are tagged with clusters by TagObject, so that objects of caches are not
+// copied by every page of lists paged by continue tokens, e.g. exports by chunks. Continue tokens are
+// the same as lists from informer caches, callers should validate them by ValidateContinue
+func AggregatedList(lists []ClusterObjects, q *query.QueryInfo, compareFunc CompareFunc, clusterErrors []response.ClusterError) *response.ListResult {
+	var objects []runtime.Object
+	clusters := make(map[runtime.Object]*ClusterObjects)
+	for i := range lists {
+		for _, obj := range lists[i].Objects {
+			objects = append(objects, obj)
+			clusters[obj] = &lists[i]
+		}
+	}
+	tag := func(obj runtime.Object) runtime.Object {
+		list := clusters[obj]
+		return TagObject(obj, list.Region, list.Cluster)
+	}
+
+	result := pageList(objects, q, compareFunc, tag, ProjectFunc(q))
+	result.ClusterErrors = clusterErrors
+	return result
+}
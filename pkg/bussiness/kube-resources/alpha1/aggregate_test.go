@@ -0,0 +1,53 @@
+package alpha1
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+)
+
+func TestAggregatedList(t *testing.T) {
+	// the same object in two clusters, and an object only in the second cluster
+	first, second, other := newTestConfigMap("a"), newTestConfigMap("a"), newTestConfigMap("b")
+	second.UID, other.UID = types.UID("a-2"), types.UID("b-2")
+	lists := []ClusterObjects{
+		{Region: "r1", Cluster: "c1", Objects: []runtime.Object{first}},
+		{Region: "r1", Cluster: "c2", Objects: []runtime.Object{second, other}},
+	}
+
+	q := query.New()
+	q.SortBy = query.FieldName
+	q.Ascending = true
+	q.Limit = 2
+	// filters are applied by member clusters and ignored when merging
+	q.JSONPathFilters = []string{".metadata.name==c"}
+	member := MemberQuery(q)
+	if member.IsCursorPagination() || member.Pagination.PageSize < 3 {
+		t.Errorf("expected member clusters to return all objects, got %+v", member)
+	}
+
+	clusterErrors := []response.ClusterError{{Region: "r1", Cluster: "c3", Error: "cluster is not ready"}}
+	page := AggregatedList(lists, q, compareTestObjects, clusterErrors)
+	if len(page.Items) != 2 || len(page.Continue) == 0 || len(page.ClusterErrors) != 1 {
+		t.Fatalf("unexpected first page %+v", page)
+	}
+	for i, cluster := range []string{"c1", "c2"} {
+		if got := page.Items[i].(*v1.ConfigMap).Annotations[AnnotationCluster]; got != cluster {
+			t.Errorf("expected item %d listed from %s, got %s", i, cluster, got)
+		}
+	}
+
+	q.Continue = page.Continue
+	page = AggregatedList(lists, q, compareTestObjects, nil)
+	if len(page.Items) != 1 || page.Items[0].(*v1.ConfigMap).Name != "b" {
+		t.Errorf("unexpected second page %+v", page)
+	}
+	if len(first.Annotations) != 0 || len(other.Annotations) != 0 {
+		t.Errorf("expected objects listed from clusters not changed, got %v and %v", first.Annotations, other.Annotations)
+	}
+}
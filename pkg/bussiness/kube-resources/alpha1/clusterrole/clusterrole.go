@@ -6,7 +6,9 @@ import (
 	"captain/pkg/unify/response"
 	"captain/pkg/utils/k8sutil"
 	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 )
 
@@ -34,9 +36,28 @@ func (cr clusterRoleProvider) List(namespace string, query *query.QueryInfo) (*r
 		result = append(result, nasp)
 	}
 
+	result, err = alpha1.SelectFields(result, query, cr.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+// Watch watches clusterroles from informers, namespace is ignored since they are cluster scoped
+func (cr clusterRoleProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(cr.informers.Rbac().V1().ClusterRoles().Informer(), "", query, filter, cr.SelectableFields)
+}
+
+// SelectableFields returns fields of clusterroles supported by field selectors, the same as kube-apiserver
+func (cr clusterRoleProvider) SelectableFields(object runtime.Object) fields.Set {
+	clusterRole, ok := object.(*rbac.ClusterRole)
+	if !ok {
+		clusterRole = &rbac.ClusterRole{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&clusterRole.ObjectMeta, false)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	clusterRole, ok := object.(*rbac.ClusterRole)
 	if !ok {
@@ -5,7 +5,9 @@ import (
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 )
 
@@ -33,9 +35,28 @@ func (cr clusterRoleBingdingProvider) List(namespace string, query *query.QueryI
 		result = append(result, roleBinding)
 	}
 
+	result, err = alpha1.SelectFields(result, query, cr.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+// Watch watches clusterrolebindings from informers, namespace is ignored since they are cluster scoped
+func (cr clusterRoleBingdingProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(cr.informers.Rbac().V1().ClusterRoleBindings().Informer(), "", query, filter, cr.SelectableFields)
+}
+
+// SelectableFields returns fields of clusterrolebindings supported by field selectors, the same as kube-apiserver
+func (cr clusterRoleBingdingProvider) SelectableFields(object runtime.Object) fields.Set {
+	role, ok := object.(*rbacv1.ClusterRoleBinding)
+	if !ok {
+		role = &rbacv1.ClusterRoleBinding{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&role.ObjectMeta, false)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	role, ok := object.(*rbacv1.ClusterRoleBinding)
 
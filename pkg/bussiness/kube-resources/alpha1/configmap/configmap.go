@@ -5,7 +5,9 @@ import (
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 )
 
@@ -32,9 +34,27 @@ func (cm configmapProvider) List(namespace string, query *query.QueryInfo) (*res
 		result = append(result, configMap)
 	}
 
+	result, err = alpha1.SelectFields(result, query, cm.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+func (cm configmapProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(cm.sharedInformers.Core().V1().ConfigMaps().Informer(), namespace, query, filter, cm.SelectableFields)
+}
+
+// SelectableFields returns fields of configmaps supported by field selectors, the same as kube-apiserver
+func (cm configmapProvider) SelectableFields(object runtime.Object) fields.Set {
+	configMap, ok := object.(*corev1.ConfigMap)
+	if !ok {
+		configMap = &corev1.ConfigMap{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&configMap.ObjectMeta, true)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	configMap, ok := object.(*corev1.ConfigMap)
 	if !ok {
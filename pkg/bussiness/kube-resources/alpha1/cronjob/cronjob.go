@@ -5,7 +5,9 @@ import (
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	"k8s.io/api/batch/v1beta1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 	"strings"
 )
@@ -40,9 +42,27 @@ func (cj cronjobProvider) List(namespace string, query *query.QueryInfo) (*respo
 		result = append(result, nasp)
 	}
 
+	result, err = alpha1.SelectFields(result, query, cj.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+func (cj cronjobProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(cj.informers.Batch().V1beta1().CronJobs().Informer(), namespace, query, filter, cj.SelectableFields)
+}
+
+// SelectableFields returns fields of cronjobs supported by field selectors, the same as kube-apiserver
+func (cj cronjobProvider) SelectableFields(object runtime.Object) fields.Set {
+	cronJob, ok := object.(*v1beta1.CronJob)
+	if !ok {
+		cronJob = &v1beta1.CronJob{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&cronJob.ObjectMeta, true)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	cronJob, ok := object.(*v1beta1.CronJob)
 	if !ok {
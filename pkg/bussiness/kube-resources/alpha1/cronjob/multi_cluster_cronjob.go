@@ -3,48 +3,83 @@ package cronjob
 import (
 	"context"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 
 	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
+	"captain/pkg/utils/clustercache"
 	"captain/pkg/utils/clusterclient"
 )
 
 type mcCronJobrovider struct {
 	clusterclient.ClusterClients
+	caches *clustercache.Manager
 }
 
-func NewMCResProvider(clients clusterclient.ClusterClients) mcCronJobrovider {
-	return mcCronJobrovider{ClusterClients: clients}
+// cronjobs of member clusters are served by batch/v1, rather than batch/v1beta1 of the host cluster
+var gvr = batchv1.SchemeGroupVersion.WithResource("cronjobs")
+
+func NewMCResProvider(clients clusterclient.ClusterClients, caches *clustercache.Manager) mcCronJobrovider {
+	return mcCronJobrovider{ClusterClients: clients, caches: caches}
 }
 
-func (pd mcCronJobrovider) Get(region, cluster, namespace, name string) (runtime.Object, error) {
-	cli, err := pd.GetClientSet(region, cluster)
+func (pd mcCronJobrovider) Get(ctx context.Context, region, cluster, namespace, name string) (runtime.Object, error) {
+	informers, err := pd.caches.KubernetesSharedInformerFactory(ctx, region, cluster, gvr)
 	if err != nil {
 		return nil, err
 	}
 
-	return cli.BatchV1().CronJobs(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	return informers.Batch().V1().CronJobs().Lister().CronJobs(namespace).Get(name)
 }
 
-func (pd mcCronJobrovider) List(region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
-	cli, err := pd.GetClientSet(region, cluster)
+func (pd mcCronJobrovider) List(ctx context.Context, region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	informers, err := pd.caches.KubernetesSharedInformerFactory(ctx, region, cluster, gvr)
 	if err != nil {
 		return nil, err
 	}
-	list, err := cli.BatchV1().CronJobs(namespace).List(context.Background(), metav1.ListOptions{LabelSelector: query.LabelSelector})
+	raw, err := informers.Batch().V1().CronJobs().Lister().CronJobs(namespace).List(query.GetSelector())
 	if err != nil {
 		return nil, err
 	}
 
 	var result []runtime.Object
-	if list != nil && list.Items != nil {
-		for i := 0; i < len(list.Items); i++ {
-			result = append(result, &list.Items[i])
-		}
+	for _, cronJob := range raw {
+		result = append(result, cronJob)
+	}
+
+	result, err = alpha1.SelectFields(result, query, pd.SelectableFields)
+	if err != nil {
+		return nil, err
 	}
 
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
+
+// SelectableFields returns fields of cronjobs of member clusters supported by field selectors
+func (pd mcCronJobrovider) SelectableFields(object runtime.Object) fields.Set {
+	cronJob, ok := object.(*batchv1.CronJob)
+	if !ok {
+		cronJob = &batchv1.CronJob{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&cronJob.ObjectMeta, true)
+}
+
+func (pd mcCronJobrovider) Compare(left, right runtime.Object, field query.Field) bool {
+	return compareFunc(left, right, field)
+}
+
+func (pd mcCronJobrovider) Watch(ctx context.Context, region, cluster, namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	cli, err := pd.GetClientSet(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	w, err := cli.BatchV1().CronJobs(namespace).Watch(ctx, alpha1.WatchOptions(query))
+	if err != nil {
+		return nil, err
+	}
+	return alpha1.FilterWatch(w, query, filter)
+}
@@ -5,7 +5,9 @@ import (
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 	"strings"
 )
@@ -40,9 +42,27 @@ func (dms daemonsetProvider) List(namespace string, query *query.QueryInfo) (*re
 		result = append(result, nasp)
 	}
 
+	result, err = alpha1.SelectFields(result, query, dms.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+func (dms daemonsetProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(dms.informers.Apps().V1().DaemonSets().Informer(), namespace, query, filter, dms.SelectableFields)
+}
+
+// SelectableFields returns fields of daemonsets supported by field selectors, the same as kube-apiserver
+func (dms daemonsetProvider) SelectableFields(object runtime.Object) fields.Set {
+	daemonSet, ok := object.(*appsv1.DaemonSet)
+	if !ok {
+		daemonSet = &appsv1.DaemonSet{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&daemonSet.ObjectMeta, true)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	daemonSet, ok := object.(*appsv1.DaemonSet)
 	if !ok {
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	v1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 )
 
@@ -41,9 +43,27 @@ func (dp deployProvider) List(namespace string, query *query.QueryInfo) (*respon
 		result = append(result, deploy)
 	}
 
+	result, err = alpha1.SelectFields(result, query, dp.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+func (dp deployProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(dp.sharedInformers.Apps().V1().Deployments().Informer(), namespace, query, filter, dp.SelectableFields)
+}
+
+// SelectableFields returns fields of deployments supported by field selectors, the same as kube-apiserver
+func (dp deployProvider) SelectableFields(object runtime.Object) fields.Set {
+	deployment, ok := object.(*v1.Deployment)
+	if !ok {
+		deployment = &v1.Deployment{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&deployment.ObjectMeta, true)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	deployment, ok := object.(*v1.Deployment)
 	if !ok {
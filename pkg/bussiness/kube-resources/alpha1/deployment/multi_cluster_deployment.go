@@ -3,48 +3,58 @@ package deployment
 import (
 	"context"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 
 	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
+	"captain/pkg/utils/clustercache"
 	"captain/pkg/utils/clusterclient"
 )
 
 type mcDeploymentProvider struct {
 	clusterclient.ClusterClients
+	caches *clustercache.Manager
 }
 
-func NewMCResProvider(clients clusterclient.ClusterClients) mcDeploymentProvider {
-	return mcDeploymentProvider{ClusterClients: clients}
+var gvr = appsv1.SchemeGroupVersion.WithResource("deployments")
+
+func NewMCResProvider(clients clusterclient.ClusterClients, caches *clustercache.Manager) mcDeploymentProvider {
+	return mcDeploymentProvider{ClusterClients: clients, caches: caches}
 }
 
-func (pd mcDeploymentProvider) Get(region, cluster, namespace, name string) (runtime.Object, error) {
-	cli, err := pd.GetClientSet(region, cluster)
+func (pd mcDeploymentProvider) Get(ctx context.Context, region, cluster, namespace, name string) (runtime.Object, error) {
+	informers, err := pd.caches.KubernetesSharedInformerFactory(ctx, region, cluster, gvr)
 	if err != nil {
 		return nil, err
 	}
 
-	return cli.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	return New(informers).Get(namespace, name)
 }
 
-func (pd mcDeploymentProvider) List(region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
-	cli, err := pd.GetClientSet(region, cluster)
+func (pd mcDeploymentProvider) List(ctx context.Context, region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	informers, err := pd.caches.KubernetesSharedInformerFactory(ctx, region, cluster, gvr)
 	if err != nil {
 		return nil, err
 	}
-	list, err := cli.AppsV1().Deployments(namespace).List(context.Background(), metav1.ListOptions{LabelSelector: query.LabelSelector})
+
+	return New(informers).List(namespace, query)
+}
+
+func (pd mcDeploymentProvider) Compare(left, right runtime.Object, field query.Field) bool {
+	return compareFunc(left, right, field)
+}
+
+func (pd mcDeploymentProvider) Watch(ctx context.Context, region, cluster, namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
 	}
-
-	var result []runtime.Object
-	if list != nil && list.Items != nil {
-		for i := 0; i < len(list.Items); i++ {
-			result = append(result, &list.Items[i])
-		}
+	w, err := cli.AppsV1().Deployments(namespace).Watch(ctx, alpha1.WatchOptions(query))
+	if err != nil {
+		return nil, err
 	}
-
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.FilterWatch(w, query, filter)
 }
@@ -0,0 +1,307 @@
+package exporters
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+)
+
+// Format is the format lists are exported in
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatCSV    Format = "csv"
+	FormatYAML   Format = "yaml"
+	FormatNDJSON Format = "ndjson"
+)
+
+const (
+	MIMECSV    = "text/csv"
+	MIMEYAML   = "application/yaml"
+	MIMENDJSON = "application/x-ndjson"
+)
+
+// MIMETypes are media types of exports, routes of lists should produce them
+var MIMETypes = []string{MIMECSV, MIMEYAML, MIMENDJSON}
+
+// mimeFormats are formats of media types in Accept, including aliases used by clients
+var mimeFormats = map[string]Format{
+	"application/json":      FormatJSON,
+	"*/*":                   FormatJSON,
+	MIMECSV:                 FormatCSV,
+	MIMEYAML:                FormatYAML,
+	"application/x-yaml":    FormatYAML,
+	"text/yaml":             FormatYAML,
+	MIMENDJSON:              FormatNDJSON,
+	"application/ndjson":    FormatNDJSON,
+	"application/jsonlines": FormatNDJSON,
+}
+
+// defaultColumns are columns of CSV exports without fields
+var defaultColumns = []string{"metadata.namespace", "metadata.name", "metadata.creationTimestamp"}
+
+// Columns of CSV exports naming clusters of objects aggregated from member clusters
+const (
+	ColumnRegion  = "region"
+	ColumnCluster = "cluster"
+)
+
+// Negotiate returns the format of a list, by the format parameter if it is set, or by the first
+// media type of accept known. JSON is returned by default, and an error if format is unknown
+func Negotiate(format, accept string) (Format, error) {
+	if len(format) != 0 {
+		switch f := Format(strings.ToLower(format)); f {
+		case FormatJSON, FormatCSV, FormatYAML, FormatNDJSON:
+			return f, nil
+		}
+		return "", fmt.Errorf("unknown format %s, supported formats are json, csv, yaml and ndjson", format)
+	}
+
+	for _, mediaType := range strings.Split(accept, ",") {
+		mimeType, _, err := mime.ParseMediaType(strings.TrimSpace(mediaType))
+		if err != nil {
+			continue
+		}
+		if f, ok := mimeFormats[mimeType]; ok {
+			return f, nil
+		}
+	}
+	return FormatJSON, nil
+}
+
+// ContentType returns the media type of exports of format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return MIMECSV + "; charset=utf-8"
+	case FormatYAML:
+		return MIMEYAML
+	case FormatNDJSON:
+		return MIMENDJSON
+	}
+	return "application/json"
+}
+
+// Writer writes objects of a list one by one, so that lists are streamed instead of being encoded
+// at once. Flush writes buffered objects, it should be called once objects are written
+type Writer interface {
+	Write(obj runtime.Object) error
+	Flush() error
+}
+
+// NewWriter returns the Writer of format. fields are fields objects are projected to, they are
+// columns of CSV exports, and objects of YAML exports are kept as they are if any. Region and
+// cluster columns are added if aggregated, for objects listed from member clusters
+func NewWriter(format Format, w io.Writer, fields []string, aggregated bool) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		columns := fields
+		if len(columns) == 0 {
+			columns = defaultColumns
+		}
+		if aggregated {
+			columns = append([]string{ColumnRegion, ColumnCluster}, columns...)
+		}
+		return &csvWriter{writer: csv.NewWriter(w), columns: columns}, nil
+	case FormatYAML:
+		return &yamlWriter{writer: w, projected: len(fields) != 0}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("format %s is not exported by writers", format)
+}
+
+// formulaPrefixes are characters cells of formulas begin with
+const formulaPrefixes = "=+-@\t\r"
+
+// csvWriter writes a row of columns of every object, after the header of column names
+type csvWriter struct {
+	writer        *csv.Writer
+	columns       []string
+	headerWritten bool
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+	return c.writeRow(c.columns)
+}
+
+func (c *csvWriter) Write(obj runtime.Object) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	content := toUnstructured(obj)
+	var annotations map[string]string
+	if o, err := meta.Accessor(obj); err == nil {
+		annotations = o.GetAnnotations()
+	}
+
+	row := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		switch column {
+		case ColumnRegion:
+			row[i] = annotations[alpha1.AnnotationRegion]
+		case ColumnCluster:
+			row[i] = annotations[alpha1.AnnotationCluster]
+		default:
+			row[i] = cell(content, strings.Split(column, "."))
+		}
+	}
+	return c.writeRow(row)
+}
+
+// writeRow writes cells escaped, cells beginning with characters of formulas are prefixed with a
+// single quote, so that values of objects are not evaluated as formulas by spreadsheets
+func (c *csvWriter) writeRow(row []string) error {
+	escaped := make([]string, len(row))
+	for i, value := range row {
+		escaped[i] = value
+		if len(value) != 0 && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+			escaped[i] = "'" + value
+		}
+	}
+	return c.writer.Write(escaped)
+}
+
+// Flush writes the header even if there are no objects, so that exports are valid CSV files
+func (c *csvWriter) Flush() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// cell returns values of path in content, paths in lists are applied to every item and values are
+// separated by commas, e.g. spec.containers.image. Objects and lists are written as JSON
+func cell(content map[string]interface{}, path []string) string {
+	var values []string
+	collectValues(content, path, &values)
+	return strings.Join(values, ",")
+}
+
+func collectValues(value interface{}, path []string, values *[]string) {
+	if len(path) == 0 {
+		if value == nil {
+			return
+		}
+		switch v := value.(type) {
+		case string:
+			*values = append(*values, v)
+		case map[string]interface{}, []interface{}:
+			data, _ := json.Marshal(v)
+			*values = append(*values, string(data))
+		default:
+			*values = append(*values, fmt.Sprint(v))
+		}
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		collectValues(v[path[0]], path[1:], values)
+	case []interface{}:
+		for _, item := range v {
+			collectValues(item, path, values)
+		}
+	}
+}
+
+// yamlWriter writes objects as a stream of YAML documents. Fields populated by servers are removed
+// from objects not projected, so that exports can be applied by kubectl again. Clusters of objects
+// aggregated from member clusters are written as comments instead of annotations
+type yamlWriter struct {
+	writer    io.Writer
+	projected bool
+}
+
+// serverFields are fields of metadata populated by servers, they are not applied
+var serverFields = []string{"uid", "resourceVersion", "generation", "creationTimestamp", "deletionTimestamp",
+	"deletionGracePeriodSeconds", "selfLink", "managedFields"}
+
+func (y *yamlWriter) Write(obj runtime.Object) error {
+	content := runtime.DeepCopyJSON(toUnstructured(withKind(obj)))
+	u := &unstructured.Unstructured{Object: content}
+
+	var comment string
+	annotations := u.GetAnnotations()
+	if cluster, ok := annotations[alpha1.AnnotationCluster]; ok {
+		if region := annotations[alpha1.AnnotationRegion]; len(region) != 0 {
+			cluster = region + "/" + cluster
+		}
+		comment = fmt.Sprintf("# cluster: %s\n", cluster)
+		delete(annotations, alpha1.AnnotationRegion)
+		delete(annotations, alpha1.AnnotationCluster)
+		u.SetAnnotations(annotations)
+	}
+	if !y.projected {
+		for _, field := range serverFields {
+			unstructured.RemoveNestedField(content, "metadata", field)
+		}
+		delete(content, "status")
+	}
+
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(y.writer, "---\n%s%s", comment, data)
+	return err
+}
+
+func (y *yamlWriter) Flush() error {
+	return nil
+}
+
+// ndjsonWriter writes objects as JSON, an object per line
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(obj runtime.Object) error {
+	return n.encoder.Encode(obj)
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return nil
+}
+
+// withKind returns obj with apiVersion and kind, objects of informers have no type meta
+func withKind(obj runtime.Object) runtime.Object {
+	if !obj.GetObjectKind().GroupVersionKind().Empty() {
+		return obj
+	}
+	gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+	if err != nil || len(gvks) == 0 {
+		return obj
+	}
+	obj = obj.DeepCopyObject()
+	obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+	return obj
+}
+
+func toUnstructured(obj runtime.Object) map[string]interface{} {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		return u.UnstructuredContent()
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	return content
+}
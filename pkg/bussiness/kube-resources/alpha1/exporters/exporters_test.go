@@ -0,0 +1,170 @@
+package exporters
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+)
+
+func TestNegotiate(t *testing.T) {
+	for _, c := range []struct {
+		format, accept string
+		expected       Format
+	}{
+		{"", "", FormatJSON},
+		{"", "application/json;as=Table;g=meta.k8s.io;v=v1", FormatJSON},
+		{"", "text/csv", FormatCSV},
+		{"", "application/x-yaml, application/json", FormatYAML},
+		{"", "application/json, application/x-ndjson", FormatJSON},
+		{"NDJSON", "text/csv", FormatNDJSON},
+	} {
+		format, err := Negotiate(c.format, c.accept)
+		if err != nil || format != c.expected {
+			t.Errorf("expected %s of format %q and accept %q, got %s, %v", c.expected, c.format, c.accept, format, err)
+		}
+	}
+	if _, err := Negotiate("xml", ""); err == nil {
+		t.Errorf("expected unknown formats rejected")
+	}
+}
+
+func testPod() runtime.Object {
+	return alpha1.TagObject(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", UID: "uid", ResourceVersion: "10",
+			Labels: map[string]string{"app": "web"}},
+		Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: "nginx", Image: "nginx:1.21"},
+			{Name: "sidecar", Image: "envoy:1.20"},
+		}},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}, "r1", "c1")
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriter(FormatCSV, &buf, []string{"metadata.name", "spec.containers.image", "metadata.labels", "status.phase"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Write(testPod()); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]string{
+		{"region", "cluster", "metadata.name", "spec.containers.image", "metadata.labels", "status.phase"},
+		{"r1", "c1", "web", "nginx:1.21,envoy:1.20", `{"app":"web"}`, "Running"},
+	}
+	if len(records) != len(expected) || strings.Join(records[0], "|") != strings.Join(expected[0], "|") ||
+		strings.Join(records[1], "|") != strings.Join(expected[1], "|") {
+		t.Errorf("expected %v, got %v", expected, records)
+	}
+
+	// cells of formulas are escaped
+	buf.Reset()
+	writer, _ = NewWriter(FormatCSV, &buf, []string{"metadata.labels.app", "metadata.labels.cmd", "metadata.name"}, false)
+	writer.Write(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "-web", Labels: map[string]string{"app": "=HYPERLINK(\"x\")", "cmd": "@SUM(1)"}}})
+	writer.Flush()
+	if expected := "metadata.labels.app,metadata.labels.cmd,metadata.name\n\"'=HYPERLINK(\"\"x\"\")\",'@SUM(1),'-web\n"; buf.String() != expected {
+		t.Errorf("expected formulas escaped %q, got %q", expected, buf.String())
+	}
+
+	// the header is written without objects
+	buf.Reset()
+	writer, _ = NewWriter(FormatCSV, &buf, nil, false)
+	writer.Flush()
+	if buf.String() != "metadata.namespace,metadata.name,metadata.creationTimestamp\n" {
+		t.Errorf("unexpected empty export %q", buf.String())
+	}
+}
+
+func TestYAMLWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriter(FormatYAML, &buf, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", Generation: 3},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	for _, obj := range []runtime.Object{testPod(), deployment} {
+		if err := writer.Write(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !strings.HasPrefix(buf.String(), "---\n# cluster: r1/c1\n") {
+		t.Errorf("expected clusters written as comments, got %q", buf.String())
+	}
+
+	var objects []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(&buf, 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if len(obj.Object) != 0 {
+			objects = append(objects, obj)
+		}
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 documents, got %d", len(objects))
+	}
+
+	pod, deploy := objects[0], objects[1]
+	if pod.GetAPIVersion() != "v1" || pod.GetKind() != "Pod" || deploy.GetAPIVersion() != "apps/v1" || deploy.GetKind() != "Deployment" {
+		t.Errorf("expected kinds of objects, got %s %s and %s %s", pod.GetAPIVersion(), pod.GetKind(), deploy.GetAPIVersion(), deploy.GetKind())
+	}
+	if len(pod.GetUID()) != 0 || len(pod.GetResourceVersion()) != 0 || deploy.GetGeneration() != 0 {
+		t.Errorf("expected fields populated by servers removed")
+	}
+	if _, ok := pod.Object["status"]; ok {
+		t.Errorf("expected status removed")
+	}
+	if len(pod.GetAnnotations()) != 0 || pod.GetLabels()["app"] != "web" {
+		t.Errorf("expected tags of clusters removed and labels kept, got %v and %v", pod.GetAnnotations(), pod.GetLabels())
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriter(FormatNDJSON, &buf, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := writer.Write(testPod()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected an object per line, got %q", buf.String())
+	}
+	pod := &v1.Pod{}
+	if err := json.Unmarshal([]byte(lines[1]), pod); err != nil || pod.Name != "web" {
+		t.Errorf("unexpected line %s, %v", lines[1], err)
+	}
+}
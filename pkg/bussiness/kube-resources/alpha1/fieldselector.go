@@ -0,0 +1,69 @@
+package alpha1
+
+import (
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/unify/query"
+)
+
+// FieldsFunc returns fields of object supported by field selectors, all supported fields are
+// returned with empty values if object is nil
+type FieldsFunc func(runtime.Object) fields.Set
+
+// ObjectMetaFieldsSet returns fields of metadata supported by field selectors, metadata.namespace
+// is only supported by namespaced resources
+func ObjectMetaFieldsSet(objectMeta *metav1.ObjectMeta, hasNamespaceField bool) fields.Set {
+	return AddObjectMetaFieldsSet(fields.Set{}, objectMeta, hasNamespaceField)
+}
+
+// AddObjectMetaFieldsSet adds fields of metadata to source
+func AddObjectMetaFieldsSet(source fields.Set, objectMeta *metav1.ObjectMeta, hasNamespaceField bool) fields.Set {
+	source["metadata.name"] = objectMeta.Name
+	if hasNamespaceField {
+		source["metadata.namespace"] = objectMeta.Namespace
+	}
+	return source
+}
+
+// SupportedFields returns names of fields supported by field selectors, sorted
+func SupportedFields(fieldsFunc FieldsFunc) []string {
+	var names []string
+	for name := range fieldsFunc(nil) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SelectFields returns objects matching field selector of q, the same way as kube-apiserver. A
+// BadRequest error is returned if the selector is invalid or selects unsupported fields
+func SelectFields(objects []runtime.Object, q *query.QueryInfo, fieldsFunc FieldsFunc) ([]runtime.Object, error) {
+	if len(q.FieldSelector) == 0 {
+		return objects, nil
+	}
+	selector, err := fields.ParseSelector(q.FieldSelector)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid field selector: %v", err))
+	}
+
+	supported := fieldsFunc(nil)
+	for _, requirement := range selector.Requirements() {
+		if !supported.Has(requirement.Field) {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("field label not supported: %s", requirement.Field))
+		}
+	}
+
+	var selected []runtime.Object
+	for _, obj := range objects {
+		if selector.Matches(fieldsFunc(obj)) {
+			selected = append(selected, obj)
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,31 @@
+package alpha1
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/unify/query"
+)
+
+func TestSelectFields(t *testing.T) {
+	objects := []runtime.Object{newTestConfigMap("a"), newTestConfigMap("b"), newTestConfigMap("c")}
+
+	q := query.New()
+	q.FieldSelector = "metadata.namespace=default,metadata.name!=b"
+	selected, err := SelectFields(objects, q, testObjectFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 2 || selected[1].(*v1.ConfigMap).Name != "c" {
+		t.Errorf("unexpected selected objects %v", selected)
+	}
+
+	for _, selector := range []string{"spec.nodeName=n1", "metadata.name"} {
+		q.FieldSelector = selector
+		if _, err := SelectFields(objects, q, testObjectFields); err == nil {
+			t.Errorf("expected field selector %q rejected", selector)
+		}
+	}
+}
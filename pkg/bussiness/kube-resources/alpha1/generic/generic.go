@@ -0,0 +1,104 @@
+package generic
+
+import (
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+)
+
+// genericProvider serves resources without typed providers, e.g. CRDs, objects are unstructured and
+// filtered and sorted by metadata only
+type genericProvider struct {
+	informer   informers.GenericInformer
+	namespaced bool
+}
+
+// New returns the provider of objects of informer, which is a dynamic informer of a resource
+func New(informer informers.GenericInformer, namespaced bool) genericProvider {
+	return genericProvider{informer: informer, namespaced: namespaced}
+}
+
+func (g genericProvider) Get(namespace, name string) (runtime.Object, error) {
+	if g.namespaced {
+		return g.informer.Lister().ByNamespace(namespace).Get(name)
+	}
+	return g.informer.Lister().Get(name)
+}
+
+func (g genericProvider) List(namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	var raw []runtime.Object
+	var err error
+	if g.namespaced {
+		raw, err = g.informer.Lister().ByNamespace(namespace).List(query.GetSelector())
+	} else {
+		raw, err = g.informer.Lister().List(query.GetSelector())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := alpha1.SelectFields(raw, query, g.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
+	return alpha1.DefaultList(result, query, Compare, filter), nil
+}
+
+func (g genericProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(g.informer.Informer(), namespace, query, filter, g.SelectableFields)
+}
+
+// SelectableFields returns fields of metadata, the same as kube-apiserver supports for custom resources
+func (g genericProvider) SelectableFields(object runtime.Object) fields.Set {
+	objectMeta, _ := objectMetaOf(object)
+	return alpha1.ObjectMetaFieldsSet(&objectMeta, g.namespaced)
+}
+
+// objectMetaOf returns metadata of object used by the default filters and sorting
+func objectMetaOf(object runtime.Object) (metav1.ObjectMeta, bool) {
+	o, err := meta.Accessor(object)
+	if err != nil {
+		return metav1.ObjectMeta{}, false
+	}
+	return metav1.ObjectMeta{
+		Name:              o.GetName(),
+		Namespace:         o.GetNamespace(),
+		UID:               o.GetUID(),
+		Labels:            o.GetLabels(),
+		Annotations:       o.GetAnnotations(),
+		OwnerReferences:   o.GetOwnerReferences(),
+		CreationTimestamp: o.GetCreationTimestamp(),
+	}, true
+}
+
+func filter(object runtime.Object, filter query.Filter) bool {
+	objectMeta, ok := objectMetaOf(object)
+	if !ok {
+		return false
+	}
+
+	return alpha1.DefaultObjectMetaFilter(objectMeta, filter)
+}
+
+// Compare is the CompareFunc of objects of any resource, by metadata
+func Compare(left, right runtime.Object, field query.Field) bool {
+	leftMeta, ok := objectMetaOf(left)
+	if !ok {
+		return false
+	}
+
+	rightMeta, ok := objectMetaOf(right)
+	if !ok {
+		return false
+	}
+
+	return alpha1.DefaultObjectMetaCompare(leftMeta, rightMeta, field)
+}
@@ -0,0 +1,134 @@
+package generic
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"captain/pkg/unify/query"
+)
+
+var virtualServiceGVR = schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "virtualservices"}
+
+func newTestResolver() *Resolver {
+	listable := []string{"get", "list", "watch"}
+	client := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+	client.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "networking.istio.io/v1beta1", APIResources: []metav1.APIResource{
+			{Name: "virtualservices", Kind: "VirtualService", Namespaced: true, Verbs: listable, ShortNames: []string{"vs"}},
+		}},
+		{GroupVersion: "networking.istio.io/v1alpha3", APIResources: []metav1.APIResource{
+			{Name: "virtualservices", Kind: "VirtualService", Namespaced: true, Verbs: listable},
+		}},
+		{GroupVersion: "cluster.karmada.io/v1alpha1", APIResources: []metav1.APIResource{
+			{Name: "clusters", Kind: "Cluster", Verbs: listable},
+		}},
+		{GroupVersion: "authentication.k8s.io/v1", APIResources: []metav1.APIResource{
+			{Name: "tokenreviews", Kind: "TokenReview", Verbs: []string{"create"}},
+		}},
+	}
+	return NewResolver(client)
+}
+
+func TestResolve(t *testing.T) {
+	resolver := newTestResolver()
+	for _, c := range []struct {
+		arg        string
+		expected   schema.GroupVersionResource
+		namespaced bool
+	}{
+		{"virtualservices", virtualServiceGVR, true},
+		{"vs", virtualServiceGVR, true},
+		{"virtualservices.networking.istio.io", virtualServiceGVR, true},
+		{"virtualservices.v1alpha3.networking.istio.io", virtualServiceGVR.GroupResource().WithVersion("v1alpha3"), true},
+		{"clusters.cluster.karmada.io", schema.GroupVersionResource{Group: "cluster.karmada.io", Version: "v1alpha1", Resource: "clusters"}, false},
+	} {
+		gvr, namespaced, err := resolver.Resolve(c.arg)
+		if err != nil || gvr != c.expected || namespaced != c.namespaced {
+			t.Errorf("expected %s of %s, namespaced %v, got %s, %v, %v", c.expected, c.arg, c.namespaced, gvr, namespaced, err)
+		}
+	}
+
+	if gvk, err := resolver.KindFor(virtualServiceGVR); err != nil || gvk.Kind != "VirtualService" {
+		t.Errorf("expected kind VirtualService of %s, got %s, %v", virtualServiceGVR, gvk, err)
+	}
+
+	for _, arg := range []string{"widgets", "virtualservices.example.com", "tokenreviews"} {
+		if _, _, err := resolver.Resolve(arg); !meta.IsNoMatchError(err) {
+			t.Errorf("expected %s not matched, got %v", arg, err)
+		}
+	}
+}
+
+func newVirtualService(namespace, name string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("networking.istio.io/v1beta1")
+	obj.SetKind("VirtualService")
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetLabels(labels)
+	return obj
+}
+
+func TestGenericProvider(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{virtualServiceGVR: "VirtualServiceList"},
+		newVirtualService("default", "reviews", map[string]string{"app": "reviews"}),
+		newVirtualService("default", "ratings", map[string]string{"app": "ratings"}),
+		newVirtualService("istio-system", "gateway", nil))
+
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	informer := factory.ForResource(virtualServiceGVR)
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+	provider := New(informer, true)
+
+	obj, err := provider.Get("default", "reviews")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o, _ := meta.Accessor(obj); o.GetName() != "reviews" {
+		t.Errorf("unexpected object %v", obj)
+	}
+
+	q := query.New()
+	q.SortBy = query.FieldName
+	q.Ascending = true
+	result, err := provider.List("", q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 3 || result.Items[0].(metav1.Object).GetName() != "gateway" {
+		t.Errorf("expected virtual services of every namespace sorted by names, got %v", result.Items)
+	}
+
+	q = query.New()
+	q.AddFilter(string(query.FieldLabel), "app=ratings")
+	result, err = provider.List("default", q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 || result.Items[0].(metav1.Object).GetName() != "ratings" {
+		t.Errorf("expected virtual services filtered by labels, got %v", result.Items)
+	}
+
+	q = query.New()
+	q.FieldSelector = "metadata.namespace=istio-system"
+	result, err = provider.List("", q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 || result.Items[0].(metav1.Object).GetName() != "gateway" {
+		t.Errorf("expected virtual services selected by namespaces, got %v", result.Items)
+	}
+}
@@ -0,0 +1,78 @@
+package generic
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+	"captain/pkg/utils/clustercache"
+	"captain/pkg/utils/clusterclient"
+)
+
+type mcGenericProvider struct {
+	clusterclient.ClusterClients
+	caches     *clustercache.Manager
+	gvr        schema.GroupVersionResource
+	namespaced bool
+}
+
+// NewMCResProvider returns the provider of gvr of member clusters, objects are served from dynamic
+// informers of caches, and watched by dynamic clients
+func NewMCResProvider(clients clusterclient.ClusterClients, caches *clustercache.Manager, gvr schema.GroupVersionResource, namespaced bool) mcGenericProvider {
+	return mcGenericProvider{ClusterClients: clients, caches: caches, gvr: gvr, namespaced: namespaced}
+}
+
+func (pd mcGenericProvider) resourceClient(region, cluster, namespace string) (dynamic.ResourceInterface, error) {
+	config, err := pd.GetRequestRESTConfig(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	cli, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if pd.namespaced {
+		return cli.Resource(pd.gvr).Namespace(namespace), nil
+	}
+	return cli.Resource(pd.gvr), nil
+}
+
+func (pd mcGenericProvider) Get(ctx context.Context, region, cluster, namespace, name string) (runtime.Object, error) {
+	informer, err := pd.caches.DynamicInformer(ctx, region, cluster, pd.gvr)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(informer, pd.namespaced).Get(namespace, name)
+}
+
+func (pd mcGenericProvider) List(ctx context.Context, region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	informer, err := pd.caches.DynamicInformer(ctx, region, cluster, pd.gvr)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(informer, pd.namespaced).List(namespace, query)
+}
+
+func (pd mcGenericProvider) Compare(left, right runtime.Object, field query.Field) bool {
+	return Compare(left, right, field)
+}
+
+func (pd mcGenericProvider) Watch(ctx context.Context, region, cluster, namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	cli, err := pd.resourceClient(region, cluster, namespace)
+	if err != nil {
+		return nil, err
+	}
+	w, err := cli.Watch(ctx, alpha1.WatchOptions(query))
+	if err != nil {
+		return nil, err
+	}
+	return alpha1.FilterWatch(w, query, filter)
+}
@@ -0,0 +1,92 @@
+package generic
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+)
+
+// resetInterval is the minimum interval of resetting discovery caches, resources not found are
+// discovered again after it, e.g. CRDs installed after caches are populated
+const resetInterval = 30 * time.Second
+
+// Resolver resolves resources of requests to resources served by a cluster, by discovery of the cluster
+type Resolver struct {
+	sync.Mutex
+	discovery discovery.CachedDiscoveryInterface
+	mapper    *restmapper.DeferredDiscoveryRESTMapper
+	expander  meta.RESTMapper
+	lastReset time.Time
+}
+
+func NewResolver(client discovery.DiscoveryInterface) *Resolver {
+	cached := memory.NewMemCacheClient(client)
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(cached)
+	return &Resolver{
+		discovery: cached,
+		mapper:    mapper,
+		expander:  restmapper.NewShortcutExpander(mapper, cached),
+	}
+}
+
+// Resolve returns the resource of arg and whether it is namespaced. arg is a resource, a short name,
+// resource.group or resource.version.group, e.g. virtualservices.networking.istio.io, the same as
+// kubectl, resources of preferred versions are returned unless versions are specified. A
+// meta.NoResourceMatchError is returned if it is not served, or cannot be listed and watched
+func (r *Resolver) Resolve(arg string) (schema.GroupVersionResource, bool, error) {
+	gvr, err := r.resourceFor(arg)
+	if meta.IsNoMatchError(err) && r.reset() {
+		gvr, err = r.resourceFor(arg)
+	}
+	if err != nil {
+		return gvr, false, err
+	}
+
+	resources, err := r.discovery.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return gvr, false, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name != gvr.Resource {
+			continue
+		}
+		if !sets.NewString(resource.Verbs...).HasAll("list", "watch") {
+			break
+		}
+		return gvr, resource.Namespaced, nil
+	}
+	return gvr, false, &meta.NoResourceMatchError{PartialResource: gvr}
+}
+
+// KindFor returns the kind of gvr, which is resolved by Resolve
+func (r *Resolver) KindFor(gvr schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	return r.mapper.KindFor(gvr)
+}
+
+func (r *Resolver) resourceFor(arg string) (schema.GroupVersionResource, error) {
+	fullySpecified, groupResource := schema.ParseResourceArg(arg)
+	if fullySpecified != nil {
+		if gvr, err := r.expander.ResourceFor(*fullySpecified); err == nil {
+			return gvr, nil
+		}
+	}
+	return r.expander.ResourceFor(groupResource.WithVersion(""))
+}
+
+// reset resets discovery caches unless they are reset within resetInterval, true is returned if reset
+func (r *Resolver) reset() bool {
+	r.Lock()
+	defer r.Unlock()
+	if time.Since(r.lastReset) < resetInterval {
+		return false
+	}
+	r.lastReset = time.Now()
+	r.mapper.Reset()
+	return true
+}
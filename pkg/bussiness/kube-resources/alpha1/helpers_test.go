@@ -0,0 +1,34 @@
+package alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/unify/query"
+)
+
+// testObjectMeta returns metadata of typed objects of tests, empty metadata is returned for nil
+func testObjectMeta(object runtime.Object) metav1.ObjectMeta {
+	if o, ok := object.(metav1.ObjectMetaAccessor); ok {
+		if objectMeta, ok := o.GetObjectMeta().(*metav1.ObjectMeta); ok {
+			return *objectMeta
+		}
+	}
+	return metav1.ObjectMeta{}
+}
+
+// compareTestObjects, filterTestObjects and testObjectFields compare, filter and select objects of
+// tests by metadata, the same as providers of resources without fields of their own
+func compareTestObjects(left, right runtime.Object, field query.Field) bool {
+	return DefaultObjectMetaCompare(testObjectMeta(left), testObjectMeta(right), field)
+}
+
+func filterTestObjects(object runtime.Object, filter query.Filter) bool {
+	return DefaultObjectMetaFilter(testObjectMeta(object), filter)
+}
+
+func testObjectFields(object runtime.Object) fields.Set {
+	objectMeta := testObjectMeta(object)
+	return ObjectMetaFieldsSet(&objectMeta, true)
+}
@@ -5,7 +5,9 @@ import (
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 )
 
@@ -32,9 +34,27 @@ func (ing ingressProvider) List(namespace string, query *query.QueryInfo) (*resp
 		result = append(result, deploy)
 	}
 
+	result, err = alpha1.SelectFields(result, query, ing.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+func (ing ingressProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(ing.sharedInformers.Networking().V1().Ingresses().Informer(), namespace, query, filter, ing.SelectableFields)
+}
+
+// SelectableFields returns fields of ingresses supported by field selectors, the same as kube-apiserver
+func (ing ingressProvider) SelectableFields(object runtime.Object) fields.Set {
+	ingress, ok := object.(*v1.Ingress)
+	if !ok {
+		ingress = &v1.Ingress{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&ingress.ObjectMeta, true)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	ingress, ok := object.(*v1.Ingress)
 	if !ok {
@@ -1,6 +1,8 @@
 package alpha1
 
 import (
+	"context"
+
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	"math"
@@ -8,60 +10,77 @@ import (
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
+// KubeResProvider retrieves objects from informer caches of the host cluster
 type KubeResProvider interface {
 	// Get retrieves a single object by its namespace and name
 	Get(namespace, name string) (runtime.Object, error)
 
-	// List retrieves a collection of objects matches given query
+	// List retrieves a page of objects matching given query, see DefaultList
 	List(namespace string, query *query.QueryInfo) (*response.ListResult, error)
+
+	// Watch watches changes of objects matching given query from informers
+	Watch(namespace string, query *query.QueryInfo) (watch.Interface, error)
+
+	// SelectableFields returns fields of object supported by field selectors, the same as kube-apiserver,
+	// all supported fields are returned with empty values if object is nil
+	SelectableFields(object runtime.Object) fields.Set
 }
 
+// MultiClusterKubeResProvider retrieves objects from member clusters, objects are got and listed from
+// caches of member clusters, and watched from member clusters. ctx is the context of request, which
+// carries the trace of request to member clusters, and bounds the wait of caches to be synced
 type MultiClusterKubeResProvider interface {
 	// Get retrieves a single object by its namespace and name
-	Get(region, cluster, namespace, name string) (runtime.Object, error)
+	Get(ctx context.Context, region, cluster, namespace, name string) (runtime.Object, error)
 
-	// List retrieves a collection of objects matches given query
-	List(region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error)
+	// List retrieves a page of objects matching given query, see DefaultList
+	List(ctx context.Context, region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error)
+
+	// Compare is the CompareFunc of List, objects aggregated from clusters are sorted by it
+	Compare(left, right runtime.Object, field query.Field) bool
+
+	// Watch watches changes of objects matching given query from member clusters
+	Watch(ctx context.Context, region, cluster, namespace string, query *query.QueryInfo) (watch.Interface, error)
 }
 
 // CompareFunc return true is left great than right
 type CompareFunc func(runtime.Object, runtime.Object, query.Field) bool
 
+// FilterFunc return true if object matches a single value of filter, operators of filters like
+// in, notin and negation are applied by DefaultList
 type FilterFunc func(runtime.Object, query.Filter) bool
 
 type TransformFunc func(runtime.Object) runtime.Object
 
+// DefaultList filters and sorts objects listed from informer caches, and returns a page of them. Objects
+// are paged by cursors in continue tokens if q.Limit or q.Continue is set, or by q.Pagination otherwise
 func DefaultList(objects []runtime.Object, q *query.QueryInfo, compareFunc CompareFunc, filterFunc FilterFunc, transferFuncs ...TransformFunc) *response.ListResult {
+	return pageList(filterObjects(objects, q, filterFunc, transferFuncs...), q, compareFunc, ProjectFunc(q))
+}
 
-	var filtered []runtime.Object
-	for _, obj := range objects {
-		//is targeted by such filter key/values
-		targeted := true
-		for k, v := range q.Filters {
-			if !filterFunc(obj, query.Filter{Field: k, Value: v}) {
-				targeted = false
-			}
-		}
-
-		if targeted {
-			for _, transform := range transferFuncs {
-				obj = transform(obj)
-			}
-			filtered = append(filtered, obj)
-		}
+// pageList sorts objects filtered and returns a page of them, pageFuncs are applied to objects of the page
+func pageList(filtered []runtime.Object, q *query.QueryInfo, compareFunc CompareFunc, pageFuncs ...TransformFunc) *response.ListResult {
+	if q.IsCursorPagination() {
+		return cursorList(filtered, q, compareFunc, pageFuncs...)
 	}
 
 	//sort by some field
-	sort.Slice(filtered, func(i, j int) bool {
-		//Ascending
-		if q.Ascending {
-			return compareFunc(filtered[i], filtered[j], q.SortBy)
-		}
-		return !compareFunc(filtered[i], filtered[j], q.SortBy)
-	})
+	if IsJSONPath(q.SortBy) {
+		sortByJSONPath(filtered, string(q.SortBy), q.Ascending)
+	} else {
+		sort.Slice(filtered, func(i, j int) bool {
+			//Ascending
+			if q.Ascending {
+				return compareFunc(filtered[i], filtered[j], q.SortBy)
+			}
+			return !compareFunc(filtered[i], filtered[j], q.SortBy)
+		})
+	}
 
 	//summarize
 
@@ -74,13 +93,52 @@ func DefaultList(objects []runtime.Object, q *query.QueryInfo, compareFunc Compa
 		CurrentPage: q.Pagination.Page,
 		PageSize:    q.Pagination.PageSize,
 		TotalPages:  int(math.Ceil(float64(total) / float64(q.Pagination.PageSize))),
-		Items:       objects2Interfaces(filtered[begin:end]),
+		Items:       objects2Interfaces(filtered[begin:end], pageFuncs...),
 	}
 }
 
-func objects2Interfaces(objs []runtime.Object) []interface{} {
+func filterObjects(objects []runtime.Object, q *query.QueryInfo, filterFunc FilterFunc, transferFuncs ...TransformFunc) []runtime.Object {
+	// JSONPath filters are validated by ValidateJSONPath beforehand
+	jsonPathFilters, _ := parseJSONPathFilters(q.JSONPathFilters)
+
+	var filtered []runtime.Object
+	for _, obj := range objects {
+		//is targeted by such filter key/values
+		targeted := true
+		for _, requirement := range q.Filters {
+			if !requirement.Matches(func(filter query.Filter) bool { return filterFunc(obj, filter) }) {
+				targeted = false
+				break
+			}
+		}
+		if targeted && len(jsonPathFilters) != 0 {
+			content := toUnstructured(obj)
+			for i := range jsonPathFilters {
+				if !jsonPathFilters[i].matches(content) {
+					targeted = false
+					break
+				}
+			}
+		}
+
+		if targeted {
+			for _, transform := range transferFuncs {
+				obj = transform(obj)
+			}
+			filtered = append(filtered, obj)
+		}
+	}
+	return filtered
+}
+
+// objects2Interfaces returns items of a page, transformFuncs are applied to objects of the page only,
+// after objects are sorted
+func objects2Interfaces(objs []runtime.Object, transformFuncs ...TransformFunc) []interface{} {
 	res := make([]interface{}, 0)
 	for _, obj := range objs {
+		for _, transform := range transformFuncs {
+			obj = transform(obj)
+		}
 		res = append(res, obj)
 	}
 	return res
@@ -115,16 +173,25 @@ func DefaultObjectMetaFilter(item metav1.ObjectMeta, filter query.Filter) bool {
 			}
 		}
 		return false
-	// /namespaces?page=1&limit=10&name=default
+	// /namespaces?limit=10&name=default
+	// /namespaces?limit=10&name=prefix(kube-)
+	// /namespaces?limit=10&name=exact(default)
 	case query.FieldName:
-		return strings.Contains(item.Name, string(filter.Value))
-		// /namespaces?page=1&limit=10&uid=a8a8d6cf-f6a5-4fea-9c1b-e57610115706
+		switch filter.Match {
+		case query.MatchPrefix:
+			return strings.HasPrefix(item.Name, string(filter.Value))
+		case query.MatchExact:
+			return item.Name == string(filter.Value)
+		default:
+			return strings.Contains(item.Name, string(filter.Value))
+		}
+		// /namespaces?limit=10&uid=a8a8d6cf-f6a5-4fea-9c1b-e57610115706
 	case query.FieldUID:
 		return strings.Compare(string(item.UID), string(filter.Value)) == 0
-		// /deployments?page=1&limit=10&namespace=kubesphere-system
+		// /deployments?limit=10&namespace=kubesphere-system
 	case query.FieldNamespace:
 		return strings.Compare(item.Namespace, string(filter.Value)) == 0
-		// /namespaces?page=1&limit=10&ownerReference=a8a8d6cf-f6a5-4fea-9c1b-e57610115706
+		// /namespaces?limit=10&ownerReference=a8a8d6cf-f6a5-4fea-9c1b-e57610115706
 	case query.FieldOwnerReference:
 		for _, ownerReference := range item.OwnerReferences {
 			if strings.Compare(string(ownerReference.UID), string(filter.Value)) == 0 {
@@ -132,7 +199,7 @@ func DefaultObjectMetaFilter(item metav1.ObjectMeta, filter query.Filter) bool {
 			}
 		}
 		return false
-		// /namespaces?page=1&limit=10&ownerKind=Workspace
+		// /namespaces?limit=10&ownerKind=Workspace
 	case query.FieldOwnerKind:
 		for _, ownerReference := range item.OwnerReferences {
 			if strings.Compare(ownerReference.Kind, string(filter.Value)) == 0 {
@@ -140,10 +207,10 @@ func DefaultObjectMetaFilter(item metav1.ObjectMeta, filter query.Filter) bool {
 			}
 		}
 		return false
-		// /namespaces?page=1&limit=10&annotation=openpitrix_runtime
+		// /namespaces?limit=10&annotation=openpitrix_runtime
 	case query.FieldAnnotation:
 		return labelMatch(item.Annotations, string(filter.Value))
-		// /namespaces?page=1&limit=10&label=kubesphere.io/workspace:system-workspace
+		// /namespaces?limit=10&label=kubesphere.io/workspace:system-workspace
 	case query.FieldLabel:
 		return labelMatch(item.Labels, string(filter.Value))
 	default:
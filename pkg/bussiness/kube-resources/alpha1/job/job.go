@@ -6,8 +6,11 @@ import (
 	"captain/pkg/unify/response"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -41,9 +44,29 @@ func (j jobProvider) List(namespace string, query *query.QueryInfo) (*response.L
 		result = append(result, deploy)
 	}
 
+	result, err = alpha1.SelectFields(result, query, j.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+func (j jobProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(j.sharedInformers.Batch().V1().Jobs().Informer(), namespace, query, filter, j.SelectableFields)
+}
+
+// SelectableFields returns fields of jobs supported by field selectors, the same as kube-apiserver
+func (j jobProvider) SelectableFields(object runtime.Object) fields.Set {
+	job, ok := object.(*batchv1.Job)
+	if !ok {
+		job = &batchv1.Job{}
+	}
+	return alpha1.AddObjectMetaFieldsSet(fields.Set{
+		"status.successful": strconv.Itoa(int(job.Status.Succeeded)),
+	}, &job.ObjectMeta, true)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	job, ok := object.(*batchv1.Job)
 	if !ok {
@@ -0,0 +1,285 @@
+package alpha1
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+
+	"captain/pkg/unify/query"
+)
+
+// operators of JSONPath filters, two-character operators are matched first
+var jsonPathOperators = []string{"==", "!=", ">=", "<=", "=", ">", "<"}
+
+// jsonPathFilter selects objects by values of a JSONPath, e.g. .status.readyReplicas>=2
+type jsonPathFilter struct {
+	path     *jsonpath.JSONPath
+	operator string
+	value    string
+}
+
+// IsJSONPath returns true if sortBy is a JSONPath like .status.readyReplicas instead of a known field
+func IsJSONPath(sortBy query.Field) bool {
+	return strings.HasPrefix(string(sortBy), ".") || strings.HasPrefix(string(sortBy), "{")
+}
+
+// ValidateJSONPath validates JSONPath filters and sortBy of q, a BadRequest error is returned if
+// any of them is invalid
+func ValidateJSONPath(q *query.QueryInfo) error {
+	if _, err := parseJSONPathFilters(q.JSONPathFilters); err != nil {
+		return err
+	}
+	if IsJSONPath(q.SortBy) {
+		if _, err := parseJSONPath(string(q.SortBy)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseJSONPath(path string) (*jsonpath.JSONPath, error) {
+	if !strings.HasPrefix(path, "{") {
+		path = "{" + path + "}"
+	}
+	jp := jsonpath.New("").AllowMissingKeys(true)
+	if err := jp.Parse(path); err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid JSONPath %s: %v", path, err))
+	}
+	return jp, nil
+}
+
+func parseJSONPathFilters(expressions []string) ([]jsonPathFilter, error) {
+	var filters []jsonPathFilter
+	for _, expression := range expressions {
+		path, operator, value, ok := splitJSONPathFilter(expression)
+		if !ok {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid filter %s, <jsonpath><operator><value> is required, "+
+				"operator is one of %s", expression, strings.Join(jsonPathOperators, " ")))
+		}
+		jp, err := parseJSONPath(path)
+		if err != nil {
+			return nil, err
+		}
+		// values may be quoted, e.g. .metadata.name=="nginx"
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		filters = append(filters, jsonPathFilter{path: jp, operator: operator, value: value})
+	}
+	return filters, nil
+}
+
+// splitJSONPathFilter splits expression at the first operator outside of brackets and quotes,
+// since JSONPath may contain operators, e.g. .status.conditions[?(@.type=="Ready")].status==True
+func splitJSONPathFilter(expression string) (path, operator, value string, ok bool) {
+	depth := 0
+	var quote rune
+	for i, c := range expression {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			continue
+		case c == '"' || c == '\'':
+			quote = c
+			continue
+		case c == '[' || c == '(' || c == '{':
+			depth++
+			continue
+		case c == ']' || c == ')' || c == '}':
+			depth--
+			continue
+		}
+		if depth != 0 {
+			continue
+		}
+		for _, op := range jsonPathOperators {
+			if strings.HasPrefix(expression[i:], op) {
+				if i == 0 {
+					return "", "", "", false
+				}
+				return expression[:i], op, expression[i+len(op):], true
+			}
+		}
+	}
+	return "", "", "", false
+}
+
+// matches returns true if any value of the path of obj meets the filter, or none of values
+// equals the value of the filter for !=
+func (f *jsonPathFilter) matches(obj interface{}) bool {
+	values := jsonPathValues(f.path, obj)
+	if f.operator == "!=" {
+		for _, v := range values {
+			if compareJSONValues(v, f.value) == 0 {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, v := range values {
+		c := compareJSONValues(v, f.value)
+		switch f.operator {
+		case "=", "==":
+			if c == 0 {
+				return true
+			}
+		case ">":
+			if c > 0 {
+				return true
+			}
+		case ">=":
+			if c >= 0 {
+				return true
+			}
+		case "<":
+			if c < 0 {
+				return true
+			}
+		case "<=":
+			if c <= 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// toUnstructured converts obj to the JSON representation JSONPath is evaluated on, so that
+// paths are the same as kubectl, e.g. .status.startTime instead of .Status.StartTime
+func toUnstructured(obj runtime.Object) interface{} {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		return u.UnstructuredContent()
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil
+	}
+	return content
+}
+
+func jsonPathValues(jp *jsonpath.JSONPath, obj interface{}) []interface{} {
+	results, err := jp.FindResults(obj)
+	if err != nil {
+		return nil
+	}
+	var values []interface{}
+	for _, result := range results {
+		for _, v := range result {
+			if v.IsValid() && v.CanInterface() {
+				values = append(values, v.Interface())
+			}
+		}
+	}
+	return values
+}
+
+// compareJSONValues compares values by their types, numbers, timestamps and quantities are compared
+// by their values, e.g. 500m < 1, and others by their strings. nil is less than any value
+func compareJSONValues(left, right interface{}) int {
+	if left == nil || right == nil {
+		switch {
+		case left == nil && right == nil:
+			return 0
+		case left == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	if l, ok := toFloat(left); ok {
+		if r, ok := toFloat(right); ok {
+			return compareFloat(l, r)
+		}
+	}
+
+	ls, rs := fmt.Sprint(left), fmt.Sprint(right)
+	if l, err := time.Parse(time.RFC3339, ls); err == nil {
+		if r, err := time.Parse(time.RFC3339, rs); err == nil {
+			switch {
+			case l.Before(r):
+				return -1
+			case l.After(r):
+				return 1
+			}
+			return 0
+		}
+	}
+	if l, err := resource.ParseQuantity(ls); err == nil {
+		if r, err := resource.ParseQuantity(rs); err == nil {
+			return l.Cmp(r)
+		}
+	}
+	return strings.Compare(ls, rs)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	case bool:
+		return 0, false
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func compareFloat(l, r float64) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	}
+	return 0
+}
+
+// sortByJSONPath sorts objects by the first value of path, values are evaluated once for every object.
+// Objects without the value are the first in ascending order. The sort is stable
+func sortByJSONPath(objects []runtime.Object, path string, ascending bool) {
+	jp, err := parseJSONPath(path)
+	if err != nil {
+		return
+	}
+	type keyed struct {
+		obj runtime.Object
+		key interface{}
+	}
+	items := make([]keyed, len(objects))
+	for i, obj := range objects {
+		items[i].obj = obj
+		if values := jsonPathValues(jp, toUnstructured(obj)); len(values) != 0 {
+			items[i].key = values[0]
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		c := compareJSONValues(items[i].key, items[j].key)
+		if ascending {
+			return c < 0
+		}
+		return c > 0
+	})
+	for i := range items {
+		objects[i] = items[i].obj
+	}
+}
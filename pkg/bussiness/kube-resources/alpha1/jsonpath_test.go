@@ -0,0 +1,70 @@
+package alpha1
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/unify/query"
+)
+
+func newTestDeployment(name string, ready int32, cpu string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: appsv1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{{
+				Name:      "app",
+				Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)}},
+			}}}},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: ready},
+	}
+}
+
+func TestJSONPathFilterAndSort(t *testing.T) {
+	objects := []runtime.Object{
+		newTestDeployment("a", 3, "500m"),
+		newTestDeployment("b", 10, "2"),
+		newTestDeployment("c", 1, "1"),
+	}
+
+	q := query.New()
+	q.JSONPathFilters = []string{".status.readyReplicas>=2", ".spec.template.spec.containers[0].resources.requests.cpu<1.5"}
+	q.SortBy = ".status.readyReplicas"
+	if err := ValidateJSONPath(q); err != nil {
+		t.Fatal(err)
+	}
+	result := DefaultList(objects, q, compareTestObjects, filterTestObjects)
+	if len(result.Items) != 1 || result.Items[0].(*appsv1.Deployment).Name != "a" {
+		t.Errorf("expected only deployment a selected, got %v", result.Items)
+	}
+
+	// numbers are compared by value, 10 > 3
+	q.JSONPathFilters = nil
+	result = DefaultList(objects, q, compareTestObjects, filterTestObjects)
+	var names string
+	for _, item := range result.Items {
+		names += item.(*appsv1.Deployment).Name
+	}
+	if names != "bac" {
+		t.Errorf("expected deployments sorted by ready replicas descending, got %s", names)
+	}
+
+	for _, invalid := range []string{".status.readyReplicas", "==1", ".status[=1"} {
+		q.JSONPathFilters = []string{invalid}
+		if err := ValidateJSONPath(q); err == nil {
+			t.Errorf("expected filter %q rejected", invalid)
+		}
+	}
+}
+
+func TestSplitJSONPathFilter(t *testing.T) {
+	path, operator, value, ok := splitJSONPathFilter(`.status.conditions[?(@.type=="Ready")].status=="True"`)
+	if !ok || path != `.status.conditions[?(@.type=="Ready")].status` || operator != "==" || value != `"True"` {
+		t.Errorf("unexpected split %q %q %q", path, operator, value)
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 )
 
@@ -36,9 +38,30 @@ func (ns namespaceProvider) List(namespace string, query *query.QueryInfo) (*res
 		result = append(result, nasp)
 	}
 
+	result, err = alpha1.SelectFields(result, query, ns.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+// Watch watches namespaces from informers, namespace is ignored since they are cluster scoped
+func (ns namespaceProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(ns.informers.Core().V1().Namespaces().Informer(), "", query, filter, ns.SelectableFields)
+}
+
+// SelectableFields returns fields of namespaces supported by field selectors, the same as kube-apiserver
+func (ns namespaceProvider) SelectableFields(object runtime.Object) fields.Set {
+	namespace, ok := object.(*v1.Namespace)
+	if !ok {
+		namespace = &v1.Namespace{}
+	}
+	return alpha1.AddObjectMetaFieldsSet(fields.Set{
+		"status.phase": string(namespace.Status.Phase),
+	}, &namespace.ObjectMeta, false)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	namespace, ok := object.(*v1.Namespace)
 	if !ok {
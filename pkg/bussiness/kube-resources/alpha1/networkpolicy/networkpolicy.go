@@ -6,7 +6,9 @@ import (
 	"captain/pkg/unify/response"
 
 	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 )
 
@@ -33,9 +35,27 @@ func (netp networkpolicyProvider) List(namespace string, query *query.QueryInfo)
 		result = append(result, item)
 	}
 
+	result, err = alpha1.SelectFields(result, query, netp.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+func (netp networkpolicyProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(netp.sharedInformers.Networking().V1().NetworkPolicies().Informer(), namespace, query, filter, netp.SelectableFields)
+}
+
+// SelectableFields returns fields of networkpolicies supported by field selectors, the same as kube-apiserver
+func (netp networkpolicyProvider) SelectableFields(object runtime.Object) fields.Set {
+	np, ok := object.(*v1.NetworkPolicy)
+	if !ok {
+		np = &v1.NetworkPolicy{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&np.ObjectMeta, true)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	np, ok := object.(*v1.NetworkPolicy)
 	if !ok {
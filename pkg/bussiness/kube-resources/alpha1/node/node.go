@@ -5,11 +5,14 @@ import (
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 )
 
@@ -45,9 +48,30 @@ func (nd nodeProvider) List(node string, query *query.QueryInfo) (*response.List
 		result = append(result, nasp)
 	}
 
+	result, err = alpha1.SelectFields(result, query, nd.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+// Watch watches nodes from informers, namespace is ignored since they are cluster scoped
+func (nd nodeProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(nd.informers.Core().V1().Nodes().Informer(), "", query, filter, nd.SelectableFields)
+}
+
+// SelectableFields returns fields of nodes supported by field selectors, the same as kube-apiserver
+func (nd nodeProvider) SelectableFields(object runtime.Object) fields.Set {
+	node, ok := object.(*v1.Node)
+	if !ok {
+		node = &v1.Node{}
+	}
+	return alpha1.AddObjectMetaFieldsSet(fields.Set{
+		"spec.unschedulable": strconv.FormatBool(node.Spec.Unschedulable),
+	}, &node.ObjectMeta, false)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	node, ok := object.(*v1.Node)
 	if !ok {
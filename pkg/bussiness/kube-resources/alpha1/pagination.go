@@ -0,0 +1,123 @@
+package alpha1
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+)
+
+// cursor is the position in a list from informer caches where the next page starts, it is
+// encoded in the continue token
+type cursor struct {
+	// SortBy and Ascending are the order of the first page, kept by the following pages
+	SortBy    query.Field `json:"sortBy,omitempty"`
+	Ascending bool        `json:"ascending,omitempty"`
+
+	// UID is the last item of the previous page, the next page starts after it
+	UID string `json:"uid"`
+
+	// Offset is the index of the next item, the next page starts there if the last item
+	// of the previous page is deleted
+	Offset int `json:"offset"`
+}
+
+// ValidateContinue validates continue token of lists from informer caches, a BadRequest error is
+// returned if the token is invalid
+func ValidateContinue(token string) error {
+	_, err := decodeContinue(token)
+	return err
+}
+
+// decodeContinue decodes continue token, empty token means the first page
+func decodeContinue(token string) (*cursor, error) {
+	if len(token) == 0 {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, apierrors.NewBadRequest("invalid continue token")
+	}
+	c := &cursor{}
+	if err := json.Unmarshal(data, c); err != nil || c.Offset < 0 {
+		return nil, apierrors.NewBadRequest("invalid continue token")
+	}
+	return c, nil
+}
+
+func encodeContinue(c *cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// cursorList returns a page of at most q.Limit items starting at q.Continue. Objects are sorted in a
+// total order, ties of compareFunc are broken by namespace and name, so that pages are stable while
+// objects are not changed. Invalid continue token is treated as the first page, callers should
+// validate it by ValidateContinue beforehand. pageFuncs are applied to objects of the page
+func cursorList(objects []runtime.Object, q *query.QueryInfo, compareFunc CompareFunc, pageFuncs ...TransformFunc) *response.ListResult {
+	c, err := decodeContinue(q.Continue)
+	if err != nil || c == nil {
+		c = &cursor{SortBy: q.SortBy, Ascending: q.Ascending}
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objectKey(objects[i]) < objectKey(objects[j])
+	})
+	if IsJSONPath(c.SortBy) {
+		sortByJSONPath(objects, string(c.SortBy), c.Ascending)
+	} else {
+		sort.SliceStable(objects, func(i, j int) bool {
+			if c.Ascending {
+				return compareFunc(objects[i], objects[j], c.SortBy)
+			}
+			return compareFunc(objects[j], objects[i], c.SortBy)
+		})
+	}
+
+	begin := c.Offset
+	if len(c.UID) != 0 {
+		for i, obj := range objects {
+			if o, err := meta.Accessor(obj); err == nil && string(o.GetUID()) == c.UID {
+				begin = i + 1
+				break
+			}
+		}
+	}
+	if begin > len(objects) {
+		begin = len(objects)
+	}
+	end := begin + int(q.Limit)
+	if end > len(objects) || end < begin {
+		end = len(objects)
+	}
+
+	result := &response.ListResult{
+		Total:    len(objects),
+		PageSize: int(q.Limit),
+		Items:    objects2Interfaces(objects[begin:end], pageFuncs...),
+	}
+	if end < len(objects) {
+		next := &cursor{SortBy: c.SortBy, Ascending: c.Ascending, Offset: end}
+		if o, err := meta.Accessor(objects[end-1]); err == nil && end > begin {
+			next.UID = string(o.GetUID())
+		}
+		remaining := int64(len(objects) - end)
+		result.Continue = encodeContinue(next)
+		result.RemainingItemCount = &remaining
+	}
+	return result
+}
+
+func objectKey(obj runtime.Object) string {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return o.GetNamespace() + "/" + o.GetName()
+}
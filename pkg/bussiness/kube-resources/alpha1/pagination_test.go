@@ -0,0 +1,53 @@
+package alpha1
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"captain/pkg/unify/query"
+)
+
+func newTestConfigMap(name string) *v1.ConfigMap {
+	return &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name)}}
+}
+
+func TestCursorPagination(t *testing.T) {
+	var objects []runtime.Object
+	for i := 0; i < 5; i++ {
+		objects = append(objects, newTestConfigMap(fmt.Sprintf("cm-%d", i)))
+	}
+
+	q := query.New()
+	q.SortBy = query.FieldName
+	q.Ascending = true
+	q.Limit = 2
+
+	// the first page, then cm-2 is deleted before the second page is requested
+	first := DefaultList(objects, q, compareTestObjects, filterTestObjects)
+	if len(first.Items) != 2 || first.Items[1].(*v1.ConfigMap).Name != "cm-1" || len(first.Continue) == 0 {
+		t.Fatalf("unexpected first page %+v", first)
+	}
+	if *first.RemainingItemCount != 3 {
+		t.Errorf("expected 3 remaining items, got %d", *first.RemainingItemCount)
+	}
+
+	objects = append(objects[:2], objects[3:]...)
+	q.Continue = first.Continue
+	q.Ascending = false
+	second := DefaultList(objects, q, compareTestObjects, filterTestObjects)
+	if len(second.Items) != 2 || second.Items[0].(*v1.ConfigMap).Name != "cm-3" || second.Items[1].(*v1.ConfigMap).Name != "cm-4" {
+		t.Fatalf("expected the second page starts after the last item of the first page in its order, got %+v", second.Items)
+	}
+	if len(second.Continue) != 0 || second.RemainingItemCount != nil {
+		t.Errorf("expected no more pages, got continue %q", second.Continue)
+	}
+
+	if err := ValidateContinue("invalid"); err == nil {
+		t.Errorf("expected invalid continue token rejected")
+	}
+}
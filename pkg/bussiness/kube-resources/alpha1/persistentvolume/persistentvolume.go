@@ -5,7 +5,9 @@ import (
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 	"strings"
 )
@@ -38,9 +40,28 @@ func (pv persistentvolumeProvider) List(namespace string, query *query.QueryInfo
 		result = append(result, nasp)
 	}
 
+	result, err = alpha1.SelectFields(result, query, pv.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+// Watch watches persistentvolumes from informers, namespace is ignored since they are cluster scoped
+func (pv persistentvolumeProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(pv.informers.Core().V1().PersistentVolumes().Informer(), "", query, filter, pv.SelectableFields)
+}
+
+// SelectableFields returns fields of persistentvolumes supported by field selectors, the same as kube-apiserver
+func (pv persistentvolumeProvider) SelectableFields(object runtime.Object) fields.Set {
+	persistentVolume, ok := object.(*corev1.PersistentVolume)
+	if !ok {
+		persistentVolume = &corev1.PersistentVolume{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&persistentVolume.ObjectMeta, false)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	persistentVolume, ok := object.(*corev1.PersistentVolume)
 	if !ok {
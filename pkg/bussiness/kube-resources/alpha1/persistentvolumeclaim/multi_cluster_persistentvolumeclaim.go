@@ -2,139 +2,76 @@ package persistentvolumeclaim
 
 import (
 	"context"
-	"strconv"
 
-	volumesnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
-	versioned "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned"
-	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/client-go/kubernetes"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
 
 	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
+	"captain/pkg/utils/clustercache"
 	"captain/pkg/utils/clusterclient"
 )
 
 type mcPersistentVolumeClaimProvider struct {
 	clusterclient.ClusterClients
+	caches *clustercache.Manager
 }
 
-func NewMCResProvider(clients clusterclient.ClusterClients) mcPersistentVolumeClaimProvider {
-	return mcPersistentVolumeClaimProvider{ClusterClients: clients}
+// resources are cached for the provider, pods are counted to annotate whether claims are in use
+var resources = []schema.GroupVersionResource{
+	corev1.SchemeGroupVersion.WithResource("persistentvolumeclaims"),
+	corev1.SchemeGroupVersion.WithResource("pods"),
 }
 
-func (pd mcPersistentVolumeClaimProvider) Get(region, cluster, namespace, name string) (runtime.Object, error) {
-	cli, err := pd.GetClientSet(region, cluster)
+func NewMCResProvider(clients clusterclient.ClusterClients, caches *clustercache.Manager) mcPersistentVolumeClaimProvider {
+	return mcPersistentVolumeClaimProvider{ClusterClients: clients, caches: caches}
+}
+
+func (pd mcPersistentVolumeClaimProvider) Get(ctx context.Context, region, cluster, namespace, name string) (runtime.Object, error) {
+	provider, err := pd.provider(ctx, region, cluster)
 	if err != nil {
 		return nil, err
 	}
+	return provider.Get(namespace, name)
+}
 
-	pvc, err := cli.CoreV1().PersistentVolumeClaims(namespace).Get(context.Background(), name, metav1.GetOptions{})
+func (pd mcPersistentVolumeClaimProvider) List(ctx context.Context, region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	provider, err := pd.provider(ctx, region, cluster)
 	if err != nil {
 		return nil, err
 	}
-
-	// we should never mutate the shared objects from informers
-	pvc = pvc.DeepCopy()
-
-	helper := &pvcHelper{Clientset: cli}
-	helper.annotatePVC(pvc)
-
-	return pvc, nil
+	return provider.List(namespace, query)
 }
 
-func (pd mcPersistentVolumeClaimProvider) List(region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
-	cli, err := pd.GetClientSet(region, cluster)
+// provider returns the provider of claims cached for a member cluster, snapshots are not allowed if
+// the cluster does not serve volumesnapshotclasses
+func (pd mcPersistentVolumeClaimProvider) provider(ctx context.Context, region, cluster string) (persistentvolumeclaimProvider, error) {
+	informers, err := pd.caches.KubernetesSharedInformerFactory(ctx, region, cluster, resources...)
 	if err != nil {
-		return nil, err
+		return persistentvolumeclaimProvider{}, err
 	}
-	list, err := cli.CoreV1().PersistentVolumeClaims(namespace).List(context.Background(), metav1.ListOptions{LabelSelector: query.LabelSelector})
+	snapshotInformers, err := pd.caches.SnapshotSharedInformerFactory(ctx, region, cluster)
 	if err != nil {
-		return nil, err
-	}
-
-	var result []runtime.Object
-	if list != nil && list.Items != nil {
-		for i := 0; i < len(list.Items); i++ {
-			pvc := &list.Items[i]
-			pvc = pvc.DeepCopy()
-			helper := &pvcHelper{Clientset: cli}
-			helper.annotatePVC(pvc)
-			result = append(result, &list.Items[i])
-		}
-	}
-
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
-}
-
-type pvcHelper struct {
-	*kubernetes.Clientset
-	pods      *v1.PodList
-	snapshots *volumesnapshotv1.VolumeSnapshotClassList
-}
-
-func (h *pvcHelper) annotatePVC(pvc *v1.PersistentVolumeClaim) {
-	inUse := h.countPods(pvc.Name, pvc.Namespace)
-	isSnapshotAllow := h.isSnapshotAllowed(pvc.GetAnnotations()[annotationStorageProvisioner])
-	if pvc.Annotations == nil {
-		pvc.Annotations = make(map[string]string)
+		return persistentvolumeclaimProvider{}, err
 	}
-	pvc.Annotations[annotationInUse] = strconv.FormatBool(inUse)
-	pvc.Annotations[annotationAllowSnapshot] = strconv.FormatBool(isSnapshotAllow)
+	return New(informers, snapshotInformers), nil
 }
 
-func (h *pvcHelper) countPods(name, namespace string) bool {
-	var pods *v1.PodList
-	if h.pods != nil {
-		pods = h.pods
-	} else {
-		list, err := h.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{})
-		if err != nil {
-			return false
-		}
-		h.pods = list
-	}
-
-	if pods != nil && pods.Items != nil {
-		for i := 0; i < len(pods.Items); i++ {
-			pod := pods.Items[i]
-			for _, pvc := range pod.Spec.Volumes {
-				if pvc.PersistentVolumeClaim != nil && pvc.PersistentVolumeClaim.ClaimName == name {
-					return true
-				}
-			}
-		}
-
-	}
-	return false
+func (pd mcPersistentVolumeClaimProvider) Compare(left, right runtime.Object, field query.Field) bool {
+	return compareFunc(left, right, field)
 }
 
-func (h *pvcHelper) isSnapshotAllowed(provisioner string) bool {
-	if len(provisioner) == 0 {
-		return false
-	}
-
-	var snapshots *volumesnapshotv1.VolumeSnapshotClassList
-	if h.snapshots != nil {
-		snapshots = h.snapshots
-	} else {
-		ssCli := versioned.New(h.RESTClient())
-		snapshots, err := ssCli.SnapshotV1().VolumeSnapshotClasses().List(context.Background(), metav1.ListOptions{})
-		if err != nil {
-			return false
-		}
-		h.snapshots = snapshots
+func (pd mcPersistentVolumeClaimProvider) Watch(ctx context.Context, region, cluster, namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	cli, err := pd.GetClientSet(region, cluster)
+	if err != nil {
+		return nil, err
 	}
-
-	if snapshots != nil && snapshots.Items != nil {
-		for i := 0; i < len(snapshots.Items); i++ {
-			volumeSnapshotClass := snapshots.Items[i]
-			if volumeSnapshotClass.Driver == provisioner {
-				return true
-			}
-		}
+	w, err := cli.CoreV1().PersistentVolumeClaims(namespace).Watch(ctx, alpha1.WatchOptions(query))
+	if err != nil {
+		return nil, err
 	}
-	return false
+	return alpha1.FilterWatch(w, query, filter)
 }
@@ -9,8 +9,10 @@ import (
 
 	snapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 )
 
@@ -28,7 +30,7 @@ type persistentvolumeclaimProvider struct {
 }
 
 func New(informer informers.SharedInformerFactory, snapshotInformer snapshotinformers.SharedInformerFactory) persistentvolumeclaimProvider {
-	return persistentvolumeclaimProvider{sharedInformers: informer}
+	return persistentvolumeclaimProvider{sharedInformers: informer, snapshotInformers: snapshotInformer}
 }
 
 func (p persistentvolumeclaimProvider) Get(namespace, name string) (runtime.Object, error) {
@@ -54,9 +56,27 @@ func (p persistentvolumeclaimProvider) List(namespace string, query *query.Query
 		p.annotatePVC(pvc)
 		result = append(result, pvc)
 	}
+	result, err = alpha1.SelectFields(result, query, p.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+func (p persistentvolumeclaimProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(p.sharedInformers.Core().V1().PersistentVolumeClaims().Informer(), namespace, query, filter, p.SelectableFields)
+}
+
+// SelectableFields returns fields of persistentvolumeclaims supported by field selectors, the same as kube-apiserver
+func (p persistentvolumeclaimProvider) SelectableFields(object runtime.Object) fields.Set {
+	pvc, ok := object.(*v1.PersistentVolumeClaim)
+	if !ok {
+		pvc = &v1.PersistentVolumeClaim{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&pvc.ObjectMeta, true)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	pvc, ok := object.(*v1.PersistentVolumeClaim)
 	if !ok {
@@ -112,7 +132,7 @@ func (p *persistentvolumeclaimProvider) countPods(name, namespace string) bool {
 }
 
 func (p *persistentvolumeclaimProvider) isSnapshotAllowed(provisioner string) bool {
-	if len(provisioner) == 0 {
+	if len(provisioner) == 0 || p.snapshotInformers == nil {
 		return false
 	}
 	volumeSnapshotClasses, err := p.snapshotInformers.Snapshot().V1().VolumeSnapshotClasses().Lister().List(labels.Everything())
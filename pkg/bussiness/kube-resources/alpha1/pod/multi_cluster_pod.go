@@ -9,56 +9,58 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 
 	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
+	"captain/pkg/utils/clustercache"
 	"captain/pkg/utils/clusterclient"
 )
 
 type mcPodProvider struct {
 	clusterclient.ClusterClients
+	caches *clustercache.Manager
 }
 
-func NewMCResProvider(clients clusterclient.ClusterClients) mcPodProvider {
-	return mcPodProvider{ClusterClients: clients}
+// resources are cached for the provider, replicasets and services are looked up by filters of pods
+var resources = []schema.GroupVersionResource{
+	v1.SchemeGroupVersion.WithResource("pods"),
+	appv1.SchemeGroupVersion.WithResource("replicasets"),
+	v1.SchemeGroupVersion.WithResource("services"),
 }
 
-func (pd mcPodProvider) Get(region, cluster, namespace, name string) (runtime.Object, error) {
-	cli, err := pd.GetClientSet(region, cluster)
+func NewMCResProvider(clients clusterclient.ClusterClients, caches *clustercache.Manager) mcPodProvider {
+	return mcPodProvider{ClusterClients: clients, caches: caches}
+}
+
+func (pd mcPodProvider) Get(ctx context.Context, region, cluster, namespace, name string) (runtime.Object, error) {
+	informers, err := pd.caches.KubernetesSharedInformerFactory(ctx, region, cluster, resources...)
 	if err != nil {
 		return nil, err
 	}
 
-	return cli.CoreV1().Pods(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	return New(informers).Get(namespace, name)
 }
 
-func (pd mcPodProvider) List(region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
-	cli, err := pd.GetClientSet(region, cluster)
-	if err != nil {
-		return nil, err
-	}
-	list, err := cli.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{LabelSelector: query.LabelSelector})
+func (pd mcPodProvider) List(ctx context.Context, region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	informers, err := pd.caches.KubernetesSharedInformerFactory(ctx, region, cluster, resources...)
 	if err != nil {
 		return nil, err
 	}
 
-	var result []runtime.Object
-	if list != nil && list.Items != nil {
-		for i := 0; i < len(list.Items); i++ {
-			result = append(result, &list.Items[i])
-		}
-	}
-
-	podCli := PodProviderClient{Clientset: cli}
-	return alpha1.DefaultList(result, query, compareFunc, podCli.filter), nil
+	return New(informers).List(namespace, query)
 }
 
+// PodProviderClient filters pods of watches of member clusters, owners and services of pods are
+// retrieved from member clusters
 type PodProviderClient struct {
 	*kubernetes.Clientset
 	replicaSets *appv1.ReplicaSetList
-	service     *v1.Service
+	// services are cached by namespace/name, since a filter may name several services
+	services map[string]*v1.Service
 }
 
 func (c *PodProviderClient) filter(object runtime.Object, filter query.Filter) bool {
@@ -73,27 +75,19 @@ func (c *PodProviderClient) filter(object runtime.Object, filter query.Filter) b
 	case query.FieldOwnerName:
 		kind := filter.Field
 		name := filter.Value
-		if !c.podBelongTo(pod, string(kind), string(name)) {
-			return false
-		}
+		return c.podBelongTo(pod, string(kind), string(name))
+	// every filter is evaluated for a single value, operators of filters are applied by DefaultList
 	case "nodeName":
-		if pod.Spec.NodeName != string(filter.Value) {
-			return false
-		}
+		return pod.Spec.NodeName == string(filter.Value)
 	case "pvcName":
-		if !podBindPVC(pod, string(filter.Value)) {
-			return false
-		}
+		return podBindPVC(pod, string(filter.Value))
 	case "serviceName":
-		if !c.podBelongToService(pod, string(filter.Value)) {
-			return false
-		}
+		return c.podBelongToService(pod, string(filter.Value))
 	case query.FieldStatus:
 		return strings.Compare(string(pod.Status.Phase), string(filter.Value)) == 0
 	default:
 		return alpha1.DefaultObjectMetaFilter(pod.ObjectMeta, filter)
 	}
-	return false
 }
 
 func (c *PodProviderClient) podBelongTo(item *v1.Pod, kind string, name string) bool {
@@ -145,21 +139,39 @@ func (c *PodProviderClient) podBelongToDeployment(item *v1.Pod, deploymentName s
 }
 
 func (c *PodProviderClient) podBelongToService(item *v1.Pod, serviceName string) bool {
-	var service *v1.Service
-	if c.service != nil {
-		service = c.service
-	} else {
-		service, err := c.CoreV1().Services(item.Namespace).Get(context.Background(), serviceName, metav1.GetOptions{})
+	key := item.Namespace + "/" + serviceName
+	service, ok := c.services[key]
+	if !ok {
+		var err error
+		service, err = c.CoreV1().Services(item.Namespace).Get(context.Background(), serviceName, metav1.GetOptions{})
 		if err != nil {
 			return false
 		}
-		c.service = service
-	}
-	if service != nil {
-		selector := labels.Set(service.Spec.Selector).AsSelectorPreValidated()
-		if selector.Empty() || !selector.Matches(labels.Set(item.Labels)) {
-			return false
+		if c.services == nil {
+			c.services = make(map[string]*v1.Service)
 		}
+		c.services[key] = service
+	}
+	selector := labels.Set(service.Spec.Selector).AsSelectorPreValidated()
+	if selector.Empty() || !selector.Matches(labels.Set(item.Labels)) {
+		return false
 	}
 	return true
 }
+
+func (pd mcPodProvider) Compare(left, right runtime.Object, field query.Field) bool {
+	return compareFunc(left, right, field)
+}
+
+func (pd mcPodProvider) Watch(ctx context.Context, region, cluster, namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	cli, err := pd.GetClientSet(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	w, err := cli.CoreV1().Pods(namespace).Watch(ctx, alpha1.WatchOptions(query))
+	if err != nil {
+		return nil, err
+	}
+	podCli := PodProviderClient{Clientset: cli}
+	return alpha1.FilterWatch(w, query, podCli.filter)
+}
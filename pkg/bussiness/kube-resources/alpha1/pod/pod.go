@@ -6,8 +6,10 @@ import (
 	"captain/pkg/unify/response"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 	"strings"
 )
@@ -35,9 +37,35 @@ func (pd podProvider) List(namespace string, query *query.QueryInfo) (*response.
 		result = append(result, pod)
 	}
 
+	result, err = alpha1.SelectFields(result, query, pd.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, pd.filter), nil
 }
 
+func (pd podProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(pd.sharedInformers.Core().V1().Pods().Informer(), namespace, query, pd.filter, pd.SelectableFields)
+}
+
+// SelectableFields returns fields of pods supported by field selectors, the same as kube-apiserver
+func (pd podProvider) SelectableFields(object runtime.Object) fields.Set {
+	pod, ok := object.(*v1.Pod)
+	if !ok {
+		pod = &v1.Pod{}
+	}
+	return alpha1.AddObjectMetaFieldsSet(fields.Set{
+		"spec.nodeName":            pod.Spec.NodeName,
+		"spec.restartPolicy":       string(pod.Spec.RestartPolicy),
+		"spec.schedulerName":       pod.Spec.SchedulerName,
+		"spec.serviceAccountName":  pod.Spec.ServiceAccountName,
+		"status.phase":             string(pod.Status.Phase),
+		"status.podIP":             pod.Status.PodIP,
+		"status.nominatedNodeName": pod.Status.NominatedNodeName,
+	}, &pod.ObjectMeta, true)
+}
+
 func (pd *podProvider) filter(object runtime.Object, filter query.Filter) bool {
 	pod, ok := object.(*v1.Pod)
 	if !ok {
@@ -50,27 +78,19 @@ func (pd *podProvider) filter(object runtime.Object, filter query.Filter) bool {
 	case query.FieldOwnerName:
 		kind := filter.Field
 		name := filter.Value
-		if !pd.podBelongTo(pod, string(kind), string(name)) {
-			return false
-		}
+		return pd.podBelongTo(pod, string(kind), string(name))
+	// every filter is evaluated for a single value, operators of filters are applied by DefaultList
 	case "nodeName":
-		if pod.Spec.NodeName != string(filter.Value) {
-			return false
-		}
+		return pod.Spec.NodeName == string(filter.Value)
 	case "pvcName":
-		if !podBindPVC(pod, string(filter.Value)) {
-			return false
-		}
+		return podBindPVC(pod, string(filter.Value))
 	case "serviceName":
-		if !pd.podBelongToService(pod, string(filter.Value)) {
-			return false
-		}
+		return pd.podBelongToService(pod, string(filter.Value))
 	case query.FieldStatus:
 		return strings.Compare(string(pod.Status.Phase), string(filter.Value)) == 0
 	default:
 		return alpha1.DefaultObjectMetaFilter(pod.ObjectMeta, filter)
 	}
-	return false
 }
 
 func compareFunc(left, right runtime.Object, field query.Field) bool {
@@ -85,16 +105,20 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 	}
 	switch field {
 	case query.FieldStartTime:
+		// pods not started yet are the last
 		if leftPod.Status.StartTime == nil {
 			return false
 		}
 		if rightPod.Status.StartTime == nil {
 			return true
 		}
+		if leftPod.Status.StartTime.Equal(rightPod.Status.StartTime) {
+			return strings.Compare(leftPod.Name, rightPod.Name) < 0
+		}
+		return leftPod.Status.StartTime.After(rightPod.Status.StartTime.Time)
 	default:
 		return alpha1.DefaultObjectMetaCompare(leftPod.ObjectMeta, rightPod.ObjectMeta, field)
 	}
-	return false
 }
 func (pd *podProvider) podBelongTo(item *v1.Pod, kind string, name string) bool {
 	switch kind {
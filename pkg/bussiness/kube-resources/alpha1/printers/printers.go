@@ -0,0 +1,852 @@
+package printers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// labelNodeRolePrefix and nodeLabelRole are labels of node roles, the same as kubectl
+	labelNodeRolePrefix = "node-role.kubernetes.io/"
+	nodeLabelRole       = "kubernetes.io/role"
+)
+
+// handlers print objects of resources served by ResourceProcessor, keyed by groups and resources, so that
+// custom resources of the same names are not printed by them
+var handlers = map[schema.GroupResource]*tableHandler{
+	v1.Resource("pods"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Ready", Type: "string", Description: "The aggregate readiness state of this pod for accepting traffic."},
+			{Name: "Status", Type: "string", Description: "The aggregate status of the containers in this pod."},
+			{Name: "Restarts", Type: "string", Description: "The number of times the containers in this pod have been restarted and when the last container in this pod has restarted."},
+			ageColumn,
+			{Name: "IP", Type: "string", Priority: 1, Description: v1.PodStatus{}.SwaggerDoc()["podIP"]},
+			{Name: "Node", Type: "string", Priority: 1, Description: v1.PodSpec{}.SwaggerDoc()["nodeName"]},
+			{Name: "Nominated Node", Type: "string", Priority: 1, Description: v1.PodStatus{}.SwaggerDoc()["nominatedNodeName"]},
+		},
+		print: printPod,
+	},
+	appsv1.Resource("deployments"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Ready", Type: "string", Description: "Number of the pod with ready state"},
+			{Name: "Up-to-date", Type: "integer", Description: appsv1.DeploymentStatus{}.SwaggerDoc()["updatedReplicas"]},
+			{Name: "Available", Type: "integer", Description: appsv1.DeploymentStatus{}.SwaggerDoc()["availableReplicas"]},
+			ageColumn,
+			{Name: "Containers", Type: "string", Priority: 1, Description: "Names of each container in the template."},
+			{Name: "Images", Type: "string", Priority: 1, Description: "Images referenced by each container in the template."},
+			{Name: "Selector", Type: "string", Priority: 1, Description: appsv1.DeploymentSpec{}.SwaggerDoc()["selector"]},
+		},
+		print: printDeployment,
+	},
+	appsv1.Resource("statefulsets"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Ready", Type: "string", Description: "Number of the pod with ready state"},
+			ageColumn,
+			{Name: "Containers", Type: "string", Priority: 1, Description: "Names of each container in the template."},
+			{Name: "Images", Type: "string", Priority: 1, Description: "Images referenced by each container in the template."},
+		},
+		print: printStatefulSet,
+	},
+	appsv1.Resource("daemonsets"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Desired", Type: "integer", Description: appsv1.DaemonSetStatus{}.SwaggerDoc()["desiredNumberScheduled"]},
+			{Name: "Current", Type: "integer", Description: appsv1.DaemonSetStatus{}.SwaggerDoc()["currentNumberScheduled"]},
+			{Name: "Ready", Type: "integer", Description: appsv1.DaemonSetStatus{}.SwaggerDoc()["numberReady"]},
+			{Name: "Up-to-date", Type: "integer", Description: appsv1.DaemonSetStatus{}.SwaggerDoc()["updatedNumberScheduled"]},
+			{Name: "Available", Type: "integer", Description: appsv1.DaemonSetStatus{}.SwaggerDoc()["numberAvailable"]},
+			{Name: "Node Selector", Type: "string", Description: v1.PodSpec{}.SwaggerDoc()["nodeSelector"]},
+			ageColumn,
+			{Name: "Containers", Type: "string", Priority: 1, Description: "Names of each container in the template."},
+			{Name: "Images", Type: "string", Priority: 1, Description: "Images referenced by each container in the template."},
+			{Name: "Selector", Type: "string", Priority: 1, Description: appsv1.DaemonSetSpec{}.SwaggerDoc()["selector"]},
+		},
+		print: printDaemonSet,
+	},
+	batchv1.Resource("jobs"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Completions", Type: "string", Description: batchv1.JobStatus{}.SwaggerDoc()["succeeded"]},
+			{Name: "Duration", Type: "string", Description: "Time required to complete the job."},
+			ageColumn,
+			{Name: "Containers", Type: "string", Priority: 1, Description: "Names of each container in the template."},
+			{Name: "Images", Type: "string", Priority: 1, Description: "Images referenced by each container in the template."},
+			{Name: "Selector", Type: "string", Priority: 1, Description: batchv1.JobSpec{}.SwaggerDoc()["selector"]},
+		},
+		print: printJob,
+	},
+	batchv1.Resource("cronjobs"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Schedule", Type: "string", Description: batchv1beta1.CronJobSpec{}.SwaggerDoc()["schedule"]},
+			{Name: "Suspend", Type: "boolean", Description: batchv1beta1.CronJobSpec{}.SwaggerDoc()["suspend"]},
+			{Name: "Active", Type: "integer", Description: batchv1beta1.CronJobStatus{}.SwaggerDoc()["active"]},
+			{Name: "Last Schedule", Type: "string", Description: batchv1beta1.CronJobStatus{}.SwaggerDoc()["lastScheduleTime"]},
+			ageColumn,
+			{Name: "Containers", Type: "string", Priority: 1, Description: "Names of each container in the template."},
+			{Name: "Images", Type: "string", Priority: 1, Description: "Images referenced by each container in the template."},
+			{Name: "Selector", Type: "string", Priority: 1, Description: batchv1.JobSpec{}.SwaggerDoc()["selector"]},
+		},
+		print: printCronJob,
+	},
+	v1.Resource("services"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Type", Type: "string", Description: v1.ServiceSpec{}.SwaggerDoc()["type"]},
+			{Name: "Cluster-IP", Type: "string", Description: v1.ServiceSpec{}.SwaggerDoc()["clusterIP"]},
+			{Name: "External-IP", Type: "string", Description: v1.ServiceSpec{}.SwaggerDoc()["externalIPs"]},
+			{Name: "Port(s)", Type: "string", Description: v1.ServiceSpec{}.SwaggerDoc()["ports"]},
+			ageColumn,
+			{Name: "Selector", Type: "string", Priority: 1, Description: v1.ServiceSpec{}.SwaggerDoc()["selector"]},
+		},
+		print: printService,
+	},
+	networkingv1.Resource("ingresses"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Class", Type: "string", Description: "The name of the IngressClass resource that should be used for additional configuration"},
+			{Name: "Hosts", Type: "string", Description: "Hosts that incoming requests are matched against before the ingress rule"},
+			{Name: "Address", Type: "string", Description: "Address is a list containing ingress points for the load-balancer"},
+			{Name: "Ports", Type: "string", Description: "Ports of TLS configurations that open"},
+			ageColumn,
+		},
+		print: printIngress,
+	},
+	v1.Resource("configmaps"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Data", Type: "integer", Description: v1.ConfigMap{}.SwaggerDoc()["data"]},
+			ageColumn,
+		},
+		print: printConfigMap,
+	},
+	v1.Resource("secrets"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Type", Type: "string", Description: v1.Secret{}.SwaggerDoc()["type"]},
+			{Name: "Data", Type: "integer", Description: v1.Secret{}.SwaggerDoc()["data"]},
+			ageColumn,
+		},
+		print: printSecret,
+	},
+	v1.Resource("serviceaccounts"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Secrets", Type: "integer", Description: v1.ServiceAccount{}.SwaggerDoc()["secrets"]},
+			ageColumn,
+		},
+		print: printServiceAccount,
+	},
+	v1.Resource("namespaces"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Status", Type: "string", Description: "The status of the namespace"},
+			ageColumn,
+		},
+		print: printNamespace,
+	},
+	v1.Resource("nodes"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Status", Type: "string", Description: "The status of the node"},
+			{Name: "Roles", Type: "string", Description: "The roles of the node"},
+			ageColumn,
+			{Name: "Version", Type: "string", Description: v1.NodeSystemInfo{}.SwaggerDoc()["kubeletVersion"]},
+			{Name: "Internal-IP", Type: "string", Priority: 1, Description: v1.NodeStatus{}.SwaggerDoc()["addresses"]},
+			{Name: "External-IP", Type: "string", Priority: 1, Description: v1.NodeStatus{}.SwaggerDoc()["addresses"]},
+			{Name: "OS-Image", Type: "string", Priority: 1, Description: v1.NodeSystemInfo{}.SwaggerDoc()["osImage"]},
+			{Name: "Kernel-Version", Type: "string", Priority: 1, Description: v1.NodeSystemInfo{}.SwaggerDoc()["kernelVersion"]},
+			{Name: "Container-Runtime", Type: "string", Priority: 1, Description: v1.NodeSystemInfo{}.SwaggerDoc()["containerRuntimeVersion"]},
+		},
+		print: printNode,
+	},
+	v1.Resource("persistentvolumes"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Capacity", Type: "string", Description: v1.PersistentVolumeSpec{}.SwaggerDoc()["capacity"]},
+			{Name: "Access Modes", Type: "string", Description: v1.PersistentVolumeSpec{}.SwaggerDoc()["accessModes"]},
+			{Name: "Reclaim Policy", Type: "string", Description: v1.PersistentVolumeSpec{}.SwaggerDoc()["persistentVolumeReclaimPolicy"]},
+			{Name: "Status", Type: "string", Description: v1.PersistentVolumeStatus{}.SwaggerDoc()["phase"]},
+			{Name: "Claim", Type: "string", Description: v1.PersistentVolumeSpec{}.SwaggerDoc()["claimRef"]},
+			{Name: "StorageClass", Type: "string", Description: "StorageClass of the pv"},
+			{Name: "Reason", Type: "string", Description: v1.PersistentVolumeStatus{}.SwaggerDoc()["reason"]},
+			ageColumn,
+			{Name: "VolumeMode", Type: "string", Priority: 1, Description: v1.PersistentVolumeSpec{}.SwaggerDoc()["volumeMode"]},
+		},
+		print: printPersistentVolume,
+	},
+	v1.Resource("persistentvolumeclaims"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Status", Type: "string", Description: v1.PersistentVolumeClaimStatus{}.SwaggerDoc()["phase"]},
+			{Name: "Volume", Type: "string", Description: v1.PersistentVolumeClaimSpec{}.SwaggerDoc()["volumeName"]},
+			{Name: "Capacity", Type: "string", Description: v1.PersistentVolumeClaimStatus{}.SwaggerDoc()["capacity"]},
+			{Name: "Access Modes", Type: "string", Description: v1.PersistentVolumeClaimStatus{}.SwaggerDoc()["accessModes"]},
+			{Name: "StorageClass", Type: "string", Description: "StorageClass of the pvc"},
+			ageColumn,
+			{Name: "VolumeMode", Type: "string", Priority: 1, Description: v1.PersistentVolumeClaimSpec{}.SwaggerDoc()["volumeMode"]},
+		},
+		print: printPersistentVolumeClaim,
+	},
+	storagev1.Resource("storageclasses"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Provisioner", Type: "string", Description: storagev1.StorageClass{}.SwaggerDoc()["provisioner"]},
+			{Name: "ReclaimPolicy", Type: "string", Description: storagev1.StorageClass{}.SwaggerDoc()["reclaimPolicy"]},
+			{Name: "VolumeBindingMode", Type: "string", Description: storagev1.StorageClass{}.SwaggerDoc()["volumeBindingMode"]},
+			{Name: "AllowVolumeExpansion", Type: "string", Description: storagev1.StorageClass{}.SwaggerDoc()["allowVolumeExpansion"]},
+			ageColumn,
+		},
+		print: printStorageClass,
+	},
+	rbacv1.Resource("roles"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Created At", Type: "date", Description: objectMetaDescriptions["creationTimestamp"]},
+		},
+		print: printRole,
+	},
+	rbacv1.Resource("clusterroles"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Created At", Type: "date", Description: objectMetaDescriptions["creationTimestamp"]},
+		},
+		print: printClusterRole,
+	},
+	rbacv1.Resource("rolebindings"): {
+		columns: roleBindingColumns,
+		print:   printRoleBinding,
+	},
+	rbacv1.Resource("clusterrolebindings"): {
+		columns: roleBindingColumns,
+		print:   printClusterRoleBinding,
+	},
+	networkingv1.Resource("networkpolicies"): {
+		columns: []metav1.TableColumnDefinition{
+			nameColumn,
+			{Name: "Pod-Selector", Type: "string", Description: networkingv1.NetworkPolicySpec{}.SwaggerDoc()["podSelector"]},
+			ageColumn,
+		},
+		print: printNetworkPolicy,
+	},
+}
+
+var roleBindingColumns = []metav1.TableColumnDefinition{
+	nameColumn,
+	{Name: "Role", Type: "string", Description: rbacv1.RoleBinding{}.SwaggerDoc()["roleRef"]},
+	ageColumn,
+	{Name: "Users", Type: "string", Priority: 1, Description: "Users in the roleBinding"},
+	{Name: "Groups", Type: "string", Priority: 1, Description: "Groups in the roleBinding"},
+	{Name: "ServiceAccounts", Type: "string", Priority: 1, Description: "ServiceAccounts in the roleBinding"},
+}
+
+func printPod(obj runtime.Object) ([]interface{}, bool) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return nil, false
+	}
+
+	restarts := 0
+	totalContainers := len(pod.Spec.Containers)
+	readyContainers := 0
+	lastRestartDate := metav1.NewTime(time.Time{})
+
+	reason := string(pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		reason = pod.Status.Reason
+	}
+
+	initializing := false
+	for i := range pod.Status.InitContainerStatuses {
+		container := pod.Status.InitContainerStatuses[i]
+		restarts += int(container.RestartCount)
+		if container.LastTerminationState.Terminated != nil {
+			terminatedDate := container.LastTerminationState.Terminated.FinishedAt
+			if lastRestartDate.Before(&terminatedDate) {
+				lastRestartDate = terminatedDate
+			}
+		}
+		switch {
+		case container.State.Terminated != nil && container.State.Terminated.ExitCode == 0:
+			continue
+		case container.State.Terminated != nil:
+			// initialization is failed
+			if len(container.State.Terminated.Reason) == 0 {
+				if container.State.Terminated.Signal != 0 {
+					reason = fmt.Sprintf("Init:Signal:%d", container.State.Terminated.Signal)
+				} else {
+					reason = fmt.Sprintf("Init:ExitCode:%d", container.State.Terminated.ExitCode)
+				}
+			} else {
+				reason = "Init:" + container.State.Terminated.Reason
+			}
+			initializing = true
+		case container.State.Waiting != nil && len(container.State.Waiting.Reason) > 0 && container.State.Waiting.Reason != "PodInitializing":
+			reason = "Init:" + container.State.Waiting.Reason
+			initializing = true
+		default:
+			reason = fmt.Sprintf("Init:%d/%d", i, len(pod.Spec.InitContainers))
+			initializing = true
+		}
+		break
+	}
+	if !initializing {
+		restarts = 0
+		hasRunning := false
+		for i := len(pod.Status.ContainerStatuses) - 1; i >= 0; i-- {
+			container := pod.Status.ContainerStatuses[i]
+
+			restarts += int(container.RestartCount)
+			if container.LastTerminationState.Terminated != nil {
+				terminatedDate := container.LastTerminationState.Terminated.FinishedAt
+				if lastRestartDate.Before(&terminatedDate) {
+					lastRestartDate = terminatedDate
+				}
+			}
+			if container.State.Waiting != nil && container.State.Waiting.Reason != "" {
+				reason = container.State.Waiting.Reason
+			} else if container.State.Terminated != nil && container.State.Terminated.Reason != "" {
+				reason = container.State.Terminated.Reason
+			} else if container.State.Terminated != nil && container.State.Terminated.Reason == "" {
+				if container.State.Terminated.Signal != 0 {
+					reason = fmt.Sprintf("Signal:%d", container.State.Terminated.Signal)
+				} else {
+					reason = fmt.Sprintf("ExitCode:%d", container.State.Terminated.ExitCode)
+				}
+			} else if container.Ready && container.State.Running != nil {
+				hasRunning = true
+				readyContainers++
+			}
+		}
+
+		// change pod status back to "Running" if there is at least one container still reporting as "Running" status
+		if reason == "Completed" && hasRunning {
+			if hasPodReadyCondition(pod.Status.Conditions) {
+				reason = "Running"
+			} else {
+				reason = "NotReady"
+			}
+		}
+	}
+
+	if pod.DeletionTimestamp != nil && pod.Status.Reason == "NodeLost" {
+		reason = "Unknown"
+	} else if pod.DeletionTimestamp != nil {
+		reason = "Terminating"
+	}
+
+	restartsStr := strconv.Itoa(restarts)
+	if !lastRestartDate.IsZero() {
+		restartsStr = fmt.Sprintf("%d (%s ago)", restarts, translateTimestampSince(lastRestartDate))
+	}
+
+	nodeName := pod.Spec.NodeName
+	nominatedNodeName := pod.Status.NominatedNodeName
+	podIP := pod.Status.PodIP
+	if podIP == "" {
+		podIP = "<none>"
+	}
+	if nodeName == "" {
+		nodeName = "<none>"
+	}
+	if nominatedNodeName == "" {
+		nominatedNodeName = "<none>"
+	}
+
+	return []interface{}{pod.Name, fmt.Sprintf("%d/%d", readyContainers, totalContainers), reason, restartsStr,
+		translateTimestampSince(pod.CreationTimestamp), podIP, nodeName, nominatedNodeName}, true
+}
+
+func hasPodReadyCondition(conditions []v1.PodCondition) bool {
+	for _, condition := range conditions {
+		if condition.Type == v1.PodReady && condition.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func printDeployment(obj runtime.Object) ([]interface{}, bool) {
+	deployment, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return nil, false
+	}
+	desiredReplicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desiredReplicas = *deployment.Spec.Replicas
+	}
+	containers, images := layoutContainerCells(deployment.Spec.Template.Spec.Containers)
+	return []interface{}{deployment.Name, fmt.Sprintf("%d/%d", deployment.Status.ReadyReplicas, desiredReplicas),
+		int64(deployment.Status.UpdatedReplicas), int64(deployment.Status.AvailableReplicas),
+		translateTimestampSince(deployment.CreationTimestamp), containers, images, formatSelector(deployment.Spec.Selector)}, true
+}
+
+func printStatefulSet(obj runtime.Object) ([]interface{}, bool) {
+	statefulSet, ok := obj.(*appsv1.StatefulSet)
+	if !ok {
+		return nil, false
+	}
+	desiredReplicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		desiredReplicas = *statefulSet.Spec.Replicas
+	}
+	containers, images := layoutContainerCells(statefulSet.Spec.Template.Spec.Containers)
+	return []interface{}{statefulSet.Name, fmt.Sprintf("%d/%d", statefulSet.Status.ReadyReplicas, desiredReplicas),
+		translateTimestampSince(statefulSet.CreationTimestamp), containers, images}, true
+}
+
+func printDaemonSet(obj runtime.Object) ([]interface{}, bool) {
+	daemonSet, ok := obj.(*appsv1.DaemonSet)
+	if !ok {
+		return nil, false
+	}
+	containers, images := layoutContainerCells(daemonSet.Spec.Template.Spec.Containers)
+	return []interface{}{daemonSet.Name, int64(daemonSet.Status.DesiredNumberScheduled), int64(daemonSet.Status.CurrentNumberScheduled),
+		int64(daemonSet.Status.NumberReady), int64(daemonSet.Status.UpdatedNumberScheduled), int64(daemonSet.Status.NumberAvailable),
+		labels(daemonSet.Spec.Template.Spec.NodeSelector), translateTimestampSince(daemonSet.CreationTimestamp),
+		containers, images, formatSelector(daemonSet.Spec.Selector)}, true
+}
+
+func printJob(obj runtime.Object) ([]interface{}, bool) {
+	job, ok := obj.(*batchv1.Job)
+	if !ok {
+		return nil, false
+	}
+	var completions string
+	if job.Spec.Completions != nil {
+		completions = fmt.Sprintf("%d/%d", job.Status.Succeeded, *job.Spec.Completions)
+	} else {
+		parallelism := int32(0)
+		if job.Spec.Parallelism != nil {
+			parallelism = *job.Spec.Parallelism
+		}
+		if parallelism > 1 {
+			completions = fmt.Sprintf("%d/1 of %d", job.Status.Succeeded, parallelism)
+		} else {
+			completions = fmt.Sprintf("%d/1", job.Status.Succeeded)
+		}
+	}
+	var jobDuration string
+	switch {
+	case job.Status.StartTime == nil:
+	case job.Status.CompletionTime == nil:
+		jobDuration = duration.HumanDuration(time.Since(job.Status.StartTime.Time))
+	default:
+		jobDuration = duration.HumanDuration(job.Status.CompletionTime.Sub(job.Status.StartTime.Time))
+	}
+	containers, images := layoutContainerCells(job.Spec.Template.Spec.Containers)
+	return []interface{}{job.Name, completions, jobDuration, translateTimestampSince(job.CreationTimestamp),
+		containers, images, formatSelector(job.Spec.Selector)}, true
+}
+
+func printCronJob(obj runtime.Object) ([]interface{}, bool) {
+	cronJob, ok := obj.(*batchv1beta1.CronJob)
+	if !ok {
+		return nil, false
+	}
+	lastScheduleTime := "<none>"
+	if cronJob.Status.LastScheduleTime != nil {
+		lastScheduleTime = translateTimestampSince(*cronJob.Status.LastScheduleTime)
+	}
+	suspend := cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+	containers, images := layoutContainerCells(cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers)
+	return []interface{}{cronJob.Name, cronJob.Spec.Schedule, suspend, int64(len(cronJob.Status.Active)), lastScheduleTime,
+		translateTimestampSince(cronJob.CreationTimestamp), containers, images, formatSelector(cronJob.Spec.JobTemplate.Spec.Selector)}, true
+}
+
+func printService(obj runtime.Object) ([]interface{}, bool) {
+	service, ok := obj.(*v1.Service)
+	if !ok {
+		return nil, false
+	}
+	clusterIP := service.Spec.ClusterIP
+	if len(clusterIP) == 0 {
+		clusterIP = "<none>"
+	}
+	return []interface{}{service.Name, string(service.Spec.Type), clusterIP, serviceExternalIP(service),
+		servicePorts(service.Spec.Ports), translateTimestampSince(service.CreationTimestamp), labels(service.Spec.Selector)}, true
+}
+
+func serviceExternalIP(service *v1.Service) string {
+	switch service.Spec.Type {
+	case v1.ServiceTypeClusterIP, v1.ServiceTypeNodePort:
+		if len(service.Spec.ExternalIPs) > 0 {
+			return strings.Join(service.Spec.ExternalIPs, ",")
+		}
+		return "<none>"
+	case v1.ServiceTypeLoadBalancer:
+		ips := append(loadBalancerIPs(service.Status.LoadBalancer.Ingress), service.Spec.ExternalIPs...)
+		if len(ips) > 0 {
+			return strings.Join(ips, ",")
+		}
+		return "<pending>"
+	case v1.ServiceTypeExternalName:
+		return service.Spec.ExternalName
+	}
+	return "<unknown>"
+}
+
+func loadBalancerIPs(ingresses []v1.LoadBalancerIngress) []string {
+	var ips []string
+	for _, ingress := range ingresses {
+		if ingress.IP != "" {
+			ips = append(ips, ingress.IP)
+		} else if ingress.Hostname != "" {
+			ips = append(ips, ingress.Hostname)
+		}
+	}
+	return ips
+}
+
+func servicePorts(ports []v1.ServicePort) string {
+	if len(ports) == 0 {
+		return "<none>"
+	}
+	pieces := make([]string, len(ports))
+	for i, port := range ports {
+		pieces[i] = fmt.Sprintf("%d/%s", port.Port, port.Protocol)
+		if port.NodePort > 0 {
+			pieces[i] = fmt.Sprintf("%d:%d/%s", port.Port, port.NodePort, port.Protocol)
+		}
+	}
+	return strings.Join(pieces, ",")
+}
+
+func printIngress(obj runtime.Object) ([]interface{}, bool) {
+	ingress, ok := obj.(*networkingv1.Ingress)
+	if !ok {
+		return nil, false
+	}
+	className := "<none>"
+	if ingress.Spec.IngressClassName != nil {
+		className = *ingress.Spec.IngressClassName
+	}
+	var hosts []string
+	for _, rule := range ingress.Spec.Rules {
+		if len(rule.Host) != 0 {
+			hosts = append(hosts, rule.Host)
+		}
+	}
+	hostsStr := "*"
+	if len(hosts) != 0 {
+		hostsStr = strings.Join(hosts, ",")
+	}
+	var addresses []string
+	for _, ingress := range ingress.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			addresses = append(addresses, ingress.IP)
+		} else if ingress.Hostname != "" {
+			addresses = append(addresses, ingress.Hostname)
+		}
+	}
+	ports := "80"
+	if len(ingress.Spec.TLS) != 0 {
+		ports = "80, 443"
+	}
+	return []interface{}{ingress.Name, className, hostsStr, strings.Join(addresses, ","), ports,
+		translateTimestampSince(ingress.CreationTimestamp)}, true
+}
+
+func printConfigMap(obj runtime.Object) ([]interface{}, bool) {
+	configMap, ok := obj.(*v1.ConfigMap)
+	if !ok {
+		return nil, false
+	}
+	return []interface{}{configMap.Name, int64(len(configMap.Data) + len(configMap.BinaryData)),
+		translateTimestampSince(configMap.CreationTimestamp)}, true
+}
+
+func printSecret(obj runtime.Object) ([]interface{}, bool) {
+	secret, ok := obj.(*v1.Secret)
+	if !ok {
+		return nil, false
+	}
+	return []interface{}{secret.Name, string(secret.Type), int64(len(secret.Data)), translateTimestampSince(secret.CreationTimestamp)}, true
+}
+
+func printServiceAccount(obj runtime.Object) ([]interface{}, bool) {
+	serviceAccount, ok := obj.(*v1.ServiceAccount)
+	if !ok {
+		return nil, false
+	}
+	return []interface{}{serviceAccount.Name, int64(len(serviceAccount.Secrets)), translateTimestampSince(serviceAccount.CreationTimestamp)}, true
+}
+
+func printNamespace(obj runtime.Object) ([]interface{}, bool) {
+	namespace, ok := obj.(*v1.Namespace)
+	if !ok {
+		return nil, false
+	}
+	return []interface{}{namespace.Name, string(namespace.Status.Phase), translateTimestampSince(namespace.CreationTimestamp)}, true
+}
+
+func printNode(obj runtime.Object) ([]interface{}, bool) {
+	node, ok := obj.(*v1.Node)
+	if !ok {
+		return nil, false
+	}
+
+	status := []string{"Unknown"}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != v1.NodeReady {
+			continue
+		}
+		if condition.Status == v1.ConditionTrue {
+			status[0] = string(condition.Type)
+		} else {
+			status[0] = "Not" + string(condition.Type)
+		}
+	}
+	if node.Spec.Unschedulable {
+		status = append(status, "SchedulingDisabled")
+	}
+
+	roles := strings.Join(findNodeRoles(node), ",")
+	if len(roles) == 0 {
+		roles = "<none>"
+	}
+
+	internalIP, externalIP := "<none>", "<none>"
+	for _, address := range node.Status.Addresses {
+		if address.Type == v1.NodeInternalIP && internalIP == "<none>" {
+			internalIP = address.Address
+		}
+		if address.Type == v1.NodeExternalIP && externalIP == "<none>" {
+			externalIP = address.Address
+		}
+	}
+	osImage, kernelVersion, crVersion := node.Status.NodeInfo.OSImage, node.Status.NodeInfo.KernelVersion, node.Status.NodeInfo.ContainerRuntimeVersion
+	if osImage == "" {
+		osImage = "<unknown>"
+	}
+	if kernelVersion == "" {
+		kernelVersion = "<unknown>"
+	}
+	if crVersion == "" {
+		crVersion = "<unknown>"
+	}
+	return []interface{}{node.Name, strings.Join(status, ","), roles, translateTimestampSince(node.CreationTimestamp),
+		node.Status.NodeInfo.KubeletVersion, internalIP, externalIP, osImage, kernelVersion, crVersion}, true
+}
+
+// findNodeRoles returns the roles of a given node, the same as kubectl
+func findNodeRoles(node *v1.Node) []string {
+	roles := sets.NewString()
+	for k, v := range node.Labels {
+		switch {
+		case strings.HasPrefix(k, labelNodeRolePrefix):
+			if role := strings.TrimPrefix(k, labelNodeRolePrefix); len(role) > 0 {
+				roles.Insert(role)
+			}
+		case k == nodeLabelRole && v != "":
+			roles.Insert(v)
+		}
+	}
+	return roles.List()
+}
+
+func printPersistentVolume(obj runtime.Object) ([]interface{}, bool) {
+	pv, ok := obj.(*v1.PersistentVolume)
+	if !ok {
+		return nil, false
+	}
+	claimRefUID := ""
+	if pv.Spec.ClaimRef != nil {
+		claimRefUID = pv.Spec.ClaimRef.Namespace + "/" + pv.Spec.ClaimRef.Name
+	}
+	storage := pv.Spec.Capacity[v1.ResourceStorage]
+	phase := string(pv.Status.Phase)
+	if pv.DeletionTimestamp != nil {
+		phase = "Terminating"
+	}
+	volumeMode := "<unset>"
+	if pv.Spec.VolumeMode != nil {
+		volumeMode = string(*pv.Spec.VolumeMode)
+	}
+	return []interface{}{pv.Name, storage.String(), accessModes(pv.Spec.AccessModes), string(pv.Spec.PersistentVolumeReclaimPolicy),
+		phase, claimRefUID, pv.Spec.StorageClassName, pv.Status.Reason, translateTimestampSince(pv.CreationTimestamp), volumeMode}, true
+}
+
+func printPersistentVolumeClaim(obj runtime.Object) ([]interface{}, bool) {
+	pvc, ok := obj.(*v1.PersistentVolumeClaim)
+	if !ok {
+		return nil, false
+	}
+	phase := string(pvc.Status.Phase)
+	if pvc.DeletionTimestamp != nil {
+		phase = "Terminating"
+	}
+	storage, modes := "", ""
+	if pvc.Spec.VolumeName != "" {
+		modes = accessModes(pvc.Status.AccessModes)
+		quantity := pvc.Status.Capacity[v1.ResourceStorage]
+		storage = quantity.String()
+	}
+	storageClass := ""
+	if pvc.Spec.StorageClassName != nil {
+		storageClass = *pvc.Spec.StorageClassName
+	} else if class, ok := pvc.Annotations[v1.BetaStorageClassAnnotation]; ok {
+		storageClass = class
+	}
+	volumeMode := "<unset>"
+	if pvc.Spec.VolumeMode != nil {
+		volumeMode = string(*pvc.Spec.VolumeMode)
+	}
+	return []interface{}{pvc.Name, phase, pvc.Spec.VolumeName, storage, modes, storageClass,
+		translateTimestampSince(pvc.CreationTimestamp), volumeMode}, true
+}
+
+// accessModes returns access modes abbreviated the same as kubectl, e.g. RWO,ROX
+func accessModes(modes []v1.PersistentVolumeAccessMode) string {
+	var abbreviations []string
+	for _, mode := range []struct {
+		mode         v1.PersistentVolumeAccessMode
+		abbreviation string
+	}{
+		{v1.ReadWriteOnce, "RWO"},
+		{v1.ReadOnlyMany, "ROX"},
+		{v1.ReadWriteMany, "RWX"},
+		{v1.ReadWriteOncePod, "RWOP"},
+	} {
+		for _, m := range modes {
+			if m == mode.mode {
+				abbreviations = append(abbreviations, mode.abbreviation)
+				break
+			}
+		}
+	}
+	return strings.Join(abbreviations, ",")
+}
+
+func printStorageClass(obj runtime.Object) ([]interface{}, bool) {
+	storageClass, ok := obj.(*storagev1.StorageClass)
+	if !ok {
+		return nil, false
+	}
+	name := storageClass.Name
+	if storageClass.Annotations["storageclass.kubernetes.io/is-default-class"] == "true" ||
+		storageClass.Annotations["storageclass.beta.kubernetes.io/is-default-class"] == "true" {
+		name += " (default)"
+	}
+	reclaimPolicy := string(v1.PersistentVolumeReclaimDelete)
+	if storageClass.ReclaimPolicy != nil {
+		reclaimPolicy = string(*storageClass.ReclaimPolicy)
+	}
+	volumeBindingMode := string(storagev1.VolumeBindingImmediate)
+	if storageClass.VolumeBindingMode != nil {
+		volumeBindingMode = string(*storageClass.VolumeBindingMode)
+	}
+	allowVolumeExpansion := storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion
+	return []interface{}{name, storageClass.Provisioner, reclaimPolicy, volumeBindingMode, strconv.FormatBool(allowVolumeExpansion),
+		translateTimestampSince(storageClass.CreationTimestamp)}, true
+}
+
+func printRole(obj runtime.Object) ([]interface{}, bool) {
+	role, ok := obj.(*rbacv1.Role)
+	if !ok {
+		return nil, false
+	}
+	return []interface{}{role.Name, role.CreationTimestamp.UTC().Format(time.RFC3339)}, true
+}
+
+func printClusterRole(obj runtime.Object) ([]interface{}, bool) {
+	clusterRole, ok := obj.(*rbacv1.ClusterRole)
+	if !ok {
+		return nil, false
+	}
+	return []interface{}{clusterRole.Name, clusterRole.CreationTimestamp.UTC().Format(time.RFC3339)}, true
+}
+
+func printRoleBinding(obj runtime.Object) ([]interface{}, bool) {
+	roleBinding, ok := obj.(*rbacv1.RoleBinding)
+	if !ok {
+		return nil, false
+	}
+	users, groups, serviceAccounts := subjects(roleBinding.Subjects)
+	return []interface{}{roleBinding.Name, roleBinding.RoleRef.Kind + "/" + roleBinding.RoleRef.Name,
+		translateTimestampSince(roleBinding.CreationTimestamp), users, groups, serviceAccounts}, true
+}
+
+func printClusterRoleBinding(obj runtime.Object) ([]interface{}, bool) {
+	clusterRoleBinding, ok := obj.(*rbacv1.ClusterRoleBinding)
+	if !ok {
+		return nil, false
+	}
+	users, groups, serviceAccounts := subjects(clusterRoleBinding.Subjects)
+	return []interface{}{clusterRoleBinding.Name, clusterRoleBinding.RoleRef.Kind + "/" + clusterRoleBinding.RoleRef.Name,
+		translateTimestampSince(clusterRoleBinding.CreationTimestamp), users, groups, serviceAccounts}, true
+}
+
+func subjects(subjects []rbacv1.Subject) (string, string, string) {
+	var users, groups, serviceAccounts []string
+	for _, subject := range subjects {
+		switch subject.Kind {
+		case rbacv1.ServiceAccountKind:
+			serviceAccounts = append(serviceAccounts, subject.Namespace+"/"+subject.Name)
+		case rbacv1.UserKind:
+			users = append(users, subject.Name)
+		case rbacv1.GroupKind:
+			groups = append(groups, subject.Name)
+		}
+	}
+	return strings.Join(users, ", "), strings.Join(groups, ", "), strings.Join(serviceAccounts, ", ")
+}
+
+func printNetworkPolicy(obj runtime.Object) ([]interface{}, bool) {
+	networkPolicy, ok := obj.(*networkingv1.NetworkPolicy)
+	if !ok {
+		return nil, false
+	}
+	return []interface{}{networkPolicy.Name, formatSelector(&networkPolicy.Spec.PodSelector),
+		translateTimestampSince(networkPolicy.CreationTimestamp)}, true
+}
+
+func layoutContainerCells(containers []v1.Container) (string, string) {
+	names := make([]string, len(containers))
+	images := make([]string, len(containers))
+	for i, container := range containers {
+		names[i] = container.Name
+		images[i] = container.Image
+	}
+	return strings.Join(names, ","), strings.Join(images, ",")
+}
+
+func formatSelector(selector *metav1.LabelSelector) string {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil || s.Empty() {
+		return "<none>"
+	}
+	return s.String()
+}
+
+// labels returns key=value pairs sorted by keys, or <none> if empty
+func labels(m map[string]string) string {
+	if len(m) == 0 {
+		return "<none>"
+	}
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,173 @@
+package printers
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+)
+
+// printFunc returns cells of obj, false is returned if obj is not the type of the resource
+type printFunc func(obj runtime.Object) ([]interface{}, bool)
+
+// tableHandler prints objects of a resource
+type tableHandler struct {
+	columns []metav1.TableColumnDefinition
+	print   printFunc
+}
+
+var objectMetaDescriptions = metav1.ObjectMeta{}.SwaggerDoc()
+
+var (
+	nameColumn    = metav1.TableColumnDefinition{Name: "Name", Type: "string", Format: "name", Description: objectMetaDescriptions["name"]}
+	ageColumn     = metav1.TableColumnDefinition{Name: "Age", Type: "string", Description: objectMetaDescriptions["creationTimestamp"]}
+	clusterColumn = metav1.TableColumnDefinition{Name: "Cluster", Type: "string", Description: "Member cluster the object is listed from, prefixed with its region"}
+)
+
+// defaultHandler prints any object by its name and age, the same as kube-apiserver for resources without printers
+var defaultHandler = &tableHandler{
+	columns: []metav1.TableColumnDefinition{nameColumn, ageColumn},
+	print: func(obj runtime.Object) ([]interface{}, bool) {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, false
+		}
+		return []interface{}{o.GetName(), translateTimestampSince(o.GetCreationTimestamp())}, true
+	},
+}
+
+// IsTableRequest returns true if accept of a request asks for metav1.Table, e.g. application/json;as=Table;g=meta.k8s.io;v=v1
+func IsTableRequest(accept string) bool {
+	for _, mediaType := range strings.Split(accept, ",") {
+		mimeType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaType))
+		if err != nil || mimeType != "application/json" {
+			continue
+		}
+		if params["as"] == "Table" && params["g"] == metav1.GroupName && params["v"] == "v1" {
+			return true
+		}
+	}
+	return false
+}
+
+// Table converts items of a list of resource to a table, columns are the same as kubectl printers.
+// resource is a resource, resource.group or resource.version.group the same as ResourceProcessor.
+// A Cluster column is added after names if withCluster, for objects aggregated from member clusters.
+// Objects of resources without printers, or of unexpected types, are printed by names and ages
+func Table(resource string, items []interface{}, withCluster bool) (*metav1.Table, error) {
+	handler, ok := handlerFor(resource)
+	if !ok {
+		handler = defaultHandler
+	}
+
+	objects := make([]runtime.Object, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(runtime.Object)
+		if !ok {
+			return nil, fmt.Errorf("unexpected item %T of %s", item, resource)
+		}
+		objects = append(objects, obj)
+	}
+
+	rows, err := printRows(handler, objects, withCluster)
+	if err != nil && handler != defaultHandler {
+		handler = defaultHandler
+		rows, err = printRows(handler, objects, withCluster)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := handler.columns
+	if withCluster {
+		columns = append([]metav1.TableColumnDefinition{columns[0], clusterColumn}, columns[1:]...)
+	}
+	return &metav1.Table{
+		TypeMeta:          metav1.TypeMeta{APIVersion: metav1.SchemeGroupVersion.String(), Kind: "Table"},
+		ColumnDefinitions: columns,
+		Rows:              rows,
+	}, nil
+}
+
+// handlerFor returns the handler of resource, resources without groups are built-in resources of
+// the name, the same as typed providers of ResourceProcessor
+func handlerFor(resource string) (*tableHandler, bool) {
+	fullySpecified, groupResource := schema.ParseResourceArg(resource)
+	if fullySpecified != nil {
+		if handler, ok := handlers[fullySpecified.GroupResource()]; ok {
+			return handler, true
+		}
+	}
+	if handler, ok := handlers[groupResource]; ok {
+		return handler, true
+	}
+	if len(groupResource.Group) == 0 {
+		for gr, handler := range handlers {
+			if gr.Resource == groupResource.Resource {
+				return handler, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func printRows(handler *tableHandler, objects []runtime.Object, withCluster bool) ([]metav1.TableRow, error) {
+	rows := make([]metav1.TableRow, 0, len(objects))
+	for _, obj := range objects {
+		cells, ok := handler.print(obj)
+		if !ok {
+			return nil, fmt.Errorf("unexpected object %T", obj)
+		}
+		row := metav1.TableRow{Cells: cells}
+		if o, err := meta.Accessor(obj); err == nil {
+			if withCluster {
+				row.Cells = append([]interface{}{cells[0], clusterName(o)}, cells[1:]...)
+			}
+			// rows carry metadata of objects, the same as kube-apiserver by default
+			row.Object.Object = &metav1.PartialObjectMetadata{
+				TypeMeta:   metav1.TypeMeta{APIVersion: metav1.SchemeGroupVersion.String(), Kind: "PartialObjectMetadata"},
+				ObjectMeta: objectMeta(o),
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func clusterName(o metav1.Object) string {
+	annotations := o.GetAnnotations()
+	if region := annotations[alpha1.AnnotationRegion]; len(region) != 0 {
+		return region + "/" + annotations[alpha1.AnnotationCluster]
+	}
+	return annotations[alpha1.AnnotationCluster]
+}
+
+func objectMeta(o metav1.Object) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:              o.GetName(),
+		Namespace:         o.GetNamespace(),
+		UID:               o.GetUID(),
+		ResourceVersion:   o.GetResourceVersion(),
+		CreationTimestamp: o.GetCreationTimestamp(),
+		DeletionTimestamp: o.GetDeletionTimestamp(),
+		Labels:            o.GetLabels(),
+		Annotations:       o.GetAnnotations(),
+		OwnerReferences:   o.GetOwnerReferences(),
+	}
+}
+
+// translateTimestampSince returns the elapsed time since timestamp in human-readable approximation
+func translateTimestampSince(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(timestamp.Time))
+}
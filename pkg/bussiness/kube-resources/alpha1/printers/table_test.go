@@ -0,0 +1,89 @@
+package printers
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+)
+
+func TestIsTableRequest(t *testing.T) {
+	for accept, expected := range map[string]bool{
+		"application/json;as=Table;v=v1;g=meta.k8s.io,application/json;as=Table;v=v1beta1;g=meta.k8s.io,application/json": true,
+		"application/json; as=Table; g=meta.k8s.io; v=v1":                                                                 true,
+		"application/json;as=Table;g=meta.k8s.io;v=v1beta1":                                                               false,
+		"application/json": false,
+		"":                 false,
+	} {
+		if IsTableRequest(accept) != expected {
+			t.Errorf("expected %v for %q", expected, accept)
+		}
+	}
+}
+
+func TestTable(t *testing.T) {
+	replicas := int32(3)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default", CreationTimestamp: metav1.Now()},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 2, UpdatedReplicas: 3, AvailableReplicas: 2},
+	}
+	tagged := alpha1.TagObject(deployment, "r1", "c1")
+
+	table, err := Table("deployments", []interface{}{tagged}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, column := range table.ColumnDefinitions[:5] {
+		names = append(names, column.Name)
+	}
+	if len(table.ColumnDefinitions) != len(table.Rows[0].Cells) || len(names) != 5 ||
+		names[0] != "Name" || names[1] != "Cluster" || names[2] != "Ready" || names[3] != "Up-to-date" || names[4] != "Available" {
+		t.Fatalf("unexpected columns %v", table.ColumnDefinitions)
+	}
+	cells := table.Rows[0].Cells
+	if cells[0] != "nginx" || cells[1] != "r1/c1" || cells[2] != "2/3" || cells[3] != int64(3) || cells[4] != int64(2) {
+		t.Errorf("unexpected cells %v", cells)
+	}
+	if metadata, ok := table.Rows[0].Object.Object.(*metav1.PartialObjectMetadata); !ok || metadata.Name != "nginx" {
+		t.Errorf("expected metadata of object in row, got %v", table.Rows[0].Object)
+	}
+
+	// resources with groups are printed the same
+	for _, resource := range []string{"deployments.apps", "deployments.v1.apps"} {
+		table, err = Table(resource, []interface{}{deployment}, false)
+		if err != nil || len(table.ColumnDefinitions) != 8 || table.ColumnDefinitions[1].Name != "Ready" {
+			t.Errorf("expected %s printed as deployments, got %v, %v", resource, table, err)
+		}
+	}
+
+	// resources without printers are printed by names and ages, including custom resources of the same names
+	for _, resource := range []string{"unknown", "deployments.example.com"} {
+		table, err = Table(resource, []interface{}{deployment}, false)
+		if err != nil || len(table.ColumnDefinitions) != 2 || table.Rows[0].Cells[0] != "nginx" {
+			t.Errorf("unexpected default table of %s %v, %v", resource, table, err)
+		}
+	}
+}
+
+func TestPrintPod(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+		Spec:       v1.PodSpec{NodeName: "n1", Containers: []v1.Container{{Name: "app"}, {Name: "sidecar"}}},
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+			ContainerStatuses: []v1.ContainerStatus{
+				{Ready: true, RestartCount: 1, State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+				{RestartCount: 2, State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+			},
+		},
+	}
+	cells, ok := printPod(pod)
+	if !ok || cells[1] != "1/2" || cells[2] != "CrashLoopBackOff" || cells[3] != "3" || cells[6] != "n1" {
+		t.Errorf("unexpected cells %v", cells)
+	}
+}
@@ -0,0 +1,120 @@
+package alpha1
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/unify/query"
+)
+
+// lastAppliedConfigAnnotation is the annotation kubectl apply saves the whole object in
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// ProjectFunc returns the TransformFunc applied to objects returned by q. managedFields and
+// last-applied-configuration annotation are stripped unless q.ShowManagedFields, and objects are
+// projected to q.Fields if any. Projected objects are unstructured, so it is applied after objects
+// are sorted. Objects are copied before changed, since they may be from informer caches
+func ProjectFunc(q *query.QueryInfo) TransformFunc {
+	return func(obj runtime.Object) runtime.Object {
+		if !q.ShowManagedFields {
+			obj = stripManagedFields(obj)
+		}
+		if len(q.Fields) != 0 {
+			obj = project(obj, q.Fields)
+		}
+		return obj
+	}
+}
+
+func stripManagedFields(obj runtime.Object) runtime.Object {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return obj
+	}
+	_, lastApplied := o.GetAnnotations()[lastAppliedConfigAnnotation]
+	if len(o.GetManagedFields()) == 0 && !lastApplied {
+		return obj
+	}
+
+	obj = obj.DeepCopyObject()
+	o, _ = meta.Accessor(obj)
+	o.SetManagedFields(nil)
+	if lastApplied {
+		annotations := o.GetAnnotations()
+		delete(annotations, lastAppliedConfigAnnotation)
+		o.SetAnnotations(annotations)
+	}
+	return obj
+}
+
+// project returns an unstructured object of fields of obj, fields are paths separated by dots, e.g.
+// status.phase, paths in lists are applied to every item, e.g. spec.containers.image. apiVersion,
+// kind and annotations tagging member clusters are always kept
+func project(obj runtime.Object, fields []string) runtime.Object {
+	content, ok := toUnstructured(obj).(map[string]interface{})
+	if !ok {
+		return obj
+	}
+
+	projected := make(map[string]interface{})
+	for _, field := range append([]string{"apiVersion", "kind"}, fields...) {
+		copyPath(projected, content, strings.Split(field, "."))
+	}
+	result := &unstructured.Unstructured{Object: projected}
+
+	if o, err := meta.Accessor(obj); err == nil {
+		for _, key := range []string{AnnotationRegion, AnnotationCluster} {
+			if value, ok := o.GetAnnotations()[key]; ok {
+				annotations := result.GetAnnotations()
+				if annotations == nil {
+					annotations = make(map[string]string)
+				}
+				annotations[key] = value
+				result.SetAnnotations(annotations)
+			}
+		}
+	}
+	return result
+}
+
+func copyPath(dst, src map[string]interface{}, path []string) {
+	value, ok := src[path[0]]
+	if !ok || len(path[0]) == 0 {
+		return
+	}
+	if len(path) == 1 {
+		dst[path[0]] = runtime.DeepCopyJSONValue(value)
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child, _ := dst[path[0]].(map[string]interface{})
+		if child == nil {
+			child = make(map[string]interface{})
+			dst[path[0]] = child
+		}
+		copyPath(child, v, path[1:])
+	case []interface{}:
+		children, _ := dst[path[0]].([]interface{})
+		if children == nil {
+			children = make([]interface{}, len(v))
+			dst[path[0]] = children
+		}
+		for i, item := range v {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			child, _ := children[i].(map[string]interface{})
+			if child == nil {
+				child = make(map[string]interface{})
+				children[i] = child
+			}
+			copyPath(child, m, path[1:])
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package alpha1
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/unify/query"
+)
+
+func newTestPod(name string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:     "default",
+			Name:          name,
+			Annotations:   map[string]string{lastAppliedConfigAnnotation: "{}", "app": name},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "app", Image: "nginx"}, {Name: "sidecar", Image: "envoy"}},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+func TestProjectFunc(t *testing.T) {
+	pod := newTestPod("a")
+
+	q := query.New()
+	stripped := ProjectFunc(q)(pod).(*v1.Pod)
+	if len(stripped.ManagedFields) != 0 || len(stripped.Annotations) != 1 {
+		t.Errorf("expected managedFields and last-applied-configuration stripped, got %+v", stripped.ObjectMeta)
+	}
+	if len(pod.ManagedFields) == 0 || len(pod.Annotations) != 2 {
+		t.Errorf("expected object in caches not changed, got %+v", pod.ObjectMeta)
+	}
+
+	q.ShowManagedFields = true
+	if ProjectFunc(q)(pod) != runtime.Object(pod) {
+		t.Errorf("expected object returned as is")
+	}
+
+	q.ShowManagedFields = false
+	q.Fields = []string{"metadata.name", "status.phase", "spec.containers.image"}
+	projected := ProjectFunc(q)(TagObject(pod, "r1", "c1")).(*unstructured.Unstructured)
+	if projected.GetName() != "a" || len(projected.GetManagedFields()) != 0 || len(projected.GetNamespace()) != 0 {
+		t.Errorf("unexpected metadata %v", projected.Object["metadata"])
+	}
+	if projected.GetAnnotations()[AnnotationCluster] != "c1" || len(projected.GetAnnotations()) != 2 {
+		t.Errorf("expected only annotations of member cluster kept, got %v", projected.GetAnnotations())
+	}
+	if phase, _, _ := unstructured.NestedString(projected.Object, "status", "phase"); phase != "Running" {
+		t.Errorf("expected status.phase kept, got %v", projected.Object["status"])
+	}
+	containers, _, _ := unstructured.NestedSlice(projected.Object, "spec", "containers")
+	if len(containers) != 2 || len(containers[1].(map[string]interface{})) != 1 || containers[1].(map[string]interface{})["image"] != "envoy" {
+		t.Errorf("expected images of containers kept, got %v", containers)
+	}
+}
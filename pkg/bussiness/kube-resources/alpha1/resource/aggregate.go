@@ -0,0 +1,138 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	clusterv1alpha1 "captain/apis/cluster/v1alpha1"
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/bussiness/kube-resources/alpha1/generic"
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+	"captain/pkg/utils/clusterclient"
+)
+
+// aggregatedListTimeout is the timeout of listing objects from member clusters of an aggregated list,
+// clusters not responding in time are reported as failed
+const aggregatedListTimeout = 10 * time.Second
+
+var errClusterNotReady = errors.New("cluster is not ready")
+
+// clusterList is objects listed from a member cluster of an aggregated list
+type clusterList struct {
+	region  string
+	cluster string
+	objects []runtime.Object
+	err     error
+}
+
+// AggregatedList lists objects from every cluster, or every cluster of region if region is not empty,
+// concurrently. Objects are tagged with region and cluster they are listed from, then sorted and paged
+// globally. Clusters failed, not ready or timed out are reported in ClusterErrors of the result, along
+// with objects of the other clusters
+//
+// Resources without typed providers are resolved by every cluster, ErrResourceNotSupported is returned
+// only if no cluster serves it
+func (r *ResourceProcessor) AggregatedList(ctx context.Context, region, resource, namespace string, q *query.QueryInfo) (*response.ListResult, error) {
+	compareFunc := generic.Compare
+	if provider := r.TryMultiClusterResource(resource); provider != nil {
+		compareFunc = provider.Compare
+	}
+	if err := alpha1.ValidateContinue(q.Continue); err != nil {
+		return nil, err
+	}
+	if err := alpha1.ValidateJSONPath(q); err != nil {
+		return nil, err
+	}
+
+	clusters, err := r.clusterLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	// objects of clusters are merged in the order of cluster names, so that pages are stable
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].Name < clusters[j].Name
+	})
+
+	ctx, cancel := context.WithTimeout(ctx, aggregatedListTimeout)
+	defer cancel()
+
+	member := alpha1.MemberQuery(q)
+	var lists []*clusterList
+	var wg sync.WaitGroup
+	for _, cluster := range clusters {
+		clusterRegion, clusterName := clusterclient.RegionAndName(cluster)
+		if len(region) != 0 && clusterRegion != region {
+			continue
+		}
+		list := &clusterList{region: clusterRegion, cluster: clusterName}
+		lists = append(lists, list)
+		wg.Add(1)
+		go func(cluster *clusterv1alpha1.Cluster) {
+			defer wg.Done()
+			list.objects, list.err = r.listCluster(ctx, cluster, list.region, list.cluster, resource, namespace, member)
+		}(cluster)
+	}
+	wg.Wait()
+
+	var objects []alpha1.ClusterObjects
+	var clusterErrors []response.ClusterError
+	notSupported := 0
+	for _, list := range lists {
+		if list.err == ErrResourceNotSupported {
+			notSupported++
+		}
+		if list.err != nil {
+			clusterErrors = append(clusterErrors, response.ClusterError{Region: list.region, Cluster: list.cluster, Error: list.err.Error()})
+			continue
+		}
+		objects = append(objects, alpha1.ClusterObjects{Region: list.region, Cluster: list.cluster, Objects: list.objects})
+	}
+	if len(lists) != 0 && notSupported == len(lists) {
+		return nil, ErrResourceNotSupported
+	}
+	return alpha1.AggregatedList(objects, q, compareFunc, clusterErrors), nil
+}
+
+// listCluster lists objects from a member cluster, objects of the host cluster are listed from informer
+// caches the same as requests dispatched to the host cluster
+func (r *ResourceProcessor) listCluster(ctx context.Context, cluster *clusterv1alpha1.Cluster, region, name string,
+	resource, namespace string, q *query.QueryInfo) ([]runtime.Object, error) {
+	var result *response.ListResult
+	if r.clients.IsHostCluster(cluster) {
+		hostProvider, err := r.hostProvider(ctx, namespace == "", resource)
+		if err != nil {
+			return nil, err
+		}
+		result, err = hostProvider.List(namespace, q)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if !r.clients.IsClusterReady(cluster) {
+			return nil, errClusterNotReady
+		}
+		provider, err := r.memberProvider(region, name, resource, namespace)
+		if err != nil {
+			return nil, err
+		}
+		result, err = provider.List(ctx, region, name, namespace, q)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	objects := make([]runtime.Object, 0, len(result.Items))
+	for _, item := range result.Items {
+		if obj, ok := item.(runtime.Object); ok {
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
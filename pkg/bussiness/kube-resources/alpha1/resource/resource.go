@@ -1,6 +1,8 @@
 package resource
 
 import (
+	"context"
+
 	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/bussiness/kube-resources/alpha1/clusterrole"
 	"captain/pkg/bussiness/kube-resources/alpha1/clusterrolebinding"
@@ -8,6 +10,7 @@ import (
 	"captain/pkg/bussiness/kube-resources/alpha1/cronjob"
 	"captain/pkg/bussiness/kube-resources/alpha1/daemonset"
 	"captain/pkg/bussiness/kube-resources/alpha1/deployment"
+	"captain/pkg/bussiness/kube-resources/alpha1/generic"
 	"captain/pkg/bussiness/kube-resources/alpha1/ingress"
 	"captain/pkg/bussiness/kube-resources/alpha1/job"
 	"captain/pkg/bussiness/kube-resources/alpha1/namespace"
@@ -23,14 +26,22 @@ import (
 	"captain/pkg/bussiness/kube-resources/alpha1/serviceaccount"
 	"captain/pkg/bussiness/kube-resources/alpha1/statefulset"
 	"captain/pkg/bussiness/kube-resources/alpha1/storageclass"
+	clusterlister "captain/pkg/client/listers/cluster/v1alpha1"
 	"captain/pkg/informers"
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
+	"captain/pkg/utils/clustercache"
 	"captain/pkg/utils/clusterclient"
 	"errors"
+	"sync"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 )
 
@@ -59,15 +70,56 @@ var (
 	ErrResourceNotSupported  = errors.New("resource is not supported")
 )
 
+// GroupVersionResources returns resources supported by processors, of both the host and member clusters
+func GroupVersionResources() []schema.GroupVersionResource {
+	return []schema.GroupVersionResource{
+		NamespaceGVR, NodeGVR, ClusterroleGVR, StorageclassGVR, PersistentvolumeGVR, ClusterrolebindingGVR,
+		DeploymentGVR, PodGVR, StatefulsetGVR, JobGVR, CronJobGVR, DaemonsetGVR, IngresseGVR, ServiceGVR,
+		ConfigmapGVR, PersistentvolumeClaimGVR, SecretGVR, RolebindingGVR, RoleGVR, ServiceaccountGVR, NetworkpolicieGVR,
+	}
+}
+
 // ResourceProcessor ... processing resources including kube-native, sevice mesh , others kinds of cloud-native resources
 type ResourceProcessor struct {
 	clusterResourceProcessors    map[schema.GroupVersionResource]alpha1.KubeResProvider
 	namespacedResourceProcessors map[schema.GroupVersionResource]alpha1.KubeResProvider
 
 	multiClusterResourceProcessors map[schema.GroupVersionResource]alpha1.MultiClusterKubeResProvider
+
+	// clusterLister and clients are used by aggregated lists to find member clusters
+	clusterLister clusterlister.ClusterLister
+	clients       clusterclient.ClusterClients
+
+	// hostConfig is the config of writes to the host cluster, writes are not supported if it is nil.
+	// Writes are sent on behalf of requesting users by impersonate, they are forbidden if it is nil
+	hostConfig  *rest.Config
+	impersonate ImpersonateFunc
+
+	// hostResolver and hostCache serve resources without typed providers of the host cluster,
+	// e.g. CRDs, they are nil if hostConfig is nil
+	hostResolver *generic.Resolver
+	hostCache    *clustercache.Cache
+
+	// memberCaches cache objects of member clusters, resources of member clusters are served from them
+	memberCaches *clustercache.Manager
+
+	// memberResolvers resolve resources without typed providers of member clusters, by cluster names
+	memberResolversMu sync.Mutex
+	memberResolvers   map[string]*memberResolver
+}
+
+// memberResolver is the resolver of a member cluster, it is rebuilt if the kubeconfig is changed
+type memberResolver struct {
+	kubeconfig string
+	resolver   *generic.Resolver
 }
 
-func NewResourceProcessor(factory informers.CapInformerFactory, cache cache.Cache) *ResourceProcessor {
+// NewResourceProcessor returns the processor of resources of the host and member clusters. Resources
+// without typed providers of the host cluster are resolved by discovery of hostConfig, and cached by
+// dynamic informers started on demand, which run until stopCh is closed. Objects of member clusters
+// are served from caches, which are shared with the other readers of member clusters, e.g. search
+func NewResourceProcessor(factory informers.CapInformerFactory, cache cache.Cache, clients clusterclient.ClusterClients, caches *clustercache.Manager,
+	hostConfig *rest.Config, impersonate ImpersonateFunc, stopCh <-chan struct{}) *ResourceProcessor {
 	namespacedResourceProcessors := make(map[schema.GroupVersionResource]alpha1.KubeResProvider)
 	clusterResourceProcessors := make(map[schema.GroupVersionResource]alpha1.KubeResProvider)
 
@@ -97,49 +149,75 @@ func NewResourceProcessor(factory informers.CapInformerFactory, cache cache.Cach
 
 	// multi cluster native kube resource
 	multiClusterResourceProcessors := make(map[schema.GroupVersionResource]alpha1.MultiClusterKubeResProvider)
-	clients := clusterclient.NewClusterClients(factory.CaptainSharedInformerFactory().Cluster().V1alpha1().Clusters())
-	multiClusterResourceProcessors[NamespaceGVR] = namespace.NewMCResProvider(clients)
-	multiClusterResourceProcessors[NodeGVR] = node.NewMCResProvider(clients)
-	multiClusterResourceProcessors[ClusterroleGVR] = clusterrole.NewMCResProvider(clients)
-	multiClusterResourceProcessors[StorageclassGVR] = storageclass.NewMCResProvider(clients)
-	multiClusterResourceProcessors[PersistentvolumeGVR] = persistentvolume.NewMCResProvider(clients)
-	multiClusterResourceProcessors[ClusterrolebindingGVR] = clusterrolebinding.NewMCResProvider(clients)
-
-	multiClusterResourceProcessors[DeploymentGVR] = deployment.NewMCResProvider(clients)
-	multiClusterResourceProcessors[PodGVR] = pod.NewMCResProvider(clients)
-	multiClusterResourceProcessors[StatefulsetGVR] = statefulset.NewMCResProvider(clients)
-	multiClusterResourceProcessors[JobGVR] = job.NewMCResProvider(clients)
-	multiClusterResourceProcessors[CronJobGVR] = cronjob.NewMCResProvider(clients)
-	multiClusterResourceProcessors[DaemonsetGVR] = daemonset.NewMCResProvider(clients)
-	multiClusterResourceProcessors[IngresseGVR] = ingress.NewMCResProvider(clients)
-	multiClusterResourceProcessors[ServiceGVR] = service.NewMCResProvider(clients)
-	multiClusterResourceProcessors[ConfigmapGVR] = configmap.NewMCResProvider(clients)
-	multiClusterResourceProcessors[PersistentvolumeClaimGVR] = persistentvolumeclaim.NewMCResProvider(clients)
-	multiClusterResourceProcessors[SecretGVR] = secret.NewMCResProvider(clients)
-	multiClusterResourceProcessors[RolebindingGVR] = rolebinding.NewMCResProvider(clients)
-	multiClusterResourceProcessors[RoleGVR] = role.NewMCResProvider(clients)
-	multiClusterResourceProcessors[ServiceaccountGVR] = serviceaccount.NewMCResProvider(clients)
-	multiClusterResourceProcessors[NetworkpolicieGVR] = networkpolicy.NewMCResProvider(clients)
-
-	return &ResourceProcessor{
+	multiClusterResourceProcessors[NamespaceGVR] = namespace.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[NodeGVR] = node.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[ClusterroleGVR] = clusterrole.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[StorageclassGVR] = storageclass.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[PersistentvolumeGVR] = persistentvolume.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[ClusterrolebindingGVR] = clusterrolebinding.NewMCResProvider(clients, caches)
+
+	multiClusterResourceProcessors[DeploymentGVR] = deployment.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[PodGVR] = pod.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[StatefulsetGVR] = statefulset.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[JobGVR] = job.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[CronJobGVR] = cronjob.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[DaemonsetGVR] = daemonset.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[IngresseGVR] = ingress.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[ServiceGVR] = service.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[ConfigmapGVR] = configmap.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[PersistentvolumeClaimGVR] = persistentvolumeclaim.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[SecretGVR] = secret.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[RolebindingGVR] = rolebinding.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[RoleGVR] = role.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[ServiceaccountGVR] = serviceaccount.NewMCResProvider(clients, caches)
+	multiClusterResourceProcessors[NetworkpolicieGVR] = networkpolicy.NewMCResProvider(clients, caches)
+
+	processor := &ResourceProcessor{
 		namespacedResourceProcessors:   namespacedResourceProcessors,
 		clusterResourceProcessors:      clusterResourceProcessors,
 		multiClusterResourceProcessors: multiClusterResourceProcessors,
+		clusterLister:                  factory.CaptainSharedInformerFactory().Cluster().V1alpha1().Clusters().Lister(),
+		clients:                        clients,
+		hostConfig:                     hostConfig,
+		impersonate:                    impersonate,
+		memberCaches:                   caches,
+		memberResolvers:                make(map[string]*memberResolver),
+	}
+	if hostConfig != nil {
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(hostConfig)
+		if err != nil {
+			klog.Errorf("resources without providers are not supported, %v", err)
+			return processor
+		}
+		hostCache, err := clustercache.NewCache(hostConfig)
+		if err != nil {
+			klog.Errorf("resources without providers are not supported, %v", err)
+			return processor
+		}
+		if stopCh != nil {
+			go func() {
+				<-stopCh
+				hostCache.Stop()
+			}()
+		}
+		processor.hostResolver = generic.NewResolver(discoveryClient)
+		processor.hostCache = hostCache
 	}
+	return processor
 }
 
-// TryResource will retrieve a getter with resource name, it doesn't guarantee find resource with correct group version
-// need to refactor this use schema.GroupVersionResource
+// TryResource returns the typed provider of resource, which is a resource, resource.group or
+// resource.version.group, e.g. deployments.apps. nil is returned if there is none
 func (r *ResourceProcessor) TryResource(clusterScope bool, resource string) alpha1.KubeResProvider {
 	if clusterScope {
 		for k, v := range r.clusterResourceProcessors {
-			if k.Resource == resource {
+			if matchResource(k, resource) {
 				return v
 			}
 		}
 	}
 	for k, v := range r.namespacedResourceProcessors {
-		if k.Resource == resource {
+		if matchResource(k, resource) {
 			return v
 		}
 	}
@@ -147,45 +225,180 @@ func (r *ResourceProcessor) TryResource(clusterScope bool, resource string) alph
 	return nil
 }
 
+// matchResource returns true if arg is the resource of gvr, with its group and version if specified
+func matchResource(gvr schema.GroupVersionResource, arg string) bool {
+	if gvr.Resource == arg {
+		return true
+	}
+	fullySpecified, groupResource := schema.ParseResourceArg(arg)
+	if fullySpecified != nil && *fullySpecified == gvr {
+		return true
+	}
+	return groupResource == gvr.GroupResource()
+}
+
+// hostProvider returns the provider of resource of the host cluster, resources without typed
+// providers are resolved by discovery and served by dynamic informers, which are started on demand
+func (r *ResourceProcessor) hostProvider(ctx context.Context, clusterScope bool, resource string) (alpha1.KubeResProvider, error) {
+	if provider := r.TryResource(clusterScope, resource); provider != nil {
+		return provider, nil
+	}
+	if r.hostResolver == nil {
+		return nil, ErrResourceNotSupported
+	}
+	gvr, namespaced, err := resolve(r.hostResolver, resource)
+	if err != nil {
+		return nil, err
+	}
+	// the same as typed providers, namespaced resources are listed in every namespace out of namespaces
+	if !clusterScope && !namespaced {
+		return nil, ErrResourceNotSupported
+	}
+	informer, err := r.hostCache.DynamicInformer(ctx, gvr)
+	if err != nil {
+		return nil, err
+	}
+	return generic.New(informer, namespaced), nil
+}
+
+// memberProvider returns the provider of resource of a member cluster, resources without typed
+// providers are resolved by discovery of the cluster and served by dynamic informers of its cache
+func (r *ResourceProcessor) memberProvider(region, cluster, resource, namespace string) (alpha1.MultiClusterKubeResProvider, error) {
+	if provider := r.TryMultiClusterResource(resource); provider != nil {
+		return provider, nil
+	}
+	resolver, err := r.memberResolver(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	gvr, namespaced, err := resolve(resolver, resource)
+	if err != nil {
+		return nil, err
+	}
+	if len(namespace) != 0 && !namespaced {
+		return nil, ErrResourceNotSupported
+	}
+	return generic.NewMCResProvider(r.clients, r.memberCaches, gvr, namespaced), nil
+}
+
+// memberResolver returns the resolver of a member cluster, discovery of clusters is cached by resolvers
+func (r *ResourceProcessor) memberResolver(region, cluster string) (*generic.Resolver, error) {
+	c, err := r.clients.Get(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	kubeconfig := string(c.Spec.Connection.KubeConfig)
+
+	r.memberResolversMu.Lock()
+	defer r.memberResolversMu.Unlock()
+	if resolver, ok := r.memberResolvers[c.Name]; ok && resolver.kubeconfig == kubeconfig {
+		return resolver.resolver, nil
+	}
+	config, err := r.clients.GetRESTConfig(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	resolver := &memberResolver{kubeconfig: kubeconfig, resolver: generic.NewResolver(discoveryClient)}
+	r.memberResolvers[c.Name] = resolver
+	return resolver.resolver, nil
+}
+
+// resolve resolves resource by resolver, ErrResourceNotSupported is returned if it is not served
+func resolve(resolver *generic.Resolver, resource string) (schema.GroupVersionResource, bool, error) {
+	gvr, namespaced, err := resolver.Resolve(resource)
+	if meta.IsNoMatchError(err) {
+		return gvr, false, ErrResourceNotSupported
+	}
+	return gvr, namespaced, err
+}
+
+// SupportedFields returns fields supported by field selectors of every resource, objects of member
+// clusters are selected by the same providers, which support the same fields
+func (r *ResourceProcessor) SupportedFields() map[string][]string {
+	supported := make(map[string][]string)
+	for gvr, provider := range r.clusterResourceProcessors {
+		supported[gvr.Resource] = alpha1.SupportedFields(provider.SelectableFields)
+	}
+	for gvr, provider := range r.namespacedResourceProcessors {
+		supported[gvr.Resource] = alpha1.SupportedFields(provider.SelectableFields)
+	}
+	return supported
+}
+
 func (r *ResourceProcessor) TryMultiClusterResource(resource string) alpha1.MultiClusterKubeResProvider {
 	for k, v := range r.multiClusterResourceProcessors {
-		if k.Resource == resource {
+		if matchResource(k, resource) {
 			return v
 		}
 	}
 	return nil
 }
 
-func (r *ResourceProcessor) Get(region, cluster, resource, namespace, name string) (runtime.Object, error) {
+func (r *ResourceProcessor) Get(ctx context.Context, region, cluster, resource, namespace, name string) (runtime.Object, error) {
 	if alpha1.IsHostCluster(region, cluster) {
 		clusterScope := namespace == ""
-		getter := r.TryResource(clusterScope, resource)
-		if getter == nil {
-			return nil, ErrResourceNotSupported
+		getter, err := r.hostProvider(ctx, clusterScope, resource)
+		if err != nil {
+			return nil, err
 		}
 		return getter.Get(namespace, name)
 	}
-	getter := r.TryMultiClusterResource(resource)
-	if getter == nil {
-		return nil, ErrResourceNotSupported
+	getter, err := r.memberProvider(region, cluster, resource, namespace)
+	if err != nil {
+		return nil, err
 	}
-	return getter.Get(region, cluster, namespace, name)
+	return getter.Get(ctx, region, cluster, namespace, name)
 }
 
-func (r *ResourceProcessor) List(region, cluster, resource, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+func (r *ResourceProcessor) List(ctx context.Context, region, cluster, resource, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
 	if alpha1.IsHostCluster(region, cluster) {
 		// parse cluster scope or not
 		clusterScope := namespace == ""
 
-		provider := r.TryResource(clusterScope, resource)
-		if provider == nil {
-			return nil, ErrResourceNotSupported
+		provider, err := r.hostProvider(ctx, clusterScope, resource)
+		if err != nil {
+			return nil, err
+		}
+		if err := alpha1.ValidateContinue(query.Continue); err != nil {
+			return nil, err
+		}
+		if err := alpha1.ValidateJSONPath(query); err != nil {
+			return nil, err
 		}
 		return provider.List(namespace, query)
 	}
-	provider := r.TryMultiClusterResource(resource)
-	if provider == nil {
-		return nil, ErrResourceNotSupported
+	provider, err := r.memberProvider(region, cluster, resource, namespace)
+	if err != nil {
+		return nil, err
+	}
+	// objects of member clusters are paged from caches, by continue tokens the same as the host cluster
+	if err := alpha1.ValidateContinue(query.Continue); err != nil {
+		return nil, err
+	}
+	if err := alpha1.ValidateJSONPath(query); err != nil {
+		return nil, err
+	}
+	return provider.List(ctx, region, cluster, namespace, query)
+}
+
+// Watch watches changes of objects matching query, from informers of the host cluster, or upstream
+// watches of member clusters, which are not cut off when caches of member clusters are evicted
+func (r *ResourceProcessor) Watch(ctx context.Context, region, cluster, resource, namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	if alpha1.IsHostCluster(region, cluster) {
+		clusterScope := namespace == ""
+		provider, err := r.hostProvider(ctx, clusterScope, resource)
+		if err != nil {
+			return nil, err
+		}
+		return provider.Watch(namespace, query)
+	}
+	provider, err := r.memberProvider(region, cluster, resource, namespace)
+	if err != nil {
+		return nil, err
 	}
-	return provider.List(region, cluster, namespace, query)
+	return provider.Watch(ctx, region, cluster, namespace, query)
 }
@@ -0,0 +1,23 @@
+package resource
+
+import (
+	"testing"
+)
+
+func TestMatchResource(t *testing.T) {
+	for _, c := range []struct {
+		arg      string
+		expected bool
+	}{
+		{"deployments", true},
+		{"deployments.apps", true},
+		{"deployments.v1.apps", true},
+		{"deployments.v1beta2.apps", false},
+		{"deployments.extensions", false},
+		{"deployment", false},
+	} {
+		if matched := matchResource(DeploymentGVR, c.arg); matched != c.expected {
+			t.Errorf("expected %v of %s, got %v", c.expected, c.arg, matched)
+		}
+	}
+}
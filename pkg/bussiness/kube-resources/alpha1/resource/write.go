@@ -0,0 +1,218 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+)
+
+const (
+	ParameterDryRun             = "dryRun"
+	ParameterFieldManager       = "fieldManager"
+	ParameterForce              = "force"
+	ParameterPropagationPolicy  = "propagationPolicy"
+	ParameterGracePeriodSeconds = "gracePeriodSeconds"
+)
+
+// ImpersonateFunc returns the impersonation config of writes on behalf of the user from ctx, objects
+// are written with identities of kubeconfigs if it is empty, e.g. users skipped by impersonation
+type ImpersonateFunc func(ctx context.Context) rest.ImpersonationConfig
+
+// Create creates obj in the host cluster or a member cluster. apiVersion, kind and namespace of obj
+// are set by the resource and namespace if they are empty, and must match them otherwise
+func (r *ResourceProcessor) Create(ctx context.Context, region, cluster, resource, namespace string, obj *unstructured.Unstructured, opts metav1.CreateOptions) (runtime.Object, error) {
+	client, gvr, gvk, err := r.resourceClient(ctx, region, cluster, resource, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateObject(obj, gvr, gvk, namespace, ""); err != nil {
+		return nil, err
+	}
+	return client.Create(ctx, obj, opts)
+}
+
+// Update replaces the object named name with obj, obj is validated the same as Create, and its name
+// must be name if it is set
+func (r *ResourceProcessor) Update(ctx context.Context, region, cluster, resource, namespace, name string, obj *unstructured.Unstructured, opts metav1.UpdateOptions) (runtime.Object, error) {
+	client, gvr, gvk, err := r.resourceClient(ctx, region, cluster, resource, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateObject(obj, gvr, gvk, namespace, name); err != nil {
+		return nil, err
+	}
+	return client.Update(ctx, obj, opts)
+}
+
+// Patch patches the object named name by data of patchType, JSON merge, JSON, strategic merge patches
+// and server-side apply are supported. Patches are validated by kube-apiserver
+func (r *ResourceProcessor) Patch(ctx context.Context, region, cluster, resource, namespace, name string, patchType types.PatchType, data []byte, opts metav1.PatchOptions) (runtime.Object, error) {
+	client, gvr, _, err := r.resourceClient(ctx, region, cluster, resource, namespace)
+	if err != nil {
+		return nil, err
+	}
+	switch patchType {
+	case types.JSONPatchType, types.MergePatchType, types.StrategicMergePatchType:
+	case types.ApplyPatchType:
+		// kube-apiserver requires field managers of server-side apply
+		if len(opts.FieldManager) == 0 {
+			return nil, errors.NewBadRequest("fieldManager is required by server-side apply")
+		}
+	default:
+		return nil, errors.NewGenericServerResponse(http.StatusUnsupportedMediaType, "patch", gvr.GroupResource(), name,
+			fmt.Sprintf("patch type %s is not supported", patchType), 0, false)
+	}
+	return client.Patch(ctx, name, patchType, data, opts)
+}
+
+// Delete deletes the object named name, the status returned tells the kind of the object
+func (r *ResourceProcessor) Delete(ctx context.Context, region, cluster, resource, namespace, name string, opts metav1.DeleteOptions) (*metav1.Status, error) {
+	client, _, gvk, err := r.resourceClient(ctx, region, cluster, resource, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Delete(ctx, name, opts); err != nil {
+		return nil, err
+	}
+	return &metav1.Status{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+		Status:   metav1.StatusSuccess,
+		Details:  &metav1.StatusDetails{Name: name, Group: gvk.Group, Kind: gvk.Kind},
+	}, nil
+}
+
+// resourceClient returns the client of resource in namespace of the host cluster or a member cluster,
+// with the resource and kind written. Objects of namespaced resources are written in namespaces, and
+// cluster scoped ones out of namespaces. Writes are forbidden if impersonate is nil, otherwise every
+// user allowed to write would write with the identity of captain
+func (r *ResourceProcessor) resourceClient(ctx context.Context, region, cluster, resource, namespace string) (dynamic.ResourceInterface, schema.GroupVersionResource, schema.GroupVersionKind, error) {
+	gvr, gvk, namespaced, err := r.writableResource(region, cluster, resource)
+	if err != nil {
+		return nil, gvr, gvk, err
+	}
+	if namespaced && len(namespace) == 0 {
+		return nil, gvr, gvk, errors.NewBadRequest(fmt.Sprintf("%s are namespaced, they are written in namespaces", resource))
+	}
+	if !namespaced && len(namespace) != 0 {
+		return nil, gvr, gvk, errors.NewBadRequest(fmt.Sprintf("%s are cluster scoped, they are not written in namespaces", resource))
+	}
+	if r.impersonate == nil {
+		return nil, gvr, gvk, errors.NewForbidden(gvr.GroupResource(), "", fmt.Errorf("writes are not allowed while impersonation is disabled"))
+	}
+
+	var config *rest.Config
+	if alpha1.IsHostCluster(region, cluster) {
+		if r.hostConfig == nil {
+			return nil, gvr, gvk, errors.NewMethodNotSupported(gvr.GroupResource(), "write")
+		}
+		config = rest.CopyConfig(r.hostConfig)
+	} else {
+		memberConfig, err := r.clients.GetRequestRESTConfig(region, cluster)
+		if err != nil {
+			return nil, gvr, gvk, err
+		}
+		config = memberConfig
+	}
+	config.Impersonate = r.impersonate(ctx)
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, gvr, gvk, err
+	}
+	if namespaced {
+		return client.Resource(gvr).Namespace(namespace), gvr, gvk, nil
+	}
+	return client.Resource(gvr), gvr, gvk, nil
+}
+
+// writableResource returns the resource and kind of resource in the host cluster or a member cluster,
+// and whether it is namespaced. Resources of typed providers are served by every cluster, the others,
+// e.g. CRDs, are resolved by the same resolvers as reads. ErrResourceNotSupported is returned if
+// resource is not served
+func (r *ResourceProcessor) writableResource(region, cluster, resource string) (schema.GroupVersionResource, schema.GroupVersionKind, bool, error) {
+	for gvr := range r.clusterResourceProcessors {
+		if matchResource(gvr, resource) {
+			gvk, err := kindFor(gvr)
+			return gvr, gvk, false, err
+		}
+	}
+	for gvr := range r.namespacedResourceProcessors {
+		if matchResource(gvr, resource) {
+			gvk, err := kindFor(gvr)
+			return gvr, gvk, true, err
+		}
+	}
+
+	resolver := r.hostResolver
+	if !alpha1.IsHostCluster(region, cluster) {
+		memberResolver, err := r.memberResolver(region, cluster)
+		if err != nil {
+			return schema.GroupVersionResource{}, schema.GroupVersionKind{}, false, err
+		}
+		resolver = memberResolver
+	}
+	if resolver == nil {
+		return schema.GroupVersionResource{}, schema.GroupVersionKind{}, false, ErrResourceNotSupported
+	}
+	gvr, namespaced, err := resolve(resolver, resource)
+	if err != nil {
+		return gvr, schema.GroupVersionKind{}, false, err
+	}
+	gvk, err := resolver.KindFor(gvr)
+	return gvr, gvk, namespaced, err
+}
+
+// validateObject sets apiVersion, kind and namespace of obj if they are empty, and returns a
+// BadRequest error if they do not match the resource and kind, or the name is not name if name is not empty
+func validateObject(obj *unstructured.Unstructured, gvr schema.GroupVersionResource, gvk schema.GroupVersionKind, namespace, name string) error {
+	if len(obj.GetAPIVersion()) == 0 {
+		obj.SetAPIVersion(gvk.GroupVersion().String())
+	}
+	if len(obj.GetKind()) == 0 {
+		obj.SetKind(gvk.Kind)
+	}
+	if obj.GroupVersionKind() != gvk {
+		return errors.NewBadRequest(fmt.Sprintf("%s are %s of %s, not %s of %s", gvr.Resource, gvk.Kind, gvk.GroupVersion(), obj.GetKind(), obj.GetAPIVersion()))
+	}
+
+	if len(obj.GetNamespace()) == 0 {
+		obj.SetNamespace(namespace)
+	}
+	if obj.GetNamespace() != namespace {
+		return errors.NewBadRequest(fmt.Sprintf("the namespace of the object %s does not match the namespace %s of the request", obj.GetNamespace(), namespace))
+	}
+
+	if len(name) != 0 {
+		if len(obj.GetName()) == 0 {
+			obj.SetName(name)
+		}
+		if obj.GetName() != name {
+			return errors.NewBadRequest(fmt.Sprintf("the name of the object %s does not match the name %s of the request", obj.GetName(), name))
+		}
+	}
+	return nil
+}
+
+// kindFor returns the kind of gvr, by kinds registered in the scheme of clientsets
+func kindFor(gvr schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	for kind := range scheme.Scheme.KnownTypes(gvr.GroupVersion()) {
+		gvk := gvr.GroupVersion().WithKind(kind)
+		if plural, _ := meta.UnsafeGuessKindToResource(gvk); plural == gvr {
+			return gvk, nil
+		}
+	}
+	return schema.GroupVersionKind{}, fmt.Errorf("kind of %s is not registered", gvr)
+}
@@ -0,0 +1,167 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/bussiness/kube-resources/alpha1/generic"
+)
+
+// request is a request received by the fake kube-apiserver
+type request struct {
+	method, path, contentType, user string
+	body                            map[string]interface{}
+}
+
+func newWriteProcessor(t *testing.T) (*ResourceProcessor, *[]request) {
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, _ := ioutil.ReadAll(req.Body)
+		received := request{method: req.Method, path: req.URL.Path, contentType: req.Header.Get("Content-Type"), user: req.Header.Get("Impersonate-User")}
+		json.Unmarshal(data, &received.body)
+		requests = append(requests, received)
+
+		w.Header().Set("Content-Type", "application/json")
+		if req.Method == http.MethodDelete {
+			json.NewEncoder(w).Encode(metav1.Status{Status: metav1.StatusSuccess})
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"c","namespace":"default"}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return &ResourceProcessor{
+		clusterResourceProcessors:    map[schema.GroupVersionResource]alpha1.KubeResProvider{NodeGVR: nil},
+		namespacedResourceProcessors: map[schema.GroupVersionResource]alpha1.KubeResProvider{ConfigmapGVR: nil, DeploymentGVR: nil},
+		hostConfig:                   &rest.Config{Host: server.URL},
+		hostResolver:                 newTestResolver(),
+		impersonate: func(ctx context.Context) rest.ImpersonationConfig {
+			return rest.ImpersonationConfig{UserName: "alice"}
+		},
+	}, &requests
+}
+
+// newTestResolver resolves virtualservices of istio, which have no typed providers
+func newTestResolver() *generic.Resolver {
+	client := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+	client.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "networking.istio.io/v1beta1", APIResources: []metav1.APIResource{
+			{Name: "virtualservices", Kind: "VirtualService", Namespaced: true, Verbs: []string{"get", "list", "watch", "create"}},
+		}},
+	}
+	return generic.NewResolver(client)
+}
+
+func TestCreate(t *testing.T) {
+	processor, requests := newWriteProcessor(t)
+	ctx := context.Background()
+
+	configmap := &unstructured.Unstructured{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "c"}}}
+	if _, err := processor.Create(ctx, "", "", "configmaps", "default", configmap, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("expected a request, got %v", *requests)
+	}
+	received := (*requests)[0]
+	metadata, _ := received.body["metadata"].(map[string]interface{})
+	if received.method != http.MethodPost || received.path != "/api/v1/namespaces/default/configmaps" || received.user != "alice" ||
+		received.body["kind"] != "ConfigMap" || received.body["apiVersion"] != "v1" || metadata["namespace"] != "default" {
+		t.Errorf("unexpected request %+v", received)
+	}
+
+	// objects not matching requests are rejected before they are sent
+	for _, c := range []struct {
+		resource, namespace string
+		object              map[string]interface{}
+	}{
+		{"configmaps", "default", map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment"}},
+		{"configmaps", "default", map[string]interface{}{"metadata": map[string]interface{}{"namespace": "kube-system"}}},
+		{"configmaps", "", map[string]interface{}{}},
+		{"nodes", "default", map[string]interface{}{}},
+	} {
+		_, err := processor.Create(ctx, "", "", c.resource, c.namespace, &unstructured.Unstructured{Object: c.object}, metav1.CreateOptions{})
+		if !errors.IsBadRequest(err) {
+			t.Errorf("expected BadRequest of %s in %q, got %v", c.resource, c.namespace, err)
+		}
+	}
+	if _, err := processor.Create(ctx, "", "", "widgets", "default", &unstructured.Unstructured{}, metav1.CreateOptions{}); err != ErrResourceNotSupported {
+		t.Errorf("expected unsupported resources rejected, got %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("expected invalid objects not sent, got %v", *requests)
+	}
+
+	// resources without typed providers are resolved the same as reads
+	virtualService := &unstructured.Unstructured{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "v"}}}
+	if _, err := processor.Create(ctx, "", "", "virtualservices.networking.istio.io", "default", virtualService, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if received := (*requests)[1]; received.path != "/apis/networking.istio.io/v1beta1/namespaces/default/virtualservices" ||
+		received.body["kind"] != "VirtualService" || received.body["apiVersion"] != "networking.istio.io/v1beta1" {
+		t.Errorf("unexpected request %+v", received)
+	}
+
+	processor.impersonate = nil
+	if _, err := processor.Create(ctx, "", "", "configmaps", "default", configmap, metav1.CreateOptions{}); !errors.IsForbidden(err) {
+		t.Errorf("expected writes forbidden without impersonation, got %v", err)
+	}
+}
+
+func TestUpdatePatchDelete(t *testing.T) {
+	processor, requests := newWriteProcessor(t)
+	ctx := context.Background()
+
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "other"}}}
+	if _, err := processor.Update(ctx, "", "", "deployments", "default", "web", deployment, metav1.UpdateOptions{}); !errors.IsBadRequest(err) {
+		t.Errorf("expected names not matching rejected, got %v", err)
+	}
+
+	if _, err := processor.Patch(ctx, "", "", "deployments", "default", "web", types.ApplyPatchType, []byte("{}"), metav1.PatchOptions{}); !errors.IsBadRequest(err) {
+		t.Errorf("expected server-side apply without field managers rejected, got %v", err)
+	}
+	if _, err := processor.Patch(ctx, "", "", "deployments", "default", "web", types.PatchType("text/plain"), []byte("{}"), metav1.PatchOptions{}); !errors.IsUnsupportedMediaType(err) {
+		t.Errorf("expected unknown patch types rejected, got %v", err)
+	}
+	if _, err := processor.Patch(ctx, "", "", "deployments", "default", "web", types.StrategicMergePatchType, []byte(`{"spec":{"replicas":2}}`), metav1.PatchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	status, err := processor.Delete(ctx, "", "", "nodes", "", "n1", metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Details == nil || status.Details.Kind != "Node" || status.Details.Name != "n1" {
+		t.Errorf("expected details of the node deleted, got %+v", status.Details)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("expected 2 requests, got %v", *requests)
+	}
+	if patch := (*requests)[0]; patch.method != http.MethodPatch || patch.path != "/apis/apps/v1/namespaces/default/deployments/web" ||
+		patch.contentType != string(types.StrategicMergePatchType) {
+		t.Errorf("unexpected patch %+v", patch)
+	}
+	if deletion := (*requests)[1]; deletion.method != http.MethodDelete || deletion.path != "/api/v1/nodes/n1" {
+		t.Errorf("unexpected deletion %+v", deletion)
+	}
+
+	processor.hostConfig = nil
+	if _, err := processor.Delete(ctx, "", "", "nodes", "", "n1", metav1.DeleteOptions{}); !errors.IsMethodNotSupported(err) {
+		t.Errorf("expected writes rejected without configs, got %v", err)
+	}
+}
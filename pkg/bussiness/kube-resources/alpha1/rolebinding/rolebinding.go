@@ -5,7 +5,9 @@ import (
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 )
 
@@ -37,9 +39,27 @@ func (cr rolebindingProvider) List(namespace string, query *query.QueryInfo) (*r
 		result = append(result, roleBinding)
 	}
 
+	result, err = alpha1.SelectFields(result, query, cr.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+func (cr rolebindingProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(cr.informers.Rbac().V1().RoleBindings().Informer(), namespace, query, filter, cr.SelectableFields)
+}
+
+// SelectableFields returns fields of rolebindings supported by field selectors, the same as kube-apiserver
+func (cr rolebindingProvider) SelectableFields(object runtime.Object) fields.Set {
+	role, ok := object.(*rbacv1.RoleBinding)
+	if !ok {
+		role = &rbacv1.RoleBinding{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&role.ObjectMeta, true)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	role, ok := object.(*rbacv1.RoleBinding)
 
@@ -0,0 +1,571 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+)
+
+const (
+	ParameterQuery     = "q"
+	ParameterKind      = "kind"
+	ParameterCluster   = "cluster"
+	ParameterNamespace = "namespace"
+)
+
+const (
+	// maxTermsPerDocument bounds terms indexed of an object, terms of names are indexed first, then
+	// labels and annotations
+	maxTermsPerDocument = 256
+
+	// maxTermLength is the maximum length of terms, the same as names of objects
+	maxTermLength = 253
+
+	// maxAnnotationLength is the maximum length of annotations indexed, longer ones are usually
+	// serialized objects, e.g. kubectl.kubernetes.io/last-applied-configuration
+	maxAnnotationLength = 256
+)
+
+// secrets is the resource of secrets, annotations of secrets are never indexed, since they may carry
+// data of secrets, e.g. a short kubectl.kubernetes.io/last-applied-configuration
+var secrets = schema.GroupResource{Resource: "secrets"}
+
+// field is where a term is found in an object, matches of fields are weighted differently
+type field uint8
+
+const (
+	fieldName field = 1 << iota
+	fieldNameWord
+	fieldNamespace
+	fieldLabel
+	fieldAnnotation
+)
+
+// fieldWeights are weights of fields in the descending order
+var fieldWeights = []struct {
+	field  field
+	weight float64
+}{
+	{fieldName, 10},
+	{fieldNameWord, 8},
+	{fieldLabel, 5},
+	{fieldNamespace, 3},
+	{fieldAnnotation, 1},
+}
+
+// weights of terms matching words of queries exactly, by prefix and by substring
+const (
+	exactWeight     = 1
+	prefixWeight    = 0.6
+	substringWeight = 0.3
+)
+
+// Query searches objects by words of Text, objects matching every word are returned, all objects
+// are matched if Text is empty
+type Query struct {
+	Text string
+
+	// Kind selects objects of the kind or resource, e.g. Deployment or deployments
+	Kind string
+
+	// Cluster selects objects of the cluster, e.g. region/cluster, or cluster if it is not in a region
+	Cluster string
+
+	Namespace string
+
+	// Authorized returns whether objects of resource in namespace of a cluster may be listed, region
+	// and cluster are empty for the host cluster, the same as requests to it. Objects not authorized
+	// are neither returned nor counted in facets. All objects are if it is nil
+	Authorized func(region, cluster, namespace string, resource schema.GroupResource) bool
+
+	Pagination *query.Pagination
+}
+
+// Hit is an object matching a query
+type Hit struct {
+	Region            string            `json:"region,omitempty"`
+	Cluster           string            `json:"cluster"`
+	Kind              string            `json:"kind"`
+	APIVersion        string            `json:"apiVersion"`
+	Resource          string            `json:"resource"`
+	Namespace         string            `json:"namespace,omitempty"`
+	Name              string            `json:"name"`
+	UID               types.UID         `json:"uid"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	CreationTimestamp metav1.Time       `json:"creationTimestamp"`
+
+	// Score is the relevance of the object, hits are sorted by it
+	Score float64 `json:"score"`
+}
+
+// FacetValue is the number of hits of a kind or cluster
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Facets are numbers of hits by kinds and clusters. Numbers of kinds are counted regardless of
+// Query.Kind, and numbers of clusters regardless of Query.Cluster, so that they can be switched
+type Facets struct {
+	Kinds    []FacetValue `json:"kinds"`
+	Clusters []FacetValue `json:"clusters"`
+}
+
+// Result is a page of hits, clusters failed to be indexed are reported in ClusterErrors
+type Result struct {
+	response.ListResult
+
+	Facets Facets `json:"facets"`
+
+	// Incomplete is true if objects are not indexed since the index is full
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+// clusterRef is a cluster objects are indexed from, objects are tagged with its current region and name
+type clusterRef struct {
+	id     string
+	region string
+	name   string
+	err    error
+}
+
+func (c *clusterRef) String() string {
+	if len(c.region) != 0 {
+		return c.region + "/" + c.name
+	}
+	return c.name
+}
+
+type docKey struct {
+	cluster   string
+	resource  string
+	namespace string
+	name      string
+}
+
+type document struct {
+	key               docKey
+	cluster           *clusterRef
+	kind              string
+	group             string
+	apiVersion        string
+	uid               types.UID
+	resourceVersion   string
+	labels            map[string]string
+	creationTimestamp metav1.Time
+
+	// terms are terms of the document and fields they are found in
+	terms map[string]field
+}
+
+// Index is an inverted index of metadata of objects, from terms of names, namespaces, labels and
+// annotations to objects. Objects are not kept, so that memory is bounded by the number of objects
+type Index struct {
+	lock sync.RWMutex
+
+	maxDocuments int
+	clusters     map[string]*clusterRef
+	documents    map[docKey]*document
+	postings     map[string]map[*document]field
+	terms        *termSet
+
+	// dropped are objects not indexed since the index is full, they are indexed once they are
+	// updated with room in the index. overflowed is set if there are more than maxDocuments of them
+	dropped    map[docKey]struct{}
+	overflowed bool
+}
+
+func NewIndex(maxDocuments int) *Index {
+	return &Index{
+		maxDocuments: maxDocuments,
+		clusters:     make(map[string]*clusterRef),
+		documents:    make(map[docKey]*document),
+		postings:     make(map[string]map[*document]field),
+		terms:        newTermSet(),
+		dropped:      make(map[docKey]struct{}),
+	}
+}
+
+// AddCluster starts indexing objects of cluster id, objects indexed of it before are removed. Objects
+// are indexed by the returned ref only, until the cluster is added again or deleted
+func (idx *Index) AddCluster(id, region, name string) *clusterRef {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	idx.deleteCluster(id)
+	ref := &clusterRef{id: id, region: region, name: name}
+	idx.clusters[id] = ref
+	return ref
+}
+
+// RenameCluster changes region and name objects of cluster id are tagged with
+func (idx *Index) RenameCluster(id, region, name string) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	if ref, ok := idx.clusters[id]; ok {
+		ref.region, ref.name = region, name
+	}
+}
+
+// SetClusterError records the error of indexing objects of cluster, nil clears it
+func (idx *Index) SetClusterError(ref *clusterRef, err error) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	ref.err = err
+}
+
+// DeleteCluster removes objects of cluster id
+func (idx *Index) DeleteCluster(id string) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	idx.deleteCluster(id)
+}
+
+func (idx *Index) deleteCluster(id string) {
+	if _, ok := idx.clusters[id]; !ok {
+		return
+	}
+	delete(idx.clusters, id)
+	for key, doc := range idx.documents {
+		if key.cluster == id {
+			idx.remove(doc)
+		}
+	}
+	for key := range idx.dropped {
+		if key.cluster == id {
+			delete(idx.dropped, key)
+		}
+	}
+}
+
+// Upsert indexes obj of resource gvr from cluster, or re-indexes it if it is changed
+func (idx *Index) Upsert(ref *clusterRef, gvr schema.GroupVersionResource, kind string, obj metav1.Object) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	if idx.clusters[ref.id] != ref {
+		return
+	}
+	key := docKey{cluster: ref.id, resource: gvr.Resource, namespace: obj.GetNamespace(), name: obj.GetName()}
+	if doc, ok := idx.documents[key]; ok {
+		// resyncs are not changes
+		if doc.uid == obj.GetUID() && doc.resourceVersion == obj.GetResourceVersion() {
+			return
+		}
+		idx.remove(doc)
+	} else if len(idx.documents) >= idx.maxDocuments {
+		if len(idx.dropped) < idx.maxDocuments {
+			idx.dropped[key] = struct{}{}
+		} else {
+			idx.overflowed = true
+		}
+		return
+	}
+	delete(idx.dropped, key)
+
+	doc := &document{
+		key:               key,
+		cluster:           ref,
+		kind:              kind,
+		group:             gvr.Group,
+		apiVersion:        gvr.GroupVersion().String(),
+		uid:               obj.GetUID(),
+		resourceVersion:   obj.GetResourceVersion(),
+		labels:            obj.GetLabels(),
+		creationTimestamp: obj.GetCreationTimestamp(),
+		terms:             documentTerms(gvr, obj),
+	}
+	idx.documents[key] = doc
+	for term, fields := range doc.terms {
+		posting, ok := idx.postings[term]
+		if !ok {
+			posting = make(map[*document]field)
+			idx.postings[term] = posting
+			idx.terms.add(term)
+		}
+		posting[doc] = fields
+	}
+}
+
+// Delete removes obj of resource gvr from cluster
+func (idx *Index) Delete(ref *clusterRef, gvr schema.GroupVersionResource, obj metav1.Object) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	if idx.clusters[ref.id] != ref {
+		return
+	}
+	key := docKey{cluster: ref.id, resource: gvr.Resource, namespace: obj.GetNamespace(), name: obj.GetName()}
+	delete(idx.dropped, key)
+	if doc, ok := idx.documents[key]; ok {
+		idx.remove(doc)
+	}
+}
+
+func (idx *Index) remove(doc *document) {
+	delete(idx.documents, doc.key)
+	for term := range doc.terms {
+		posting := idx.postings[term]
+		delete(posting, doc)
+		if len(posting) == 0 {
+			delete(idx.postings, term)
+			idx.terms.delete(term)
+		}
+	}
+}
+
+// Search returns hits of q sorted by relevance, then by clusters, kinds, namespaces and names
+func (idx *Index) Search(q *Query) *Result {
+	idx.lock.RLock()
+	defer idx.lock.RUnlock()
+
+	scores := idx.match(strings.Fields(strings.ToLower(q.Text)))
+
+	kinds := make(map[string]int)
+	clusters := make(map[string]int)
+	var hits []*Hit
+	for doc, score := range scores {
+		if len(q.Namespace) != 0 && doc.key.namespace != q.Namespace {
+			continue
+		}
+		if q.Authorized != nil && !doc.authorized(q.Authorized) {
+			continue
+		}
+		kindMatched := len(q.Kind) == 0 || strings.EqualFold(doc.kind, q.Kind) || doc.key.resource == q.Kind
+		clusterMatched := len(q.Cluster) == 0 || doc.cluster.String() == q.Cluster || doc.cluster.name == q.Cluster
+		if clusterMatched {
+			kinds[doc.kind]++
+		}
+		if kindMatched {
+			clusters[doc.cluster.String()]++
+		}
+		if kindMatched && clusterMatched {
+			hits = append(hits, doc.hit(score))
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		left, right := hits[i], hits[j]
+		switch {
+		case left.Score != right.Score:
+			return left.Score > right.Score
+		case left.Region != right.Region:
+			return left.Region < right.Region
+		case left.Cluster != right.Cluster:
+			return left.Cluster < right.Cluster
+		case left.Kind != right.Kind:
+			return left.Kind < right.Kind
+		case left.Namespace != right.Namespace:
+			return left.Namespace < right.Namespace
+		default:
+			return left.Name < right.Name
+		}
+	})
+
+	pagination := q.Pagination
+	if pagination == nil {
+		pagination = query.DefaultPagination
+	}
+	total := len(hits)
+	begin, end := pagination.GetValidPagination(total)
+	items := make([]interface{}, 0, end-begin)
+	for _, hit := range hits[begin:end] {
+		items = append(items, hit)
+	}
+
+	result := &Result{
+		ListResult: response.ListResult{
+			Items:       items,
+			Total:       total,
+			CurrentPage: pagination.Page,
+			PageSize:    pagination.PageSize,
+			TotalPages:  int(math.Ceil(float64(total) / float64(pagination.PageSize))),
+		},
+		Facets:     Facets{Kinds: facetValues(kinds), Clusters: facetValues(clusters)},
+		Incomplete: len(idx.dropped) != 0 || idx.overflowed,
+	}
+	for _, ref := range idx.clusters {
+		if ref.err != nil {
+			result.ClusterErrors = append(result.ClusterErrors, response.ClusterError{Region: ref.region, Cluster: ref.name, Error: ref.err.Error()})
+		}
+	}
+	sort.Slice(result.ClusterErrors, func(i, j int) bool {
+		return result.ClusterErrors[i].Region+"/"+result.ClusterErrors[i].Cluster < result.ClusterErrors[j].Region+"/"+result.ClusterErrors[j].Cluster
+	})
+	return result
+}
+
+// match returns scores of documents matching every word, a word matches terms equal to it, prefixed
+// with it or containing it, and the best weighted match of every word is summed up. Words shorter
+// than trigramLength do not match terms by substrings
+func (idx *Index) match(words []string) map[*document]float64 {
+	if len(words) == 0 {
+		scores := make(map[*document]float64, len(idx.documents))
+		for _, doc := range idx.documents {
+			scores[doc] = 0
+		}
+		return scores
+	}
+
+	var scores map[*document]float64
+	for _, word := range words {
+		weights := make(map[string]float64)
+		idx.terms.withPrefix(word, func(term string) {
+			if term == word {
+				weights[term] = exactWeight
+			} else {
+				weights[term] = prefixWeight
+			}
+		})
+		idx.terms.containing(word, func(term string) {
+			if _, ok := weights[term]; !ok {
+				weights[term] = substringWeight
+			}
+		})
+
+		best := make(map[*document]float64)
+		for term, weight := range weights {
+			for doc, fields := range idx.postings[term] {
+				if scores != nil {
+					if _, ok := scores[doc]; !ok {
+						continue
+					}
+				}
+				if score := weight * fieldWeight(fields); score > best[doc] {
+					best[doc] = score
+				}
+			}
+		}
+		for doc := range best {
+			best[doc] += scores[doc]
+		}
+		scores = best
+		if len(scores) == 0 {
+			break
+		}
+	}
+	return scores
+}
+
+func (doc *document) authorized(authorized func(region, cluster, namespace string, resource schema.GroupResource) bool) bool {
+	region, cluster := doc.cluster.region, doc.cluster.name
+	if doc.cluster.id == hostClusterID {
+		region, cluster = "", ""
+	}
+	return authorized(region, cluster, doc.key.namespace, schema.GroupResource{Group: doc.group, Resource: doc.key.resource})
+}
+
+func (doc *document) hit(score float64) *Hit {
+	return &Hit{
+		Region:            doc.cluster.region,
+		Cluster:           doc.cluster.name,
+		Kind:              doc.kind,
+		APIVersion:        doc.apiVersion,
+		Resource:          doc.key.resource,
+		Namespace:         doc.key.namespace,
+		Name:              doc.key.name,
+		UID:               doc.uid,
+		Labels:            doc.labels,
+		CreationTimestamp: doc.creationTimestamp,
+		Score:             score,
+	}
+}
+
+func fieldWeight(fields field) float64 {
+	for _, w := range fieldWeights {
+		if fields&w.field != 0 {
+			return w.weight
+		}
+	}
+	return 0
+}
+
+// documentTerms returns terms of names, namespaces, labels and annotations of obj, names and values
+// are indexed as a whole and by words, e.g. nginx-7d9f, nginx and 7d9f. Labels are also indexed by
+// key=value
+func documentTerms(gvr schema.GroupVersionResource, obj metav1.Object) map[string]field {
+	terms := make(map[string]field)
+	add := func(f field, value string) {
+		value = strings.ToLower(value)
+		if len(value) == 0 || len(value) > maxTermLength {
+			return
+		}
+		if _, ok := terms[value]; !ok && len(terms) >= maxTermsPerDocument {
+			return
+		}
+		terms[value] |= f
+	}
+	addWords := func(f, wordField field, value string) {
+		add(f, value)
+		for _, word := range strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			add(wordField, word)
+		}
+	}
+
+	// whole names are weighted over words of names
+	addWords(fieldName, fieldNameWord, obj.GetName())
+	add(fieldNamespace, obj.GetNamespace())
+	labels := obj.GetLabels()
+	for _, key := range sortedKeys(labels) {
+		add(fieldLabel, key)
+		addWords(fieldLabel, fieldLabel, labels[key])
+		add(fieldLabel, key+"="+labels[key])
+	}
+	annotations := indexedAnnotations(gvr, obj.GetAnnotations())
+	for _, key := range sortedKeys(annotations) {
+		addWords(fieldAnnotation, fieldAnnotation, annotations[key])
+	}
+	return terms
+}
+
+// indexedAnnotations returns annotations of an object of gvr which are indexed, serialized objects
+// and annotations longer than maxAnnotationLength are not, neither are annotations of secrets
+func indexedAnnotations(gvr schema.GroupVersionResource, annotations map[string]string) map[string]string {
+	if gvr.GroupResource() == secrets {
+		return nil
+	}
+	indexed := make(map[string]string, len(annotations))
+	for key, value := range annotations {
+		if key != corev1.LastAppliedConfigAnnotation && len(value) <= maxAnnotationLength {
+			indexed[key] = value
+		}
+	}
+	return indexed
+}
+
+func facetValues(counts map[string]int) []FacetValue {
+	values := make([]FacetValue, 0, len(counts))
+	for value, count := range counts {
+		values = append(values, FacetValue{Value: value, Count: count})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	return values
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,145 @@
+package search
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"captain/pkg/unify/query"
+)
+
+var (
+	deployments = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	configmaps  = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+)
+
+func newObject(namespace, name, resourceVersion string, labels map[string]string) *metav1.ObjectMeta {
+	return &metav1.ObjectMeta{Namespace: namespace, Name: name, ResourceVersion: resourceVersion, Labels: labels}
+}
+
+func hitNames(result *Result) []string {
+	var names []string
+	for _, item := range result.Items {
+		hit := item.(*Hit)
+		names = append(names, hit.Cluster+"/"+hit.Kind+"/"+hit.Name)
+	}
+	return names
+}
+
+func expectHits(t *testing.T, result *Result, expected ...string) {
+	t.Helper()
+	names := hitNames(result)
+	if len(names) != len(expected) {
+		t.Fatalf("expected hits %v, got %v", expected, names)
+	}
+	for i := range names {
+		if names[i] != expected[i] {
+			t.Fatalf("expected hits %v, got %v", expected, names)
+		}
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	idx := NewIndex(100)
+	host := idx.AddCluster(hostClusterID, "", "host")
+	member := idx.AddCluster("r1-c1", "r1", "c1")
+
+	idx.Upsert(host, deployments, "Deployment", newObject("default", "nginx", "1", map[string]string{"app": "web"}))
+	idx.Upsert(host, configmaps, "ConfigMap", newObject("default", "nginx-conf", "2", nil))
+	idx.Upsert(member, deployments, "Deployment", newObject("default", "my-nginx-proxy", "1", nil))
+	idx.Upsert(member, deployments, "Deployment", newObject("default", "redis", "2", map[string]string{"app": "web", "tier": "cache"}))
+
+	// whole names are ranked before words of names and prefixes, the rest are sorted by clusters and kinds
+	expectHits(t, idx.Search(&Query{Text: "nginx"}), "host/Deployment/nginx", "host/ConfigMap/nginx-conf", "c1/Deployment/my-nginx-proxy")
+	expectHits(t, idx.Search(&Query{Text: "ngi"}), "host/ConfigMap/nginx-conf", "host/Deployment/nginx", "c1/Deployment/my-nginx-proxy")
+
+	// words match terms by substrings, unless they are shorter than trigrams
+	expectHits(t, idx.Search(&Query{Text: "ginx"}), "host/ConfigMap/nginx-conf", "host/Deployment/nginx", "c1/Deployment/my-nginx-proxy")
+	expectHits(t, idx.Search(&Query{Text: "gi"}))
+
+	// every word is matched, labels are matched by key=value
+	expectHits(t, idx.Search(&Query{Text: "app=web CACHE"}), "c1/Deployment/redis")
+
+	result := idx.Search(&Query{Text: "nginx", Kind: "deployments", Cluster: "r1/c1"})
+	expectHits(t, result, "c1/Deployment/my-nginx-proxy")
+	if kinds := result.Facets.Kinds; len(kinds) != 1 || kinds[0] != (FacetValue{Value: "Deployment", Count: 1}) {
+		t.Errorf("unexpected kind facets %v", kinds)
+	}
+	if clusters := result.Facets.Clusters; len(clusters) != 2 || clusters[0] != (FacetValue{Value: "host", Count: 1}) || clusters[1].Value != "r1/c1" {
+		t.Errorf("expected clusters counted regardless of cluster selected, got %v", clusters)
+	}
+
+	// objects not authorized are neither returned nor counted
+	result = idx.Search(&Query{Text: "nginx", Authorized: func(region, cluster, namespace string, resource schema.GroupResource) bool {
+		return cluster == "" && resource.Group == "apps"
+	}})
+	expectHits(t, result, "host/Deployment/nginx")
+	if clusters := result.Facets.Clusters; len(clusters) != 1 || clusters[0] != (FacetValue{Value: "host", Count: 1}) {
+		t.Errorf("expected objects not authorized not counted, got %v", clusters)
+	}
+
+	result = idx.Search(&Query{Pagination: &query.Pagination{Page: 2, PageSize: 3}})
+	if result.Total != 4 || result.TotalPages != 2 || len(result.Items) != 1 {
+		t.Errorf("unexpected page %+v", result.ListResult)
+	}
+
+	// terms of old versions are removed on updates
+	idx.Upsert(host, deployments, "Deployment", newObject("default", "nginx", "3", map[string]string{"app": "api"}))
+	expectHits(t, idx.Search(&Query{Text: "app=web"}), "c1/Deployment/redis")
+	expectHits(t, idx.Search(&Query{Text: "app=api"}), "host/Deployment/nginx")
+
+	idx.Delete(host, configmaps, newObject("default", "nginx-conf", "", nil))
+	idx.DeleteCluster("r1-c1")
+	expectHits(t, idx.Search(&Query{Text: "nginx"}), "host/Deployment/nginx")
+	if len(idx.postings["redis"]) != 0 || len(idx.documents) != 1 || len(idx.terms.trigrams["red"]) != 0 {
+		t.Errorf("expected terms of deleted objects removed")
+	}
+
+	// objects of a cluster added again are indexed by the new ref only
+	stale := member
+	member = idx.AddCluster("r1-c1", "r1", "c1")
+	idx.Upsert(stale, deployments, "Deployment", newObject("default", "redis", "2", nil))
+	expectHits(t, idx.Search(&Query{Text: "redis"}))
+}
+
+func TestIndexBounded(t *testing.T) {
+	idx := NewIndex(2)
+	host := idx.AddCluster(hostClusterID, "", "")
+	idx.Upsert(host, configmaps, "ConfigMap", newObject("default", "a", "1", nil))
+	idx.Upsert(host, configmaps, "ConfigMap", newObject("default", "b", "1", nil))
+	idx.Upsert(host, configmaps, "ConfigMap", newObject("default", "c", "1", nil))
+
+	result := idx.Search(&Query{})
+	if result.Total != 2 || !result.Incomplete {
+		t.Fatalf("expected the index bounded, got %d objects, incomplete %v", result.Total, result.Incomplete)
+	}
+
+	// dropped objects are indexed once there is room
+	idx.Delete(host, configmaps, newObject("default", "a", "", nil))
+	idx.Upsert(host, configmaps, "ConfigMap", newObject("default", "c", "1", nil))
+	result = idx.Search(&Query{})
+	if result.Total != 2 || result.Incomplete {
+		t.Errorf("expected dropped objects indexed, got %d objects, incomplete %v", result.Total, result.Incomplete)
+	}
+}
+
+func TestIndexAnnotations(t *testing.T) {
+	secrets := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	annotations := map[string]string{
+		"description":                      "nginx frontend",
+		corev1.LastAppliedConfigAnnotation: `{"data":{"password":"hunter2"}}`,
+	}
+	idx := NewIndex(100)
+	host := idx.AddCluster(hostClusterID, "", "host")
+	conf := newObject("default", "conf", "1", nil)
+	conf.Annotations = annotations
+	idx.Upsert(host, configmaps, "ConfigMap", conf)
+	secret := newObject("default", "credentials", "1", nil)
+	secret.Annotations = annotations
+	idx.Upsert(host, secrets, "Secret", secret)
+
+	expectHits(t, idx.Search(&Query{Text: "frontend"}), "host/ConfigMap/conf")
+	expectHits(t, idx.Search(&Query{Text: "hunter2"}))
+}
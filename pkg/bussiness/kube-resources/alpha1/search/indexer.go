@@ -0,0 +1,283 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	clusterv1alpha1 "captain/apis/cluster/v1alpha1"
+	clusterinformer "captain/pkg/client/informers/externalversions/cluster/v1alpha1"
+	capinformers "captain/pkg/informers"
+	"captain/pkg/utils/clustercache"
+	"captain/pkg/utils/clusterclient"
+)
+
+// hostClusterID is the id of the host cluster in the index, ids of member clusters are names of
+// cluster objects, which are never empty
+const hostClusterID = ""
+
+// errNotSynced is reported for member clusters until their objects are indexed
+var errNotSynced = errors.New("objects of the cluster are being indexed")
+
+// discoveryRetryInterval is the interval of retrying discovery of member clusters not reachable
+var discoveryRetryInterval = time.Minute
+
+// Indexer indexes objects of resources from informers of the host cluster, and informers of caches of
+// every ready member cluster, which are shared with reads of resources. Objects of member clusters are
+// removed once they are not ready or deleted
+type Indexer struct {
+	*Index
+
+	resources       []schema.GroupVersionResource
+	hostInformers   informers.SharedInformerFactory
+	hostDiscovery   discovery.DiscoveryInterface
+	clusterInformer clusterinformer.ClusterInformer
+	clients         clusterclient.ClusterClients
+	caches          *clustercache.Manager
+
+	lock    sync.Mutex
+	members map[string]*member
+}
+
+// member is the indexing of a member cluster, it is restarted if kubeconfig of the cluster changes
+type member struct {
+	kubeconfig string
+	stop       chan struct{}
+}
+
+// NewIndexer returns the indexer of resources of the host cluster by informers of factory, and of member
+// clusters by caches, which are shared with reads of resources of member clusters
+func NewIndexer(o *Options, resources []schema.GroupVersionResource, factory capinformers.CapInformerFactory, hostDiscovery discovery.DiscoveryInterface,
+	clients clusterclient.ClusterClients, caches *clustercache.Manager) *Indexer {
+	return &Indexer{
+		Index:           NewIndex(o.MaxDocuments),
+		resources:       resources,
+		hostInformers:   factory.KubernetesSharedInformerFactory(),
+		hostDiscovery:   hostDiscovery,
+		clusterInformer: factory.CaptainSharedInformerFactory().Cluster().V1alpha1().Clusters(),
+		clients:         clients,
+		caches:          caches,
+		members:         make(map[string]*member),
+	}
+}
+
+// Run indexes objects until stopCh is closed, it should be called once informers of the host cluster
+// are synced, so that objects are indexed from caches instead of being listed again
+func (i *Indexer) Run(stopCh <-chan struct{}) {
+	host := i.AddCluster(hostClusterID, "", "")
+	resources, err := serverResources(i.hostDiscovery, i.resources)
+	if err != nil {
+		klog.Errorf("discover resources indexed of the host cluster failed, %v", err)
+		i.SetClusterError(host, err)
+	}
+	for _, resource := range resources {
+		informer, err := i.hostInformers.ForResource(resource.gvr)
+		if err != nil {
+			klog.Errorf("can not make informer for resource - %s ", resource.gvr.String())
+			continue
+		}
+		i.addEventHandler(informer.Informer(), host, resource)
+	}
+	i.hostInformers.Start(stopCh)
+
+	i.clusterInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			i.syncCluster(obj.(*clusterv1alpha1.Cluster))
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			i.syncCluster(newObj.(*clusterv1alpha1.Cluster))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if cluster, ok := obj.(*clusterv1alpha1.Cluster); ok {
+				i.lock.Lock()
+				i.stopMember(cluster.Name)
+				i.lock.Unlock()
+			}
+		},
+	})
+
+	<-stopCh
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	for name := range i.members {
+		i.stopMember(name)
+	}
+}
+
+// syncCluster starts indexing a ready member cluster, or stops it if the cluster is not ready any more.
+// It is called on every resync of clusters, and does nothing if the cluster is not changed
+func (i *Indexer) syncCluster(cluster *clusterv1alpha1.Cluster) {
+	region, name := clusterclient.RegionAndName(cluster)
+	if i.clients.IsHostCluster(cluster) {
+		i.RenameCluster(hostClusterID, region, name)
+		return
+	}
+
+	ready := i.clients.IsClusterReady(cluster)
+	kubeconfig := string(cluster.Spec.Connection.KubeConfig)
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if m, ok := i.members[cluster.Name]; ok {
+		if ready && m.kubeconfig == kubeconfig {
+			i.RenameCluster(cluster.Name, region, name)
+			return
+		}
+		i.stopMember(cluster.Name)
+	}
+	if !ready {
+		return
+	}
+
+	m := &member{kubeconfig: kubeconfig, stop: make(chan struct{})}
+	i.members[cluster.Name] = m
+	ref := i.AddCluster(cluster.Name, region, name)
+	i.SetClusterError(ref, errNotSynced)
+	go i.runMember(ref, m, region, name)
+}
+
+// stopMember stops indexing a member cluster and removes its objects, called with the lock held
+func (i *Indexer) stopMember(name string) {
+	if m, ok := i.members[name]; ok {
+		close(m.stop)
+		delete(i.members, name)
+		i.DeleteCluster(name)
+	}
+}
+
+// runMember indexes objects of a member cluster from informers of its cache, the cache is held until
+// indexing is stopped, or the cache is stopped, e.g. it is rebuilt since the kubeconfig is changed and
+// the cluster is indexed again. Handlers can not be removed from informers, handlers of refs stopped
+// are ignored by the index until the cache is stopped
+func (i *Indexer) runMember(ref *clusterRef, m *member, region, name string) {
+	// member clusters may be unreachable for a while, discovery is retried until they are stopped
+	var resources []servedResource
+	err := wait.PollImmediateUntil(discoveryRetryInterval, func() (bool, error) {
+		clientset, err := i.clients.GetClientSet(region, name)
+		if err == nil {
+			resources, err = serverResources(clientset.Discovery(), i.resources)
+		}
+		if err != nil {
+			klog.V(4).Infof("discover resources indexed of cluster %s failed, %v", ref.id, err)
+			i.SetClusterError(ref, err)
+		}
+		return len(resources) != 0, nil
+	}, m.stop)
+	if err != nil {
+		return
+	}
+
+	c, err := i.caches.Get(region, name)
+	if err != nil {
+		i.SetClusterError(ref, err)
+		return
+	}
+	c.Hold()
+	defer c.Release()
+	stop := make(chan struct{})
+	go func() {
+		defer close(stop)
+		select {
+		case <-m.stop:
+		case <-c.Done():
+		}
+	}()
+
+	// informers are not waited by the cache, since objects of large clusters may not be synced in time
+	factory, err := c.KubernetesSharedInformerFactory(context.Background())
+	if err != nil {
+		i.SetClusterError(ref, err)
+		return
+	}
+	var synced []cache.InformerSynced
+	for _, resource := range resources {
+		informer, err := factory.ForResource(resource.gvr)
+		if err != nil {
+			klog.Errorf("can not make informer for resource - %s ", resource.gvr.String())
+			continue
+		}
+		i.addEventHandler(informer.Informer(), ref, resource)
+		synced = append(synced, informer.Informer().HasSynced)
+	}
+	factory.Start(c.Done())
+	if !cache.WaitForCacheSync(stop, synced...) {
+		return
+	}
+	i.SetClusterError(ref, nil)
+	<-stop
+}
+
+func (i *Indexer) addEventHandler(informer cache.SharedIndexInformer, ref *clusterRef, resource servedResource) {
+	gvr := resource.gvr
+	upsert := func(obj interface{}) {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			return
+		}
+		i.Upsert(ref, gvr, resource.kind, o)
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: upsert,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			upsert(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if o, err := meta.Accessor(obj); err == nil {
+				i.Delete(ref, gvr, o)
+			}
+		},
+	})
+}
+
+// servedResource is a resource indexed served by a cluster, objects of informers have no type meta,
+// so kinds are discovered along with resources
+type servedResource struct {
+	gvr  schema.GroupVersionResource
+	kind string
+}
+
+// serverResources returns resources of gvrs served by a cluster, resources of groups failed to be
+// discovered are absent, along with the error
+func serverResources(client discovery.DiscoveryInterface, gvrs []schema.GroupVersionResource) ([]servedResource, error) {
+	_, lists, err := client.ServerGroupsAndResources()
+	// groups not indexed failed to be discovered are ignored, e.g. unavailable metrics apis
+	if failed, ok := err.(*discovery.ErrGroupDiscoveryFailed); ok {
+		err = nil
+		for _, gvr := range gvrs {
+			if groupErr, ok := failed.Groups[gvr.GroupVersion()]; ok {
+				err = fmt.Errorf("discover %s failed, %v", gvr.GroupVersion(), groupErr)
+				break
+			}
+		}
+	}
+	var served []servedResource
+	for _, gvr := range gvrs {
+		for _, list := range lists {
+			if list.GroupVersion != gvr.GroupVersion().String() {
+				continue
+			}
+			for _, resource := range list.APIResources {
+				if resource.Name == gvr.Resource {
+					served = append(served, servedResource{gvr: gvr, kind: resource.Kind})
+				}
+			}
+		}
+	}
+	return served, err
+}
@@ -0,0 +1,42 @@
+package search
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+type Options struct {
+	// Enable indexes resources of the host and member clusters, and serves the search api
+	Enable bool `json:"enable" yaml:"enable" mapstructure:"enable"`
+
+	// MaxDocuments is the maximum number of objects indexed, objects beyond it are not indexed and
+	// search results are marked as incomplete until objects are deleted
+	MaxDocuments int `json:"maxDocuments" yaml:"maxDocuments" mapstructure:"maxDocuments"`
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Enable:       false,
+		MaxDocuments: 200000,
+	}
+}
+
+func (o *Options) Validate() []error {
+	var errs []error
+	if !o.Enable {
+		return errs
+	}
+
+	if o.MaxDocuments <= 0 {
+		errs = append(errs, fmt.Errorf("search max documents must be positive"))
+	}
+	return errs
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet, s *Options) {
+	fs.BoolVar(&o.Enable, "search-enable", s.Enable, ""+
+		"Index resources of the host and member clusters in memory, and serve the search api. It is disabled by default.")
+	fs.IntVar(&o.MaxDocuments, "search-max-documents", s.MaxDocuments, ""+
+		"Maximum number of objects indexed, it bounds memory used by the index.")
+}
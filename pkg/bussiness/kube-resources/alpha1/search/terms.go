@@ -0,0 +1,188 @@
+package search
+
+import (
+	"sort"
+	"strings"
+)
+
+// trigramLength is the length of grams terms are looked up by substrings, words shorter than it are
+// matched by prefixes only
+const trigramLength = 3
+
+// termSet is the set of terms of an index. Terms are looked up by prefixes from a radix tree, and by
+// substrings from trigrams of terms, so that lookups do not scan every term
+type termSet struct {
+	root     radixNode
+	trigrams map[string]map[string]struct{}
+}
+
+func newTermSet() *termSet {
+	return &termSet{trigrams: make(map[string]map[string]struct{})}
+}
+
+// add adds a term not in the set, terms are never empty
+func (s *termSet) add(term string) {
+	s.root.insert(term)
+	for _, gram := range trigrams(term) {
+		terms, ok := s.trigrams[gram]
+		if !ok {
+			terms = make(map[string]struct{})
+			s.trigrams[gram] = terms
+		}
+		terms[term] = struct{}{}
+	}
+}
+
+// delete removes a term of the set
+func (s *termSet) delete(term string) {
+	s.root.delete(term)
+	for _, gram := range trigrams(term) {
+		terms := s.trigrams[gram]
+		delete(terms, term)
+		if len(terms) == 0 {
+			delete(s.trigrams, gram)
+		}
+	}
+}
+
+// withPrefix calls fn with every term prefixed with prefix, including prefix itself
+func (s *termSet) withPrefix(prefix string, fn func(term string)) {
+	n, path := &s.root, ""
+	for len(prefix) != 0 {
+		i, ok := n.child(prefix[0])
+		if !ok {
+			return
+		}
+		c := n.children[i]
+		switch {
+		case strings.HasPrefix(prefix, c.label):
+			prefix = prefix[len(c.label):]
+		case strings.HasPrefix(c.label, prefix):
+			prefix = ""
+		default:
+			return
+		}
+		n, path = c, path+c.label
+	}
+	n.each(path, fn)
+}
+
+// containing calls fn with every term containing word, nothing is called if word is shorter than
+// trigramLength. Terms are looked up by the trigram of word with the fewest terms
+func (s *termSet) containing(word string, fn func(term string)) {
+	var candidates map[string]struct{}
+	for _, gram := range trigrams(word) {
+		terms, ok := s.trigrams[gram]
+		if !ok {
+			return
+		}
+		if candidates == nil || len(terms) < len(candidates) {
+			candidates = terms
+		}
+	}
+	for term := range candidates {
+		if strings.Contains(term, word) {
+			fn(term)
+		}
+	}
+}
+
+// trigrams returns bytes of s by trigramLength, terms are compared by bytes, so that grams of
+// multi-byte characters are the same in terms and words
+func trigrams(s string) []string {
+	if len(s) < trigramLength {
+		return nil
+	}
+	grams := make([]string, 0, len(s)-trigramLength+1)
+	for i := 0; i+trigramLength <= len(s); i++ {
+		grams = append(grams, s[i:i+trigramLength])
+	}
+	return grams
+}
+
+// radixNode is a node of a radix tree, labels of children are never empty and begin with different bytes
+type radixNode struct {
+	// label is the part of terms from the parent to the node
+	label string
+	// term is true if a term ends at the node
+	term bool
+	// children are sorted by the first bytes of labels
+	children []*radixNode
+}
+
+// child returns the index of the child beginning with b, or where it is inserted if there is none
+func (n *radixNode) child(b byte) (int, bool) {
+	i := sort.Search(len(n.children), func(i int) bool {
+		return n.children[i].label[0] >= b
+	})
+	return i, i < len(n.children) && n.children[i].label[0] == b
+}
+
+func (n *radixNode) insert(term string) {
+	for len(term) != 0 {
+		i, ok := n.child(term[0])
+		if !ok {
+			n.children = append(n.children, nil)
+			copy(n.children[i+1:], n.children[i:])
+			n.children[i] = &radixNode{label: term, term: true}
+			return
+		}
+		c := n.children[i]
+		common := commonPrefixLength(c.label, term)
+		if common < len(c.label) {
+			// the child is split by the common prefix
+			split := &radixNode{label: c.label[:common], children: []*radixNode{c}}
+			c.label = c.label[common:]
+			n.children[i] = split
+			c = split
+		}
+		n, term = c, term[common:]
+	}
+	n.term = true
+}
+
+// delete removes term under n, nodes left without terms are removed, and nodes left with a single
+// child are merged with it
+func (n *radixNode) delete(term string) {
+	i, ok := n.child(term[0])
+	if !ok {
+		return
+	}
+	c := n.children[i]
+	if !strings.HasPrefix(term, c.label) {
+		return
+	}
+	if rest := term[len(c.label):]; len(rest) != 0 {
+		c.delete(rest)
+	} else {
+		c.term = false
+	}
+
+	switch {
+	case c.term:
+	case len(c.children) == 0:
+		n.children = append(n.children[:i], n.children[i+1:]...)
+	case len(c.children) == 1:
+		only := c.children[0]
+		only.label = c.label + only.label
+		n.children[i] = only
+	}
+}
+
+// each calls fn with terms of n and its descendants, path is the term ending at n
+func (n *radixNode) each(path string, fn func(term string)) {
+	if n.term {
+		fn(path)
+	}
+	for _, c := range n.children {
+		c.each(path+c.label, fn)
+	}
+}
+
+func commonPrefixLength(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
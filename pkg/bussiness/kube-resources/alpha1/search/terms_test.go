@@ -0,0 +1,102 @@
+package search
+
+import (
+	"sort"
+	"testing"
+)
+
+func lookup(lookup func(word string, fn func(term string)), word string) []string {
+	var terms []string
+	lookup(word, func(term string) {
+		terms = append(terms, term)
+	})
+	sort.Strings(terms)
+	return terms
+}
+
+func TestTermSet(t *testing.T) {
+	s := newTermSet()
+	for _, term := range []string{"nginx", "nginx-conf", "ngrok", "my-nginx-proxy", "n", "redis"} {
+		s.add(term)
+	}
+
+	tests := []struct {
+		name               string
+		deleted            []string
+		prefix             string
+		expected           []string
+		contains           string
+		expectedContaining []string
+	}{
+		{
+			name:               "terms are looked up by prefixes and substrings",
+			prefix:             "ng",
+			expected:           []string{"nginx", "nginx-conf", "ngrok"},
+			contains:           "ginx",
+			expectedContaining: []string{"my-nginx-proxy", "nginx", "nginx-conf"},
+		},
+		{
+			name:               "prefixes ending in labels of nodes",
+			prefix:             "nginx-c",
+			expected:           []string{"nginx-conf"},
+			contains:           "x-c",
+			expectedContaining: []string{"nginx-conf"},
+		},
+		{
+			name:               "terms are prefixes of themselves",
+			prefix:             "n",
+			expected:           []string{"n", "nginx", "nginx-conf", "ngrok"},
+			contains:           "ng",
+			expectedContaining: nil,
+		},
+		{
+			name:               "deleted terms are not looked up",
+			deleted:            []string{"nginx", "n"},
+			prefix:             "n",
+			expected:           []string{"nginx-conf", "ngrok"},
+			contains:           "ginx",
+			expectedContaining: []string{"my-nginx-proxy", "nginx-conf"},
+		},
+		{
+			name:               "terms of merged nodes are looked up",
+			deleted:            []string{"ngrok"},
+			prefix:             "ngi",
+			expected:           []string{"nginx-conf"},
+			contains:           "rok",
+			expectedContaining: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, term := range test.deleted {
+				s.delete(term)
+			}
+			if terms := lookup(s.withPrefix, test.prefix); !equalTerms(terms, test.expected) {
+				t.Errorf("expected terms prefixed with %q %v, got %v", test.prefix, test.expected, terms)
+			}
+			if terms := lookup(s.containing, test.contains); !equalTerms(terms, test.expectedContaining) {
+				t.Errorf("expected terms containing %q %v, got %v", test.contains, test.expectedContaining, terms)
+			}
+		})
+	}
+
+	for _, term := range []string{"nginx-conf", "my-nginx-proxy", "redis"} {
+		s.delete(term)
+	}
+	if len(s.root.children) != 0 || len(s.trigrams) != 0 {
+		t.Errorf("expected the set empty, got %d nodes and %d trigrams", len(s.root.children), len(s.trigrams))
+	}
+}
+
+func equalTerms(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
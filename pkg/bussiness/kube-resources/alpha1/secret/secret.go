@@ -5,7 +5,9 @@ import (
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 )
 
@@ -18,11 +20,11 @@ func New(informer informers.SharedInformerFactory) secretProvider {
 }
 
 func (s secretProvider) Get(namespace, name string) (runtime.Object, error) {
-	return s.sharedInformers.Apps().V1().Deployments().Lister().Deployments(namespace).Get(name)
+	return s.sharedInformers.Core().V1().Secrets().Lister().Secrets(namespace).Get(name)
 }
 
 func (s secretProvider) List(namespace string, query *query.QueryInfo) (*response.ListResult, error) {
-	raw, err := s.sharedInformers.Apps().V1().Deployments().Lister().Deployments(namespace).List(query.GetSelector())
+	raw, err := s.sharedInformers.Core().V1().Secrets().Lister().Secrets(namespace).List(query.GetSelector())
 	if err != nil {
 		return nil, err
 	}
@@ -32,9 +34,29 @@ func (s secretProvider) List(namespace string, query *query.QueryInfo) (*respons
 		result = append(result, sc)
 	}
 
+	result, err = alpha1.SelectFields(result, query, s.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+func (s secretProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(s.sharedInformers.Core().V1().Secrets().Informer(), namespace, query, filter, s.SelectableFields)
+}
+
+// SelectableFields returns fields of secrets supported by field selectors, the same as kube-apiserver
+func (s secretProvider) SelectableFields(object runtime.Object) fields.Set {
+	secret, ok := object.(*v1.Secret)
+	if !ok {
+		secret = &v1.Secret{}
+	}
+	return alpha1.AddObjectMetaFieldsSet(fields.Set{
+		"type": string(secret.Type),
+	}, &secret.ObjectMeta, true)
+}
+
 func compareFunc(left, right runtime.Object, field query.Field) bool {
 
 	leftSecret, ok := left.(*v1.Secret)
@@ -5,7 +5,9 @@ import (
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 )
 
@@ -32,9 +34,27 @@ func (svc serviceProvider) List(namespace string, query *query.QueryInfo) (*resp
 		result = append(result, deploy)
 	}
 
+	result, err = alpha1.SelectFields(result, query, svc.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+func (svc serviceProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(svc.sharedInformers.Core().V1().Services().Informer(), namespace, query, filter, svc.SelectableFields)
+}
+
+// SelectableFields returns fields of services supported by field selectors, the same as kube-apiserver
+func (svc serviceProvider) SelectableFields(object runtime.Object) fields.Set {
+	service, ok := object.(*corev1.Service)
+	if !ok {
+		service = &corev1.Service{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&service.ObjectMeta, true)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	service, ok := object.(*corev1.Service)
 	if !ok {
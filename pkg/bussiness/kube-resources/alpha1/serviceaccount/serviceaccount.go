@@ -5,7 +5,9 @@ import (
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 )
 
@@ -33,9 +35,27 @@ func (cr serviceaccountProvider) List(namespace string, query *query.QueryInfo)
 	for _, serviceaccount := range serviceaccounts {
 		result = append(result, serviceaccount)
 	}
+	result, err = alpha1.SelectFields(result, query, cr.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+func (cr serviceaccountProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(cr.informers.Core().V1().ServiceAccounts().Informer(), namespace, query, filter, cr.SelectableFields)
+}
+
+// SelectableFields returns fields of serviceaccounts supported by field selectors, the same as kube-apiserver
+func (cr serviceaccountProvider) SelectableFields(object runtime.Object) fields.Set {
+	serviceAccount, ok := object.(*corev1.ServiceAccount)
+	if !ok {
+		serviceAccount = &corev1.ServiceAccount{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&serviceAccount.ObjectMeta, true)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	serviceAccount, ok := object.(*corev1.ServiceAccount)
 	if !ok {
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	v1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 )
 
@@ -41,9 +43,27 @@ func (sts statefulSetProvider) List(namespace string, query *query.QueryInfo) (*
 		result = append(result, deploy)
 	}
 
+	result, err = alpha1.SelectFields(result, query, sts.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+func (sts statefulSetProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(sts.sharedInformers.Apps().V1().StatefulSets().Informer(), namespace, query, filter, sts.SelectableFields)
+}
+
+// SelectableFields returns fields of statefulsets supported by field selectors, the same as kube-apiserver
+func (sts statefulSetProvider) SelectableFields(object runtime.Object) fields.Set {
+	statefulset, ok := object.(*v1.StatefulSet)
+	if !ok {
+		statefulset = &v1.StatefulSet{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&statefulset.ObjectMeta, true)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	statefulset, ok := object.(*v1.StatefulSet)
 	if !ok {
@@ -5,7 +5,9 @@ import (
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	v1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 )
 
@@ -33,9 +35,28 @@ func (sc storageclassProvider) List(namespace string, query *query.QueryInfo) (*
 		result = append(result, nasp)
 	}
 
+	result, err = alpha1.SelectFields(result, query, sc.SelectableFields)
+	if err != nil {
+		return nil, err
+	}
+
 	return alpha1.DefaultList(result, query, compareFunc, filter), nil
 }
 
+// Watch watches storageclasses from informers, namespace is ignored since they are cluster scoped
+func (sc storageclassProvider) Watch(namespace string, query *query.QueryInfo) (watch.Interface, error) {
+	return alpha1.InformerWatch(sc.informers.Storage().V1().StorageClasses().Informer(), "", query, filter, sc.SelectableFields)
+}
+
+// SelectableFields returns fields of storageclasses supported by field selectors, the same as kube-apiserver
+func (sc storageclassProvider) SelectableFields(object runtime.Object) fields.Set {
+	storageClass, ok := object.(*v1.StorageClass)
+	if !ok {
+		storageClass = &v1.StorageClass{}
+	}
+	return alpha1.ObjectMetaFieldsSet(&storageClass.ObjectMeta, false)
+}
+
 func filter(object runtime.Object, filter query.Filter) bool {
 	storageClass, ok := object.(*v1.StorageClass)
 	if !ok {
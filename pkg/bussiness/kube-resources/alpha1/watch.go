@@ -0,0 +1,413 @@
+package alpha1
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"captain/pkg/unify/query"
+)
+
+const (
+	// eventCapacity is the number of recent events of an informer kept for watches resuming from them
+	eventCapacity = 1000
+
+	// watchChanSize is the number of events buffered for a watch, watches not keeping up are stopped by
+	// an ERROR event of 410 Gone, clients should resume from the last resourceVersion they received
+	watchChanSize = 100
+)
+
+// bookmarkInterval is the interval of bookmark events of watches from informers
+var bookmarkInterval = time.Minute
+
+var (
+	informerWatchersLock sync.Mutex
+	// informerWatchers fans out events of informers to watches, a single event handler is added to
+	// every informer, since handlers can not be removed from informers
+	informerWatchers = make(map[cache.SharedIndexInformer]*informerWatcher)
+)
+
+// WatchOptions returns options of watching objects matching q from member clusters, bookmarks are
+// requested if q.AllowWatchBookmarks
+func WatchOptions(q *query.QueryInfo) metav1.ListOptions {
+	return metav1.ListOptions{
+		LabelSelector:       q.LabelSelector,
+		FieldSelector:       q.FieldSelector,
+		Watch:               true,
+		ResourceVersion:     q.ResourceVersion,
+		AllowWatchBookmarks: q.AllowWatchBookmarks,
+	}
+}
+
+// FilterWatch applies filters of q to events of a watch of member clusters, and projects objects of
+// events the same as lists. Label and field selectors are applied by member clusters. Objects
+// modified to no longer match filters are not sent
+func FilterWatch(w watch.Interface, q *query.QueryInfo, filterFunc FilterFunc) (watch.Interface, error) {
+	matches, err := newObjectMatcher(q, filterFunc, nil)
+	if err != nil {
+		w.Stop()
+		return nil, err
+	}
+	project := ProjectFunc(q)
+	return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		switch in.Type {
+		case watch.Bookmark, watch.Error:
+			return in, true
+		}
+		if !matches(in.Object) {
+			return in, false
+		}
+		in.Object = project(in.Object)
+		return in, true
+	}), nil
+}
+
+// InformerWatch watches objects of namespace matching q from informer, the same way as kube-apiserver
+// watches. Current objects are sent as ADDED events first if q.ResourceVersion is empty or "0",
+// otherwise the watch resumes from recent events of the informer after q.ResourceVersion, or an
+// ERROR event of 410 Gone is sent if they are not kept any more. Objects modified to no longer
+// match filters are not sent
+func InformerWatch(informer cache.SharedIndexInformer, namespace string, q *query.QueryInfo, filterFunc FilterFunc, fieldsFunc FieldsFunc) (watch.Interface, error) {
+	matches, err := newObjectMatcher(q, filterFunc, fieldsFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &informerWatch{
+		result:    make(chan watch.Event, watchChanSize),
+		done:      make(chan struct{}),
+		namespace: namespace,
+		matches:   matches,
+		project:   ProjectFunc(q),
+	}
+
+	watcher := watcherOf(informer)
+	watcher.Lock()
+	defer watcher.Unlock()
+
+	// initial events are sent in background, and live events are sent after them
+	w.pending = make([]watch.Event, 0)
+	switch q.ResourceVersion {
+	case "", "0":
+		w.since = parseResourceVersion(informer.LastSyncResourceVersion())
+		for _, obj := range informer.GetStore().List() {
+			if o, ok := obj.(runtime.Object); ok {
+				if event, ok := w.filter(watch.Event{Type: watch.Added, Object: o}, false); ok {
+					w.pending = append(w.pending, event)
+				}
+			}
+		}
+	default:
+		since, err := strconv.ParseUint(q.ResourceVersion, 10, 64)
+		if err != nil {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid resourceVersion %s", q.ResourceVersion))
+		}
+		if since < watcher.oldest {
+			return errorWatch(http.StatusGone, metav1.StatusReasonExpired,
+				fmt.Sprintf("too old resource version: %d (%d)", since, watcher.oldest)), nil
+		}
+		w.since = since
+		for _, event := range watcher.events {
+			if event, ok := w.filter(event, true); ok {
+				w.pending = append(w.pending, event)
+			}
+		}
+	}
+	watcher.watches[w] = struct{}{}
+	w.stop = func() {
+		watcher.Lock()
+		delete(watcher.watches, w)
+		watcher.Unlock()
+	}
+	go w.run(informer, watcher, q.AllowWatchBookmarks)
+	return w, nil
+}
+
+// informerWatcher keeps recent events of an informer and sends them to watches
+type informerWatcher struct {
+	sync.Mutex
+	watches map[*informerWatch]struct{}
+
+	// events are recent events of the informer, in the order of resourceVersion
+	events []watch.Event
+
+	// oldest is the resourceVersion events are kept after, watches can not resume before it
+	oldest uint64
+}
+
+func watcherOf(informer cache.SharedIndexInformer) *informerWatcher {
+	informerWatchersLock.Lock()
+	defer informerWatchersLock.Unlock()
+
+	if watcher, ok := informerWatchers[informer]; ok {
+		return watcher
+	}
+	watcher := &informerWatcher{
+		watches: make(map[*informerWatch]struct{}),
+		oldest:  parseResourceVersion(informer.LastSyncResourceVersion()),
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			watcher.send(watch.Added, obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// resyncs are not changes
+			if o, err := meta.Accessor(oldObj); err == nil {
+				if n, err := meta.Accessor(newObj); err == nil && o.GetResourceVersion() == n.GetResourceVersion() {
+					return
+				}
+			}
+			watcher.send(watch.Modified, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			watcher.send(watch.Deleted, obj)
+		},
+	})
+	informerWatchers[informer] = watcher
+	return watcher
+}
+
+func (iw *informerWatcher) send(eventType watch.EventType, obj interface{}) {
+	o, ok := obj.(runtime.Object)
+	if !ok {
+		return
+	}
+	event := watch.Event{Type: eventType, Object: o}
+	rv := resourceVersionOf(o)
+
+	iw.Lock()
+	defer iw.Unlock()
+	// existing objects are sent to the handler as added when it is added to the informer
+	if eventType == watch.Added && rv != 0 && rv <= iw.oldest {
+		return
+	}
+	if len(iw.events) == eventCapacity {
+		if evicted := resourceVersionOf(iw.events[0].Object); evicted > iw.oldest {
+			iw.oldest = evicted
+		}
+		iw.events = append(iw.events[:0], iw.events[1:]...)
+	}
+	iw.events = append(iw.events, event)
+
+	for w := range iw.watches {
+		if event, ok := w.filter(event, true); ok && !w.send(event) {
+			// watches not keeping up are removed at once, instead of receiving events until they are stopped
+			delete(iw.watches, w)
+			w.terminate(errorEvent(http.StatusGone, metav1.StatusReasonGone,
+				"watch is too slow to keep up with events, resume from the last resourceVersion received"))
+		}
+	}
+}
+
+// informerWatch is a watch of objects from an informer
+type informerWatch struct {
+	result chan watch.Event
+	done   chan struct{}
+
+	namespace string
+	matches   func(runtime.Object) bool
+	project   TransformFunc
+
+	// since is the resourceVersion the watch starts after, events of objects at or before it are
+	// already sent
+	since uint64
+
+	// pending are events sent before live events
+	pending []watch.Event
+
+	stopOnce sync.Once
+	stop     func()
+
+	// terminated is the event sent last if the watch is terminated by the server, e.g. it is too slow
+	terminated *watch.Event
+}
+
+func (w *informerWatch) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+func (w *informerWatch) Stop() {
+	w.stopOnce.Do(func() {
+		w.stop()
+		close(w.done)
+	})
+}
+
+// filter returns the event sent to the watch, false is returned if its object does not match the
+// watch, or if it is a change at or before w.since when checkSince. Deletions are always sent, since
+// resourceVersion of deletions missed by informers is unknown
+func (w *informerWatch) filter(event watch.Event, checkSince bool) (watch.Event, bool) {
+	o, err := meta.Accessor(event.Object)
+	if err != nil {
+		return event, false
+	}
+	if len(w.namespace) != 0 && o.GetNamespace() != w.namespace {
+		return event, false
+	}
+	if rv := parseResourceVersion(o.GetResourceVersion()); checkSince && event.Type != watch.Deleted && rv != 0 && rv <= w.since {
+		return event, false
+	}
+	if !w.matches(event.Object) {
+		return event, false
+	}
+	event.Object = w.project(event.Object)
+	return event, true
+}
+
+// terminate stops the watch removed from its informerWatcher, event is sent instead of the events
+// not received yet, called with the lock of informerWatcher held
+func (w *informerWatch) terminate(event watch.Event) {
+	w.stopOnce.Do(func() {
+		w.terminated = &event
+		close(w.done)
+	})
+}
+
+// send sends a live event, called with the lock of informerWatcher held. False is returned if the
+// client does not keep up, instead of blocking events of other watches
+func (w *informerWatch) send(event watch.Event) bool {
+	if w.pending != nil {
+		w.pending = append(w.pending, event)
+		return true
+	}
+	select {
+	case w.result <- event:
+	case <-w.done:
+	default:
+		return false
+	}
+	return true
+}
+
+func (w *informerWatch) run(informer cache.SharedIndexInformer, watcher *informerWatcher, allowBookmarks bool) {
+	defer func() {
+		// terminated is set before done is closed, and run returns after done is closed
+		if w.terminated != nil {
+			w.drain()
+			w.result <- *w.terminated
+		}
+		close(w.result)
+	}()
+
+	for {
+		watcher.Lock()
+		pending := w.pending
+		w.pending = nil
+		if len(pending) == 0 {
+			watcher.Unlock()
+			break
+		}
+		w.pending = make([]watch.Event, 0)
+		watcher.Unlock()
+
+		for _, event := range pending {
+			select {
+			case w.result <- event:
+			case <-w.done:
+				return
+			}
+		}
+	}
+
+	var bookmarks <-chan time.Time
+	if allowBookmarks {
+		ticker := time.NewTicker(bookmarkInterval)
+		defer ticker.Stop()
+		bookmarks = ticker.C
+	}
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-bookmarks:
+			bookmark := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{ResourceVersion: informer.LastSyncResourceVersion()}}
+			select {
+			case w.result <- watch.Event{Type: watch.Bookmark, Object: bookmark}:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// drain discards events not received yet, so that the event terminating the watch is sent without blocking
+func (w *informerWatch) drain() {
+	for {
+		select {
+		case <-w.result:
+		default:
+			return
+		}
+	}
+}
+
+// newObjectMatcher returns the function matching objects by filters, JSONPath filters, label and field
+// selectors of q. Selectors are not applied if fieldsFunc is nil, e.g. they are applied by member clusters
+func newObjectMatcher(q *query.QueryInfo, filterFunc FilterFunc, fieldsFunc FieldsFunc) (func(runtime.Object) bool, error) {
+	selector := q.GetSelector()
+	fieldSelector := fields.Everything()
+	if fieldsFunc != nil && len(q.FieldSelector) != 0 {
+		// unsupported fields are rejected the same as lists
+		if _, err := SelectFields(nil, q, fieldsFunc); err != nil {
+			return nil, err
+		}
+		fieldSelector, _ = fields.ParseSelector(q.FieldSelector)
+	}
+	if _, err := parseJSONPathFilters(q.JSONPathFilters); err != nil {
+		return nil, err
+	}
+
+	return func(obj runtime.Object) bool {
+		if fieldsFunc != nil {
+			o, err := meta.Accessor(obj)
+			if err != nil || !selector.Matches(labels.Set(o.GetLabels())) || !fieldSelector.Matches(fieldsFunc(obj)) {
+				return false
+			}
+		}
+		return len(filterObjects([]runtime.Object{obj}, q, filterFunc)) == 1
+	}, nil
+}
+
+// errorWatch returns a watch sending an ERROR event of status only, e.g. 410 Gone of too old resourceVersion
+func errorWatch(code int32, reason metav1.StatusReason, message string) watch.Interface {
+	ch := make(chan watch.Event, 1)
+	ch <- errorEvent(code, reason, message)
+	close(ch)
+	return watch.NewProxyWatcher(ch)
+}
+
+func errorEvent(code int32, reason metav1.StatusReason, message string) watch.Event {
+	return watch.Event{Type: watch.Error, Object: &metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    code,
+		Reason:  reason,
+		Message: message,
+	}}
+}
+
+func resourceVersionOf(obj runtime.Object) uint64 {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return 0
+	}
+	return parseResourceVersion(o.GetResourceVersion())
+}
+
+func parseResourceVersion(resourceVersion string) uint64 {
+	rv, _ := strconv.ParseUint(resourceVersion, 10, 64)
+	return rv
+}
@@ -0,0 +1,177 @@
+package alpha1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"captain/pkg/unify/query"
+)
+
+func nextEvent(t *testing.T, w watch.Interface) watch.Event {
+	t.Helper()
+	select {
+	case event, ok := <-w.ResultChan():
+		if !ok {
+			t.Fatal("watch closed unexpectedly")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for events")
+	}
+	return watch.Event{}
+}
+
+func expectEvent(t *testing.T, w watch.Interface, eventType watch.EventType, name, resourceVersion string) {
+	t.Helper()
+	event := nextEvent(t, w)
+	pod, ok := event.Object.(*v1.Pod)
+	if event.Type != eventType || !ok || pod.Name != name || pod.ResourceVersion != resourceVersion {
+		t.Fatalf("expected %s of %s at %s, got %s of %v", eventType, name, resourceVersion, event.Type, event.Object)
+	}
+}
+
+func TestInformerWatch(t *testing.T) {
+	pod := func(namespace, name, resourceVersion string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, ResourceVersion: resourceVersion}}
+	}
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &v1.PodList{
+			ListMeta: metav1.ListMeta{ResourceVersion: "11"},
+			Items:    []v1.Pod{*pod("default", "a", "10"), *pod("kube-system", "b", "11")},
+		}, nil
+	})
+	factory := informers.NewSharedInformerFactory(client, 0)
+	informer := factory.Core().V1().Pods().Informer()
+	stop := make(chan struct{})
+	defer close(stop)
+	factory.Start(stop)
+	factory.WaitForCacheSync(stop)
+
+	// current objects of the namespace first, then live events
+	w, err := InformerWatch(informer, "default", query.New(), filterTestObjects, testObjectFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	expectEvent(t, w, watch.Added, "a", "10")
+
+	pods := client.CoreV1().Pods("default")
+	if _, err := pods.Create(context.TODO(), pod("default", "c", "12"), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, w, watch.Added, "c", "12")
+	if _, err := pods.Update(context.TODO(), pod("default", "c", "13"), metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, w, watch.Modified, "c", "13")
+
+	// resuming from a resourceVersion replays events after it only
+	q := query.New()
+	q.ResourceVersion = "12"
+	resumed, err := InformerWatch(informer, "default", q, filterTestObjects, testObjectFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resumed.Stop()
+	expectEvent(t, resumed, watch.Modified, "c", "13")
+
+	// events of objects not matching filters are not sent
+	q = query.New()
+	q.AddFilter(string(query.FieldName), "exact(a)")
+	filtered, err := InformerWatch(informer, "", q, filterTestObjects, testObjectFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer filtered.Stop()
+	expectEvent(t, filtered, watch.Added, "a", "10")
+	if err := pods.Delete(context.TODO(), "c", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, w, watch.Deleted, "c", "13")
+	select {
+	case event := <-filtered.ResultChan():
+		t.Fatalf("unexpected event %v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// events before the oldest kept are gone
+	q = query.New()
+	q.ResourceVersion = "5"
+	expired, err := InformerWatch(informer, "default", q, filterTestObjects, testObjectFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := nextEvent(t, expired)
+	if status, ok := event.Object.(*metav1.Status); event.Type != watch.Error || !ok || status.Code != http.StatusGone {
+		t.Errorf("expected 410 Gone, got %s of %v", event.Type, event.Object)
+	}
+
+	q = query.New()
+	q.ResourceVersion = "invalid"
+	if _, err := InformerWatch(informer, "default", q, filterTestObjects, testObjectFields); err == nil {
+		t.Errorf("expected invalid resourceVersion rejected")
+	}
+}
+
+func TestInformerWatchTooSlow(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	factory := informers.NewSharedInformerFactory(client, 0)
+	informer := factory.Core().V1().Pods().Informer()
+	stop := make(chan struct{})
+	defer close(stop)
+	factory.Start(stop)
+	factory.WaitForCacheSync(stop)
+
+	w, err := InformerWatch(informer, "", query.New(), filterTestObjects, testObjectFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// events are not received until the watch falls behind, after initial events are sent
+	watcher := watcherOf(informer)
+	for live := false; !live; time.Sleep(10 * time.Millisecond) {
+		watcher.Lock()
+		live = w.(*informerWatch).pending == nil
+		watcher.Unlock()
+	}
+	for i := 0; i <= watchChanSize; i++ {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("pod-%d", i), ResourceVersion: strconv.Itoa(i + 1)}}
+		if _, err := client.CoreV1().Pods("default").Create(context.TODO(), pod, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for sent := false; !sent; time.Sleep(10 * time.Millisecond) {
+		watcher.Lock()
+		sent = len(watcher.events) > watchChanSize
+		watcher.Unlock()
+	}
+
+	var last watch.Event
+	for event, ok := nextEvent(t, w), true; ok; event, ok = <-w.ResultChan() {
+		last = event
+	}
+	if status, ok := last.Object.(*metav1.Status); last.Type != watch.Error || !ok || status.Code != http.StatusGone {
+		t.Errorf("expected the watch ended by 410 Gone, got %s of %v", last.Type, last.Object)
+	}
+
+	watcher.Lock()
+	defer watcher.Unlock()
+	if len(watcher.watches) != 0 {
+		t.Errorf("expected the watch removed from the informer, got %d watches", len(watcher.watches))
+	}
+}
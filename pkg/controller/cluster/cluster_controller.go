@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
@@ -131,6 +132,10 @@ type clusterController struct {
 	clusterMap map[string]*clusterData
 
 	options *multicluster.Options
+
+	// resyncPeriod is the period of probing clusters in nanoseconds, accessed atomically since
+	// it can be changed at runtime by SetResyncPeriod
+	resyncPeriod int64
 }
 
 func NewClusterController(
@@ -158,6 +163,7 @@ func NewClusterController(
 		workerLoopPeriod: time.Second,
 		clusterMap:       make(map[string]*clusterData),
 		options:          options,
+		resyncPeriod:     int64(options.ClusterControllerResyncPeriod),
 	}
 	c.clusterLister = clusterInformer.Lister()
 	c.clusterHasSynced = clusterInformer.Informer().HasSynced
@@ -190,22 +196,40 @@ func (c *clusterController) Run(workers int, stopCh <-chan struct{}) error {
 		go wait.Until(c.worker, c.workerLoopPeriod, stopCh)
 	}
 
-	// refresh cluster configz every resync period
-	go wait.Until(func() {
-		if err := c.reconcileHostCluster(); err != nil {
-			klog.Errorf("Error create host cluster, error %v", err)
-		}
+	// refresh cluster configz every resync period, the period is read every round so that
+	// the change of it takes effect from the next round
+	go func() {
+		for {
+			if err := c.reconcileHostCluster(); err != nil {
+				klog.Errorf("Error create host cluster, error %v", err)
+			}
 
-		if err := c.probeClusters(); err != nil {
-			klog.Errorf("failed to reconcile cluster ready status, err: %v", err)
-		}
+			if err := c.probeClusters(); err != nil {
+				klog.Errorf("failed to reconcile cluster ready status, err: %v", err)
+			}
 
-	}, c.options.ClusterControllerResyncPeriod, stopCh)
+			select {
+			case <-stopCh:
+				return
+			case <-time.After(c.getResyncPeriod()):
+			}
+		}
+	}()
 
 	<-stopCh
 	return nil
 }
 
+// SetResyncPeriod changes the period of probing clusters at runtime, the resync period of
+// cluster informer event handler is not changed until restart
+func (c *clusterController) SetResyncPeriod(period time.Duration) {
+	atomic.StoreInt64(&c.resyncPeriod, int64(period))
+}
+
+func (c *clusterController) getResyncPeriod() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.resyncPeriod))
+}
+
 func (c *clusterController) worker() {
 	for c.processNextItem() {
 	}
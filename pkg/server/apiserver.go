@@ -2,23 +2,44 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
+	"captain/pkg/bussiness/kube-resources/alpha1/resource"
+	"captain/pkg/bussiness/kube-resources/alpha1/search"
 	"captain/pkg/capis/version"
 	"captain/pkg/informers"
+	"captain/pkg/server/auditing"
+	"captain/pkg/server/authorization/authorizer"
 	captainserverconfig "captain/pkg/server/config"
 	"captain/pkg/server/dispatch"
 	"captain/pkg/server/filters"
+	"captain/pkg/server/healthz"
+	"captain/pkg/server/impersonation"
+	"captain/pkg/server/metrics"
+	"captain/pkg/server/openapi"
 	"captain/pkg/server/request"
 	resAlpha1 "captain/pkg/server/resources/alpha1"
 	resV1alpha1 "captain/pkg/server/resources/v1alpha1"
+	"captain/pkg/server/tracing"
+	captaincache "captain/pkg/simple/client/cache"
 	"captain/pkg/simple/client/k8s"
+	"captain/pkg/utils/clustercache"
+	"captain/pkg/utils/clusterclient"
 
 	"github.com/emicklei/go-restful"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	urlruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
 	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/apiserver/pkg/server/dynamiccertificates"
+	k8shealthz "k8s.io/apiserver/pkg/server/healthz"
+	toolscache "k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 )
@@ -26,8 +47,20 @@ import (
 type CaptainAPIServer struct {
 	ServerCount int
 
+	// Server serves plain http on the insecure port, nil if insecure port is disabled
 	Server *http.Server
 
+	// SecureServer serves https on the secure port, nil if secure port is disabled.
+	// Both servers can be run at the same time, e.g. when migrating clients to https
+	SecureServer *http.Server
+
+	// ServingCert is the certificate of SecureServer, reloaded when the files are rotated
+	ServingCert dynamiccertificates.CertKeyContentProvider
+
+	// ClientCA is used to request client certificates in tls handshake, nil means client
+	// certificates are not requested
+	ClientCA dynamiccertificates.CAContentProvider
+
 	Config *captainserverconfig.Config
 
 	// webservice container, where all webservice defines
@@ -42,6 +75,61 @@ type CaptainAPIServer struct {
 
 	// controller-runtime client
 	KubeRuntimeCache cache.Cache
+
+	// CacheClient is the redis client, nil if redis is not configured. It is replaced when redis
+	// configuration changes at runtime, use cacheClient() to read it after the server is started
+	CacheClient captaincache.Interface
+	cacheMu     sync.RWMutex
+
+	// cacheSynced is closed once informer caches are synced, server is not ready until then
+	cacheSynced chan struct{}
+
+	// Authenticator authenticates requests, nil means authentication is disabled
+	Authenticator authenticator.Request
+
+	// Authorizer decides whether a request is allowed by the request info and user
+	Authorizer authorizer.Authorizer
+
+	// Auditing records requests to audit sinks, nil means auditing is disabled
+	Auditing auditing.Auditing
+
+	// Impersonator forwards the authenticated user to kubernetes, nil means requests are
+	// proxied with captain's own identity
+	Impersonator *impersonation.Impersonator
+
+	// TracerProvider traces requests, nil means tracing is disabled
+	TracerProvider trace.TracerProvider
+
+	// dispatcher forwards requests to member clusters, it dispatches nothing while multicluster
+	// mode is disabled, and is replaced when multicluster mode is toggled at runtime
+	dispatcher *dispatch.DynamicDispatcher
+
+	// reloadMu serializes applying of configuration changes
+	reloadMu sync.Mutex
+
+	// stopCh is the stop channel of PrepareRun, for components started at runtime
+	stopCh <-chan struct{}
+
+	// ShutdownDelayDuration is the time servers keep serving after readyz fails on shutdown
+	ShutdownDelayDuration time.Duration
+
+	// ShutdownGracePeriod is the time in-flight requests and hijacked connections are allowed
+	// to finish on shutdown, they are closed after it
+	ShutdownGracePeriod time.Duration
+
+	// shuttingDown is closed once shutdown starts, server is not ready since then
+	shuttingDown chan struct{}
+
+	// hijackedConns tracks upgraded connections, which are not drained by http.Server
+	hijackedConns *filters.HijackedConnections
+
+	// clusterClients are clients of member clusters, clusterCaches cache objects of member clusters
+	// for reads of resources and the search indexer
+	clusterClients clusterclient.ClusterClients
+	clusterCaches  *clustercache.Manager
+
+	// searchIndexer indexes resources of clusters for the search api, nil if search is disabled
+	searchIndexer *search.Indexer
 }
 
 type errorResponder struct{}
@@ -52,6 +140,7 @@ func (e *errorResponder) Error(w http.ResponseWriter, req *http.Request, err err
 }
 
 func (s *CaptainAPIServer) PrepareRun(stopCh <-chan struct{}) error {
+	s.stopCh = stopCh
 	s.container = restful.NewContainer()
 	//s.container.Filter(logRequestAndResponse)
 	s.container.Router(restful.CurlyRouter{})
@@ -59,17 +148,39 @@ func (s *CaptainAPIServer) PrepareRun(stopCh <-chan struct{}) error {
 	//	logStackOnRecover(panicReason, httpWriter)
 	//})
 
+	// requests to member clusters are traced as children of spans of requests
+	var wrapTransport clusterclient.WrapTransportFunc
+	if s.TracerProvider != nil {
+		wrapTransport = tracing.WrapTransport
+	}
+	s.clusterClients = clusterclient.NewClusterClients(s.InformerFactory.CaptainSharedInformerFactory().Cluster().V1alpha1().Clusters(), wrapTransport)
+	s.clusterCaches = clustercache.NewManager(s.clusterClients, clustercache.DefaultIdleTimeout)
+	go s.clusterCaches.Run(stopCh)
+
 	// install apis
 	s.installCaptainAPIs()
 
+	metrics.Register()
+	s.container.Handle("/metrics", metrics.Handler())
+
+	openapi.AddToContainer(s.container)
+
+	s.cacheSynced = make(chan struct{})
+	s.shuttingDown = make(chan struct{})
+	s.installHealthz()
+
 	for _, ws := range s.container.RegisteredWebServices() {
 		klog.V(2).Infof("%s", ws.RootPath())
 	}
 
-	s.Server.Handler = s.container
-
 	// handle chain
-	s.buildHandlerChain(stopCh)
+	handler := s.buildHandlerChain(s.container, stopCh)
+	handler = withHealthz(handler, s.container)
+	s.hijackedConns = filters.NewHijackedConnections()
+	handler = filters.WithHijackTracking(handler, s.hijackedConns)
+	for _, server := range s.servers() {
+		server.Handler = handler
+	}
 
 	return nil
 }
@@ -83,34 +194,117 @@ func (s *CaptainAPIServer) installCaptainAPIs() {
 	urlruntime.Must(version.AddToContainer(s.container, s.KubernetesClient.Discovery()))
 
 	// captain apis for kube resources
-	urlruntime.Must(resAlpha1.AddToContainer(s.container, s.InformerFactory, s.KubeRuntimeCache))
+	if s.Config.SearchOptions != nil && s.Config.SearchOptions.Enable {
+		s.searchIndexer = search.NewIndexer(s.Config.SearchOptions, resource.GroupVersionResources(), s.InformerFactory, s.KubernetesClient.Kubernetes().Discovery(),
+			s.clusterClients, s.clusterCaches)
+	}
+	// writes are forbidden unless they are impersonated, otherwise they are sent with the identity of captain
+	var impersonate resource.ImpersonateFunc
+	if s.Impersonator != nil {
+		impersonate = s.Impersonator.Config
+	}
+	urlruntime.Must(resAlpha1.AddToContainer(s.container, s.InformerFactory, s.KubeRuntimeCache, s.clusterClients, s.clusterCaches,
+		s.KubernetesClient.Config(), impersonate, s.searchIndexer, s.Authorizer, s.stopCh))
 
 	// captain apis for captain cluster resources
 	urlruntime.Must(resV1alpha1.AddToContainer(s.container, s.InformerFactory, s.KubernetesClient, s.KubeRuntimeCache))
 
 }
 
-// 通过WithRequestInfo解析API请求的信息，WithKubeAPIServer根据API请求信息判断是否代理请求给Kubernetes
-func (s *CaptainAPIServer) buildHandlerChain(stopCh <-chan struct{}) {
+// installHealthz installs /healthz, /readyz and /livez. Liveness only means the server is serving,
+// while readiness requires informer caches synced and dependent services reachable
+func (s *CaptainAPIServer) installHealthz() {
+	checks := []k8shealthz.HealthChecker{
+		k8shealthz.PingHealthz,
+		healthz.InformerSyncCheck(s.cacheSynced),
+		healthz.KubeAPIServerCheck(s.KubernetesClient.Discovery()),
+	}
+	// redis check is always installed, since redis may be configured at runtime
+	checks = append(checks, healthz.RedisCheck(s.cacheClient))
+
+	var reporters []healthz.Reporter
+	if s.Config.MultiClusterOptions.Enable && s.Config.MultiClusterOptions.MemberClusterHealthCheck {
+		clusterLister := s.InformerFactory.CaptainSharedInformerFactory().Cluster().V1alpha1().Clusters().Lister()
+		reporters = append(reporters, healthz.NewMemberClusterReporter(clusterLister, s.clusterClients))
+	}
+
+	healthz.InstallPathHandler(s.container, "/healthz", checks, s.Authenticator, reporters...)
+	// readyz fails once shutdown starts, while healthz keeps passing until the server stops
+	readyzChecks := append([]k8shealthz.HealthChecker{healthz.ShutdownCheck(s.shuttingDown)}, checks...)
+	healthz.InstallPathHandler(s.container, "/readyz", readyzChecks, s.Authenticator, reporters...)
+	healthz.InstallPathHandler(s.container, "/livez", []k8shealthz.HealthChecker{k8shealthz.PingHealthz}, nil)
+}
+
+var healthzPaths = []string{"/healthz", "/readyz", "/livez"}
+
+// withHealthz serves health endpoints by healthz directly, without going through the handler chain,
+// so that probes of kubelet work without credentials and are not recorded by metrics and auditing
+func withHealthz(handler http.Handler, healthz http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for _, path := range healthzPaths {
+			if req.URL.Path == path || strings.HasPrefix(req.URL.Path, path+"/") {
+				healthz.ServeHTTP(w, req)
+				return
+			}
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// 通过WithRequestInfo解析API请求的信息，WithTracing为请求创建span，WithMetrics记录请求数量和延迟，WithAuthentication认证用户，WithAuditing记录审计事件，WithAuthorization根据用户和请求信息鉴权，
+// WithKubeAPIServer根据API请求信息判断是否代理请求给Kubernetes
+func (s *CaptainAPIServer) buildHandlerChain(handler http.Handler, stopCh <-chan struct{}) http.Handler {
 	requestInfoResolver := &request.RequestInfoFactory{
-		APIPrefixes: sets.NewString("api", "apis"),
+		APIPrefixes: sets.NewString("api", "apis", "capis"),
 	}
 
-	handler := s.Server.Handler
-	handler = filters.WithKubeAPIServer(handler, s.KubernetesClient.Config(), &errorResponder{})
+	handler = filters.WithKubeAPIServer(handler, s.KubernetesClient.Config(), s.Impersonator, &errorResponder{})
 
+	s.dispatcher = &dispatch.DynamicDispatcher{}
 	if s.Config.MultiClusterOptions.Enable {
-		clusterDispatcher := dispatch.NewClusterDispatch(s.InformerFactory.CaptainSharedInformerFactory().Cluster().V1alpha1().Clusters())
-		handler = filters.WithMultipleClusterDispatcher(handler, clusterDispatcher)
+		s.dispatcher.Set(s.newClusterDispatch())
 	}
+	handler = filters.WithMultipleClusterDispatcher(handler, s.dispatcher)
 
+	handler = filters.WithAuthorization(handler, s.Authorizer)
+	if s.Auditing != nil {
+		go s.Auditing.Run(stopCh)
+	}
+	handler = filters.WithAuditing(handler, s.Auditing)
+	handler = filters.WithAuthentication(handler, s.Authenticator)
+	handler = filters.WithMetrics(handler)
+	handler = filters.WithTracing(handler, s.TracerProvider)
 	handler = filters.WithRequestInfo(handler, requestInfoResolver)
 
-	s.Server.Handler = handler
+	return handler
+}
+
+func (s *CaptainAPIServer) newClusterDispatch() dispatch.Dispatcher {
+	return dispatch.NewClusterDispatch(s.clusterClients, s.Impersonator)
+}
+
+// cacheClient returns the current redis client, nil if redis is not configured
+func (s *CaptainAPIServer) cacheClient() captaincache.Interface {
+	s.cacheMu.RLock()
+	defer s.cacheMu.RUnlock()
+	return s.CacheClient
+}
+
+// servers returns all enabled servers
+func (s *CaptainAPIServer) servers() []*http.Server {
+	var servers []*http.Server
+	if s.Server != nil {
+		servers = append(servers, s.Server)
+	}
+	if s.SecureServer != nil {
+		servers = append(servers, s.SecureServer)
+	}
+	return servers
 }
 
 func (s *CaptainAPIServer) waitForResourceSync(ctx context.Context) error {
 	klog.V(0).Info("Start cache objects")
+	start := time.Now()
 
 	stopCh := ctx.Done()
 
@@ -164,10 +358,12 @@ func (s *CaptainAPIServer) waitForResourceSync(ctx context.Context) error {
 		if !isResourceExists(gvr) {
 			klog.Warningf("resource %s not exists in the cluster", gvr.String())
 		} else {
-			_, err = s.InformerFactory.KubernetesSharedInformerFactory().ForResource(gvr)
+			informer, err := s.InformerFactory.KubernetesSharedInformerFactory().ForResource(gvr)
 			if err != nil {
 				klog.Errorf("can not make informer for resource - %s ", gvr.String())
+				continue
 			}
+			trackInformerSynced(gvr, informer.Informer(), stopCh)
 		}
 	}
 	s.InformerFactory.KubernetesSharedInformerFactory().Start(stopCh)
@@ -181,44 +377,139 @@ func (s *CaptainAPIServer) waitForResourceSync(ctx context.Context) error {
 		if !isResourceExists(gvr) {
 			klog.Warningf("resource %s not exists in the cluster", gvr)
 		} else {
-			_, err = crdInformerFactory.ForResource(gvr)
+			informer, err := crdInformerFactory.ForResource(gvr)
 			if err != nil {
 				return err
 			}
+			trackInformerSynced(gvr, informer.Informer(), stopCh)
 		}
 	}
 
 	crdInformerFactory.Start(stopCh)
-	crdInformerFactory.WaitForCacheSync(stopCh)
+
+	// server is ready only once every cache is synced, they are not if stopCh is closed before
+	for informerType, synced := range s.InformerFactory.KubernetesSharedInformerFactory().WaitForCacheSync(stopCh) {
+		if !synced {
+			return fmt.Errorf("cache of %v is not synced", informerType)
+		}
+	}
+	for informerType, synced := range crdInformerFactory.WaitForCacheSync(stopCh) {
+		if !synced {
+			return fmt.Errorf("cache of %v is not synced", informerType)
+		}
+	}
 
 	klog.V(0).Info("Finished caching objects")
+	metrics.SetCacheSyncDuration(time.Since(start))
 
 	return nil
 }
 
-func (s *CaptainAPIServer) Run(ctx context.Context) (err error) {
-	err = s.waitForResourceSync(ctx)
-	if err != nil {
-		return err
-	}
-
-	shutdownCtx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+// trackInformerSynced records informer of gvr as not synced, and as synced once its cache synced
+func trackInformerSynced(gvr schema.GroupVersionResource, informer toolscache.SharedIndexInformer, stopCh <-chan struct{}) {
+	metrics.SetInformerSynced(gvr.Group, gvr.Version, gvr.Resource, false)
+	go func() {
+		if toolscache.WaitForCacheSync(stopCh, informer.HasSynced) {
+			metrics.SetInformerSynced(gvr.Group, gvr.Version, gvr.Resource, true)
+		}
+	}()
+}
 
+func (s *CaptainAPIServer) Run(ctx context.Context) (err error) {
+	shutdownDone := make(chan struct{})
 	go func() {
 		<-ctx.Done()
-		_ = s.Server.Shutdown(shutdownCtx)
+		s.shutdown()
+		close(shutdownDone)
 	}()
 
 	// Caching resources
 	// informersFactory := informers.NewInformerFactories(kubeClient)
 
-	klog.V(0).Infof("Start listening on %s", s.Server.Addr)
-	if s.Server.TLSConfig != nil {
-		err = s.Server.ListenAndServeTLS("", "")
-	} else {
-		err = s.Server.ListenAndServe()
+	errCh := make(chan error, 3)
+	if s.Server != nil {
+		go func() {
+			klog.V(0).Infof("Start listening on %s", s.Server.Addr)
+			errCh <- s.Server.ListenAndServe()
+		}()
+	}
+	if s.SecureServer != nil {
+		s.SecureServer.TLSConfig = s.tlsConfig(ctx.Done())
+		go func() {
+			klog.V(0).Infof("Start secure listening on %s", s.SecureServer.Addr)
+			errCh <- s.SecureServer.ListenAndServeTLS("", "")
+		}()
 	}
 
+	// servers are started before caching objects so that probes can be answered,
+	// readyz fails until caches are synced
+	go func() {
+		if err := s.waitForResourceSync(ctx); err != nil {
+			errCh <- err
+			return
+		}
+		close(s.cacheSynced)
+		// objects are indexed from informer caches once they are synced
+		if s.searchIndexer != nil {
+			s.searchIndexer.Run(ctx.Done())
+		}
+	}()
+
+	// returns once any server stops, the caller cancels ctx and the other one is shut down as well.
+	// Servers stop listening as soon as shutdown starts, wait for in-flight requests to be drained
+	err = <-errCh
+	if err == http.ErrServerClosed {
+		<-shutdownDone
+	}
+	s.shutdownTracing()
 	return err
 }
+
+// shutdown fails readyz first and keeps serving for ShutdownDelayDuration, so that load balancers stop
+// sending new requests. Then servers stop listening and in-flight requests are allowed to finish in
+// ShutdownGracePeriod, watches and hijacked connections still open after it are closed
+func (s *CaptainAPIServer) shutdown() {
+	close(s.shuttingDown)
+	if s.ShutdownDelayDuration > 0 {
+		klog.V(0).Infof("Shutting down, keep serving for %v until load balancers notice", s.ShutdownDelayDuration)
+		time.Sleep(s.ShutdownDelayDuration)
+	}
+
+	klog.V(0).Infof("Draining in-flight requests in %v", s.ShutdownGracePeriod)
+	ctx, cancel := context.WithTimeout(context.Background(), s.ShutdownGracePeriod)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, server := range s.servers() {
+		wg.Add(1)
+		go func(server *http.Server) {
+			defer wg.Done()
+			if err := server.Shutdown(ctx); err != nil {
+				klog.Warningf("Failed to drain requests of %s in %v, closing them: %v", server.Addr, s.ShutdownGracePeriod, err)
+				_ = server.Close()
+			}
+		}(server)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := s.hijackedConns.Shutdown(ctx); err != nil {
+			klog.Warningf("Hijacked connections are not closed in %v, closing them: %v", s.ShutdownGracePeriod, err)
+		}
+	}()
+	wg.Wait()
+	klog.V(0).Info("Finished draining requests")
+}
+
+// shutdownTracing exports spans not exported yet
+func (s *CaptainAPIServer) shutdownTracing() {
+	tp, ok := s.TracerProvider.(interface{ Shutdown(context.Context) error })
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tp.Shutdown(ctx); err != nil {
+		klog.Warningf("failed to export spans, error: %v", err)
+	}
+}
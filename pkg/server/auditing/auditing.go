@@ -0,0 +1,215 @@
+package auditing
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/klog"
+	"sigs.k8s.io/yaml"
+
+	"captain/pkg/server/request"
+)
+
+// Auditing records requests and sends the audit events to sinks asynchronously
+type Auditing interface {
+	// LogRequestObject creates an Event from the request, the request body is read
+	// and restored when level is Request
+	LogRequestObject(req *http.Request, info *request.RequestInfo) *Event
+
+	// LogResponseObject completes the event with response code and sends it to sinks
+	LogResponseObject(e *Event, code int)
+
+	// Run sends events to sinks until stopCh is closed
+	Run(stopCh <-chan struct{})
+}
+
+type auditing struct {
+	options *Options
+	sinks   []Sink
+	eventCh chan *Event
+}
+
+// NewAuditing returns nil if auditing is disabled
+func NewAuditing(o *Options) Auditing {
+	if o == nil || !o.Enable {
+		return nil
+	}
+
+	var sinks []Sink
+	if o.LogOptions != nil && o.LogOptions.Path != "" {
+		sinks = append(sinks, NewLogSink(o.LogOptions))
+	}
+	if o.WebhookOptions != nil && o.WebhookOptions.URL != "" {
+		sinks = append(sinks, NewWebhookSink(o.WebhookOptions))
+	}
+
+	return &auditing{
+		options: o,
+		sinks:   sinks,
+		eventCh: make(chan *Event, o.EventBufferSize),
+	}
+}
+
+func (a *auditing) LogRequestObject(req *http.Request, info *request.RequestInfo) *Event {
+	e := &Event{
+		AuditID:                  uuid.NewUUID(),
+		Level:                    a.options.Level,
+		RequestReceivedTimestamp: time.Now(),
+		SourceIP:                 info.SourceIP,
+		UserAgent:                info.UserAgent,
+		Verb:                     info.Verb,
+		RequestURI:               req.URL.RequestURI(),
+		Region:                   info.Region,
+		Cluster:                  info.Cluster,
+		Namespace:                info.Namespace,
+		APIGroup:                 info.APIGroup,
+		APIVersion:               info.APIVersion,
+		Resource:                 info.Resource,
+		Subresource:              info.Subresource,
+		Name:                     info.Name,
+	}
+
+	if u, ok := request.UserFrom(req.Context()); ok {
+		e.User = u.GetName()
+		e.UID = u.GetUID()
+		e.Groups = u.GetGroups()
+	}
+
+	if a.options.Level == LevelRequest && req.Body != nil && hasRequestBody(req.Method) {
+		a.readRequestBody(req, info, e)
+	}
+
+	return e
+}
+
+// readRequestBody reads at most MaxRequestBodySize bytes of the body into event, and
+// restores the body so that it can still be read by the following handlers. Objects in
+// JSON or YAML are recorded as objects, the other bodies are recorded as raw bytes. Values
+// of secrets are redacted, bodies of secrets that cannot be redacted are not recorded
+func (a *auditing) readRequestBody(req *http.Request, info *request.RequestInfo, e *Event) {
+	body, err := io.ReadAll(io.LimitReader(req.Body, int64(a.options.MaxRequestBodySize)+1))
+	if err != nil {
+		klog.Errorf("failed to read request body for auditing: %v", err)
+	}
+	req.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(body), req.Body), Closer: req.Body}
+
+	if len(body) > a.options.MaxRequestBodySize {
+		e.RequestObjectTruncated = true
+		return
+	}
+	object := body
+	if !json.Valid(object) {
+		object = nil
+		// e.g. objects written or applied in YAML
+		if converted, err := yaml.YAMLToJSON(body); err == nil && isObjectOrArray(converted) {
+			object = converted
+		}
+	}
+
+	if info.Resource == "secrets" {
+		e.RequestObject = redactSecret(object)
+		return
+	}
+	if object != nil {
+		e.RequestObject = object
+		return
+	}
+	e.RequestBody = body
+}
+
+// redactedValue replaces values of data and stringData of secrets
+const redactedValue = "REDACTED"
+
+// redactSecret returns object with values of data and stringData redacted, the same as audit
+// policies of kube-apiserver never record them. nil is returned if object is not a JSON object,
+// e.g. a JSON patch, since values can not be found in it
+func redactSecret(object []byte) []byte {
+	var secret map[string]interface{}
+	if err := json.Unmarshal(object, &secret); err != nil || secret == nil {
+		return nil
+	}
+	for _, field := range []string{"data", "stringData"} {
+		if values, ok := secret[field].(map[string]interface{}); ok {
+			for key := range values {
+				values[key] = redactedValue
+			}
+		}
+	}
+	redacted, err := json.Marshal(secret)
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+// isObjectOrArray returns true if data is a JSON object or array, scalars converted from plain
+// text by YAML are not objects
+func isObjectOrArray(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && (data[0] == '{' || data[0] == '[')
+}
+
+func (a *auditing) LogResponseObject(e *Event, code int) {
+	e.StageTimestamp = time.Now()
+	e.Latency = e.StageTimestamp.Sub(e.RequestReceivedTimestamp).Milliseconds()
+	e.ResponseCode = code
+
+	select {
+	case a.eventCh <- e:
+	default:
+		klog.Warningf("audit event buffer is full, event %s dropped", e.AuditID)
+	}
+}
+
+func (a *auditing) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(a.options.EventBatchInterval)
+	defer ticker.Stop()
+
+	var events []*Event
+	flush := func() {
+		if len(events) == 0 {
+			return
+		}
+		for _, sink := range a.sinks {
+			if err := sink.ProcessEvents(events...); err != nil {
+				klog.Errorf("failed to send %d audit events: %v", len(events), err)
+			}
+		}
+		events = nil
+	}
+
+	for {
+		select {
+		case e := <-a.eventCh:
+			events = append(events, e)
+			if len(events) >= a.options.EventBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-stopCh:
+			// drain events already buffered before exiting
+			for len(a.eventCh) > 0 {
+				events = append(events, <-a.eventCh)
+			}
+			flush()
+			for _, sink := range a.sinks {
+				_ = sink.Close()
+			}
+			return
+		}
+	}
+}
+
+func hasRequestBody(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
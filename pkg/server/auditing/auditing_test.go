@@ -0,0 +1,127 @@
+package auditing
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	"captain/pkg/server/request"
+)
+
+type fakeSink struct {
+	sync.Mutex
+	events []*Event
+	closed bool
+}
+
+func (s *fakeSink) ProcessEvents(events ...*Event) error {
+	s.Lock()
+	defer s.Unlock()
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *fakeSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestLogRequestObject(t *testing.T) {
+	tests := []struct {
+		name            string
+		level           Level
+		resource        string
+		body            string
+		maxBodySize     int
+		expectObject    string
+		expectBody      string
+		expectTruncated bool
+	}{
+		{name: "metadata level", level: LevelMetadata, body: `{"kind":"Pod"}`, maxBodySize: 1024},
+		{name: "request level", level: LevelRequest, body: `{"kind":"Pod"}`, maxBodySize: 1024, expectObject: `{"kind":"Pod"}`},
+		{name: "request level truncated", level: LevelRequest, body: `{"kind":"Pod"}`, maxBodySize: 4, expectTruncated: true},
+		{name: "request level yaml body", level: LevelRequest, body: "kind: Pod", maxBodySize: 1024, expectObject: `{"kind":"Pod"}`},
+		{name: "request level raw body", level: LevelRequest, body: "hello", maxBodySize: 1024, expectBody: "hello"},
+		{
+			name: "request level secret", level: LevelRequest, resource: "secrets", maxBodySize: 1024,
+			body:         `{"kind":"Secret","data":{"password":"c2VjcmV0"},"stringData":{"token":"secret"}}`,
+			expectObject: `{"data":{"password":"REDACTED"},"kind":"Secret","stringData":{"token":"REDACTED"}}`,
+		},
+		{name: "request level secret json patch", level: LevelRequest, resource: "secrets", body: `[{"op":"add","path":"/data/password","value":"c2VjcmV0"}]`, maxBodySize: 1024},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := NewOptions()
+			o.Enable = true
+			o.Level = test.level
+			o.MaxRequestBodySize = test.maxBodySize
+			a := NewAuditing(o)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/default/pods", strings.NewReader(test.body))
+			req = req.WithContext(request.WithUser(req.Context(), &user.DefaultInfo{Name: "admin", Groups: []string{"system:authenticated"}}))
+			info := &request.RequestInfo{
+				RequestInfo: &k8srequest.RequestInfo{Verb: "create", Namespace: "default", APIVersion: "v1", Resource: "pods"},
+				Cluster:     "host",
+			}
+			if len(test.resource) != 0 {
+				info.Resource = test.resource
+			}
+
+			e := a.LogRequestObject(req, info)
+			if e.User != "admin" || e.Verb != "create" || e.Resource != info.Resource || e.Cluster != "host" {
+				t.Errorf("unexpected event metadata %+v", e)
+			}
+			if string(e.RequestObject) != test.expectObject {
+				t.Errorf("expected request object %q, got %q", test.expectObject, string(e.RequestObject))
+			}
+			if string(e.RequestBody) != test.expectBody {
+				t.Errorf("expected request body %q, got %q", test.expectBody, string(e.RequestBody))
+			}
+			if e.RequestObjectTruncated != test.expectTruncated {
+				t.Errorf("expected truncated %v, got %v", test.expectTruncated, e.RequestObjectTruncated)
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil || string(body) != test.body {
+				t.Errorf("request body is not restored, got %q, err %v", string(body), err)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	o := NewOptions()
+	o.Enable = true
+	o.EventBatchSize = 2
+	o.EventBatchInterval = time.Hour
+	sink := &fakeSink{}
+	a := &auditing{options: o, sinks: []Sink{sink}, eventCh: make(chan *Event, o.EventBufferSize)}
+
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		a.Run(stopCh)
+		close(done)
+	}()
+
+	for i := 0; i < 3; i++ {
+		a.LogResponseObject(&Event{RequestReceivedTimestamp: time.Now()}, http.StatusOK)
+	}
+	close(stopCh)
+	<-done
+
+	if len(sink.events) != 3 {
+		t.Errorf("expected 3 events, got %d", len(sink.events))
+	}
+	if !sink.closed {
+		t.Errorf("sink is not closed")
+	}
+}
@@ -0,0 +1,57 @@
+package auditing
+
+import (
+	"encoding/json"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Event is an audit record of a request served by captain-server
+type Event struct {
+	// AuditID is unique for every request
+	AuditID types.UID `json:"auditID"`
+
+	Level Level `json:"level"`
+
+	// RequestReceivedTimestamp is the time the request reached the server
+	RequestReceivedTimestamp time.Time `json:"requestReceivedTimestamp"`
+
+	// StageTimestamp is the time the response was completed
+	StageTimestamp time.Time `json:"stageTimestamp"`
+
+	// Latency is the time in milliseconds spent serving the request
+	Latency int64 `json:"latency"`
+
+	User   string   `json:"user,omitempty"`
+	UID    string   `json:"uid,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+
+	SourceIP  string `json:"sourceIP,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+
+	Verb       string `json:"verb"`
+	RequestURI string `json:"requestURI"`
+
+	Region      string `json:"region,omitempty"`
+	Cluster     string `json:"cluster,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	APIGroup    string `json:"apiGroup,omitempty"`
+	APIVersion  string `json:"apiVersion,omitempty"`
+	Resource    string `json:"resource,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+	Name        string `json:"name,omitempty"`
+
+	// ResponseCode is the http status code returned to client
+	ResponseCode int `json:"responseCode"`
+
+	// RequestObject is the object of the request body in JSON or YAML, only recorded in Request level.
+	// Values of data and stringData of secrets are redacted
+	RequestObject json.RawMessage `json:"requestObject,omitempty"`
+
+	// RequestBody is the request body neither in JSON nor in YAML, base64 encoded, only recorded in Request level
+	RequestBody []byte `json:"requestBody,omitempty"`
+
+	// RequestObjectTruncated indicates the request body is larger than MaxRequestBodySize and not recorded
+	RequestObjectTruncated bool `json:"requestObjectTruncated,omitempty"`
+}
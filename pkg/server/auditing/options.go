@@ -0,0 +1,124 @@
+package auditing
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type Level string
+
+const (
+	// LevelMetadata - log request metadata (user, source, verb, resource etc.) and response code, but not request body.
+	LevelMetadata Level = "Metadata"
+	// LevelRequest - log metadata and request body, request body larger than MaxRequestBodySize is truncated.
+	LevelRequest Level = "Request"
+)
+
+type Options struct {
+	Enable bool `json:"enable" yaml:"enable" mapstructure:"enable"`
+
+	// Level of audit events, one of Metadata and Request
+	Level Level `json:"level,omitempty" yaml:"level,omitempty" mapstructure:"level"`
+
+	// MaxRequestBodySize is the max bytes of request body recorded in Request level
+	MaxRequestBodySize int `json:"maxRequestBodySize,omitempty" yaml:"maxRequestBodySize,omitempty" mapstructure:"maxRequestBodySize"`
+
+	// EventBufferSize is the size of buffer holding events not yet sent to sinks,
+	// events are dropped when the buffer is full
+	EventBufferSize int `json:"eventBufferSize,omitempty" yaml:"eventBufferSize,omitempty" mapstructure:"eventBufferSize"`
+
+	// EventBatchSize is the max number of events sent to sinks at once
+	EventBatchSize int `json:"eventBatchSize,omitempty" yaml:"eventBatchSize,omitempty" mapstructure:"eventBatchSize"`
+
+	// EventBatchInterval is the max waiting time before sending events to sinks
+	EventBatchInterval time.Duration `json:"eventBatchInterval,omitempty" yaml:"eventBatchInterval,omitempty" mapstructure:"eventBatchInterval"`
+
+	LogOptions     *LogOptions     `json:"log,omitempty" yaml:"log,omitempty" mapstructure:"log"`
+	WebhookOptions *WebhookOptions `json:"webhook,omitempty" yaml:"webhook,omitempty" mapstructure:"webhook"`
+}
+
+// LogOptions configures the rotating json-lines file sink, sink is disabled if Path is empty
+type LogOptions struct {
+	Path string `json:"path,omitempty" yaml:"path,omitempty" mapstructure:"path"`
+
+	// MaxSize is the max size in megabytes of the log file before it gets rotated
+	MaxSize int `json:"maxSize,omitempty" yaml:"maxSize,omitempty" mapstructure:"maxSize"`
+
+	// MaxBackups is the max number of rotated files to retain
+	MaxBackups int `json:"maxBackups,omitempty" yaml:"maxBackups,omitempty" mapstructure:"maxBackups"`
+
+	// MaxAge is the max number of days to retain rotated files
+	MaxAge int `json:"maxAge,omitempty" yaml:"maxAge,omitempty" mapstructure:"maxAge"`
+}
+
+// WebhookOptions configures the http webhook sink, sink is disabled if URL is empty
+type WebhookOptions struct {
+	URL string `json:"url,omitempty" yaml:"url,omitempty" mapstructure:"url"`
+
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty" mapstructure:"timeout"`
+
+	// InsecureSkipVerify skips verifying the certificate of webhook server
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty" mapstructure:"insecureSkipVerify"`
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Enable:             false,
+		Level:              LevelMetadata,
+		MaxRequestBodySize: 64 * 1024,
+		EventBufferSize:    10000,
+		EventBatchSize:     100,
+		EventBatchInterval: 3 * time.Second,
+		LogOptions: &LogOptions{
+			MaxSize:    100,
+			MaxBackups: 10,
+			MaxAge:     7,
+		},
+		WebhookOptions: &WebhookOptions{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (o *Options) Validate() []error {
+	var errs []error
+
+	if !o.Enable {
+		return errs
+	}
+
+	if o.Level != LevelMetadata && o.Level != LevelRequest {
+		errs = append(errs, fmt.Errorf("audit level %s is not supported", o.Level))
+	}
+
+	if o.EventBufferSize <= 0 || o.EventBatchSize <= 0 || o.EventBatchInterval <= 0 {
+		errs = append(errs, fmt.Errorf("audit event buffer size, batch size and batch interval must be greater than zero"))
+	}
+
+	if o.LogOptions.Path == "" && o.WebhookOptions.URL == "" {
+		errs = append(errs, fmt.Errorf("at least one of audit log path and audit webhook url is required when auditing is enabled"))
+	}
+
+	return errs
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet, s *Options) {
+	fs.BoolVar(&o.Enable, "audit-enable", s.Enable, "Enable auditing of requests.")
+
+	fs.StringVar((*string)(&o.Level), "audit-level", string(s.Level), ""+
+		"Level of audit events, one of Metadata, Request. Request level records request body as well.")
+
+	fs.StringVar(&o.LogOptions.Path, "audit-log-path", s.LogOptions.Path, ""+
+		"If set, audit events are written to this file in json lines format.")
+	fs.IntVar(&o.LogOptions.MaxSize, "audit-log-maxsize", s.LogOptions.MaxSize, ""+
+		"The maximum size in megabytes of the audit log file before it gets rotated.")
+	fs.IntVar(&o.LogOptions.MaxBackups, "audit-log-maxbackup", s.LogOptions.MaxBackups, ""+
+		"The maximum number of old audit log files to retain.")
+	fs.IntVar(&o.LogOptions.MaxAge, "audit-log-maxage", s.LogOptions.MaxAge, ""+
+		"The maximum number of days to retain old audit log files.")
+
+	fs.StringVar(&o.WebhookOptions.URL, "audit-webhook-url", s.WebhookOptions.URL, ""+
+		"If set, audit events are posted to this url in batches.")
+}
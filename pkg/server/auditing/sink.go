@@ -0,0 +1,93 @@
+package auditing
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Sink persists audit events
+type Sink interface {
+	ProcessEvents(events ...*Event) error
+	Close() error
+}
+
+type logSink struct {
+	writer *lumberjack.Logger
+}
+
+// NewLogSink returns a Sink writing events to rotating files in json lines format
+func NewLogSink(o *LogOptions) Sink {
+	return &logSink{
+		writer: &lumberjack.Logger{
+			Filename:   o.Path,
+			MaxSize:    o.MaxSize,
+			MaxBackups: o.MaxBackups,
+			MaxAge:     o.MaxAge,
+		},
+	}
+}
+
+func (s *logSink) ProcessEvents(events ...*Event) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, e := range events {
+		if err := encoder.Encode(e); err != nil {
+			return err
+		}
+	}
+	_, err := s.writer.Write(buf.Bytes())
+	return err
+}
+
+func (s *logSink) Close() error {
+	return s.writer.Close()
+}
+
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink returns a Sink posting events to the webhook as a json array
+func NewWebhookSink(o *WebhookOptions) Sink {
+	return &webhookSink{
+		url: o.URL,
+		client: &http.Client{
+			Timeout: o.Timeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: o.InsecureSkipVerify},
+			},
+		},
+	}
+}
+
+func (s *webhookSink) ProcessEvents(events ...*Event) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("audit webhook %s responded with status %d", s.url, resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *webhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
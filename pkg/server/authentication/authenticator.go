@@ -0,0 +1,79 @@
+package authentication
+
+import (
+	"fmt"
+
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/request/anonymous"
+	"k8s.io/apiserver/pkg/authentication/request/bearertoken"
+	"k8s.io/apiserver/pkg/authentication/request/union"
+	"k8s.io/apiserver/pkg/authentication/request/websocket"
+	x509request "k8s.io/apiserver/pkg/authentication/request/x509"
+	"k8s.io/apiserver/pkg/authentication/token/tokenfile"
+	tokenunion "k8s.io/apiserver/pkg/authentication/token/union"
+	"k8s.io/apiserver/pkg/server/dynamiccertificates"
+
+	"captain/pkg/server/authentication/token"
+)
+
+// NewAuthenticator builds a request authenticator from options, authenticators are tried
+// in the order of client certificate, bearer token(static token file, jwt), and anonymous.
+// Client certificates are verified by clientCA, which should be created by NewClientCA.
+// Returns nil if no authenticator is configured.
+func NewAuthenticator(o *Options, clientCA dynamiccertificates.CAContentProvider) (authenticator.Request, error) {
+	if !o.Enabled() {
+		return nil, nil
+	}
+
+	var authenticators []authenticator.Request
+	var tokenAuthenticators []authenticator.Token
+
+	if clientCA != nil {
+		// verify options are read for every request, so rotated ca takes effect without restart
+		authenticators = append(authenticators, x509request.NewDynamic(clientCA.VerifyOptions, x509request.CommonNameUserConversion))
+	}
+
+	if o.StaticTokenFile != "" {
+		tokenAuth, err := tokenfile.NewCSV(o.StaticTokenFile)
+		if err != nil {
+			return nil, err
+		}
+		tokenAuthenticators = append(tokenAuthenticators, tokenAuth)
+	}
+
+	if o.JWTSecret != "" {
+		tokenAuthenticators = append(tokenAuthenticators, token.NewHMACAuthenticator(o.JWTSecret, o.JWTIssuer))
+	}
+
+	if o.JWTPublicKeyFile != "" {
+		tokenAuth, err := token.NewPublicKeyAuthenticator(o.JWTPublicKeyFile, o.JWTIssuer)
+		if err != nil {
+			return nil, err
+		}
+		tokenAuthenticators = append(tokenAuthenticators, tokenAuth)
+	}
+
+	if len(tokenAuthenticators) > 0 {
+		tokenAuth := tokenunion.New(tokenAuthenticators...)
+		authenticators = append(authenticators, bearertoken.New(tokenAuth), websocket.NewProtocolAuthenticator(tokenAuth))
+	}
+
+	if o.AnonymousAuth {
+		authenticators = append(authenticators, anonymous.NewAuthenticator())
+	}
+
+	return union.New(authenticators...), nil
+}
+
+// NewClientCA loads client ca file, the ca bundle is reloaded once the file changes after
+// the provider is started by secure server. Returns nil if client ca file is not set.
+func NewClientCA(o *Options) (dynamiccertificates.CAContentProvider, error) {
+	if o.ClientCAFile == "" {
+		return nil, nil
+	}
+	clientCA, err := dynamiccertificates.NewDynamicCAContentFromFile("client-ca", o.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client ca file %s: %v", o.ClientCAFile, err)
+	}
+	return clientCA, nil
+}
@@ -0,0 +1,87 @@
+package authentication
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+type Options struct {
+	// StaticTokenFile is a csv file of static bearer tokens, each line is formed as
+	// token,user,uid,"group1,group2,group3"
+	StaticTokenFile string `json:"staticTokenFile,omitempty" yaml:"staticTokenFile,omitempty" mapstructure:"staticTokenFile"`
+
+	// JWTSecret is the shared secret used to verify HMAC signed (HS256/HS384/HS512) jwt tokens
+	JWTSecret string `json:"jwtSecret,omitempty" yaml:"jwtSecret,omitempty" mapstructure:"jwtSecret"`
+
+	// JWTPublicKeyFile is a PEM encoded RSA or ECDSA public key used to verify
+	// RS256/RS384/RS512/ES256/ES384/ES512 signed jwt tokens
+	JWTPublicKeyFile string `json:"jwtPublicKeyFile,omitempty" yaml:"jwtPublicKeyFile,omitempty" mapstructure:"jwtPublicKeyFile"`
+
+	// JWTIssuer, if not empty, tokens issued by others will be rejected
+	JWTIssuer string `json:"jwtIssuer,omitempty" yaml:"jwtIssuer,omitempty" mapstructure:"jwtIssuer"`
+
+	// ClientCAFile is the CA bundle used to verify client certificates,
+	// the common name of the certificate is used as user name and organizations as groups
+	ClientCAFile string `json:"clientCAFile,omitempty" yaml:"clientCAFile,omitempty" mapstructure:"clientCAFile"`
+
+	// AnonymousAuth allows requests without any credential as system:anonymous
+	AnonymousAuth bool `json:"anonymousAuth" yaml:"anonymousAuth" mapstructure:"anonymousAuth"`
+}
+
+// NewOptions returns a default options, which means authentication is disabled
+func NewOptions() *Options {
+	return &Options{
+		AnonymousAuth: false,
+	}
+}
+
+// Enabled returns true if at least one authenticator is configured,
+// otherwise authentication filter will not be installed
+func (o *Options) Enabled() bool {
+	return o != nil && (o.StaticTokenFile != "" || o.JWTSecret != "" || o.JWTPublicKeyFile != "" ||
+		o.ClientCAFile != "" || o.AnonymousAuth)
+}
+
+func (o *Options) Validate() []error {
+	var errs []error
+
+	for _, file := range []string{o.StaticTokenFile, o.JWTPublicKeyFile, o.ClientCAFile} {
+		if file == "" {
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if o.JWTSecret != "" && o.JWTPublicKeyFile != "" {
+		errs = append(errs, fmt.Errorf("jwt secret and jwt public key file can not be set at the same time"))
+	}
+
+	return errs
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet, s *Options) {
+	fs.StringVar(&o.StaticTokenFile, "token-auth-file", s.StaticTokenFile, ""+
+		"If set, the file that will be used to secure the server via static bearer token authentication. "+
+		"Each line is formed as token,user,uid,\"group1,group2\".")
+
+	fs.StringVar(&o.JWTSecret, "jwt-secret", s.JWTSecret, ""+
+		"Secret used to verify HMAC signed jwt bearer tokens. Tokens without an exp claim are rejected.")
+
+	fs.StringVar(&o.JWTPublicKeyFile, "jwt-public-key-file", s.JWTPublicKeyFile, ""+
+		"PEM encoded RSA or ECDSA public key used to verify signed jwt bearer tokens. Tokens without an exp claim are rejected.")
+
+	fs.StringVar(&o.JWTIssuer, "jwt-issuer", s.JWTIssuer, ""+
+		"If set, jwt bearer tokens must carry an iss claim equal to this value.")
+
+	fs.StringVar(&o.ClientCAFile, "client-ca-file", s.ClientCAFile, ""+
+		"If set, any request presenting a client certificate signed by one of the authorities in the client-ca-file "+
+		"is authenticated with an identity corresponding to the CommonName of the client certificate.")
+
+	fs.BoolVar(&o.AnonymousAuth, "anonymous-auth", s.AnonymousAuth, ""+
+		"Enables anonymous requests to the server. Requests that are not rejected by another "+
+		"authentication method are treated as anonymous requests.")
+}
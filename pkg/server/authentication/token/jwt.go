@@ -0,0 +1,227 @@
+package token
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+var (
+	ErrTokenMalformed        = errors.New("token is malformed")
+	ErrTokenExpired          = errors.New("token is expired")
+	ErrTokenNoExpiration     = errors.New("token has no expiration")
+	ErrTokenNotValidYet      = errors.New("token is not valid yet")
+	ErrTokenInvalidIssuer    = errors.New("token has invalid issuer")
+	ErrTokenInvalidSignature = errors.New("token signature is invalid")
+	ErrAlgorithmNotSupported = errors.New("token signing algorithm is not supported")
+)
+
+// now is used to check token expiration, replaced in tests
+var now = time.Now
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+}
+
+// Claims holds the registered claims and user information carried by the token
+type Claims struct {
+	Subject   string              `json:"sub,omitempty"`
+	Username  string              `json:"username,omitempty"`
+	UID       string              `json:"uid,omitempty"`
+	Groups    []string            `json:"groups,omitempty"`
+	Extra     map[string][]string `json:"extra,omitempty"`
+	Issuer    string              `json:"iss,omitempty"`
+	ExpiresAt int64               `json:"exp,omitempty"`
+	NotBefore int64               `json:"nbf,omitempty"`
+	IssuedAt  int64               `json:"iat,omitempty"`
+}
+
+// jwtAuthenticator verifies jwt bearer tokens signed by a local key
+type jwtAuthenticator struct {
+	issuer    string
+	secret    []byte
+	publicKey crypto.PublicKey
+}
+
+// NewHMACAuthenticator returns a token authenticator verifies tokens signed with HS256/HS384/HS512
+func NewHMACAuthenticator(secret, issuer string) authenticator.Token {
+	return &jwtAuthenticator{secret: []byte(secret), issuer: issuer}
+}
+
+// NewPublicKeyAuthenticator returns a token authenticator verifies tokens signed with
+// RS256/RS384/RS512 or ES256/ES384/ES512, public key is read from given PEM file
+func NewPublicKeyAuthenticator(publicKeyFile, issuer string) (authenticator.Token, error) {
+	data, err := ioutil.ReadFile(publicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parsePublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key file %s: %v", publicKeyFile, err)
+	}
+	return &jwtAuthenticator{publicKey: key, issuer: issuer}, nil
+}
+
+func (j *jwtAuthenticator) AuthenticateToken(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		// not a jwt token, let other authenticators try
+		return nil, false, nil
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, false, nil
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, false, ErrTokenMalformed
+	}
+
+	if err = j.verify(h.Algorithm, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, false, err
+	}
+
+	var claims Claims
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, false, ErrTokenMalformed
+	}
+
+	if err = j.validate(&claims); err != nil {
+		return nil, false, err
+	}
+
+	name := claims.Username
+	if name == "" {
+		name = claims.Subject
+	}
+	if name == "" {
+		return nil, false, ErrTokenMalformed
+	}
+
+	return &authenticator.Response{
+		User: &user.DefaultInfo{
+			Name:   name,
+			UID:    claims.UID,
+			Groups: append(claims.Groups, user.AllAuthenticated),
+			Extra:  claims.Extra,
+		},
+	}, true, nil
+}
+
+// validate checks registered claims, tokens without exp are rejected, otherwise they would be valid forever
+func (j *jwtAuthenticator) validate(claims *Claims) error {
+	current := now().Unix()
+	if claims.ExpiresAt == 0 {
+		return ErrTokenNoExpiration
+	}
+	if current >= claims.ExpiresAt {
+		return ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && current < claims.NotBefore {
+		return ErrTokenNotValidYet
+	}
+	if j.issuer != "" && claims.Issuer != j.issuer {
+		return ErrTokenInvalidIssuer
+	}
+	return nil
+}
+
+func (j *jwtAuthenticator) verify(algorithm, signingString string, signature []byte) error {
+	if len(algorithm) != 5 {
+		return ErrAlgorithmNotSupported
+	}
+
+	var hash crypto.Hash
+	switch algorithm[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return ErrAlgorithmNotSupported
+	}
+
+	hasher := hash.New()
+	hasher.Write([]byte(signingString))
+
+	switch {
+	case strings.HasPrefix(algorithm, "HS") && len(j.secret) > 0:
+		mac := hmac.New(hash.New, j.secret)
+		mac.Write([]byte(signingString))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return ErrTokenInvalidSignature
+		}
+	case strings.HasPrefix(algorithm, "RS"):
+		key, ok := j.publicKey.(*rsa.PublicKey)
+		if !ok {
+			return ErrAlgorithmNotSupported
+		}
+		if err := rsa.VerifyPKCS1v15(key, hash, hasher.Sum(nil), signature); err != nil {
+			return ErrTokenInvalidSignature
+		}
+	case strings.HasPrefix(algorithm, "ES"):
+		key, ok := j.publicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return ErrAlgorithmNotSupported
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return ErrTokenInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, hasher.Sum(nil), r, s) {
+			return ErrTokenInvalidSignature
+		}
+	default:
+		return ErrAlgorithmNotSupported
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return cert.PublicKey, nil
+}
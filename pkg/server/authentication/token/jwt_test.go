@@ -0,0 +1,156 @@
+package token
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+)
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHMAC(t *testing.T, claims Claims, secret string) string {
+	signingString := encodeSegment(t, header{Algorithm: "HS256", Type: "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingString))
+	return signingString + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRSA(t *testing.T, claims Claims, key *rsa.PrivateKey) string {
+	signingString := encodeSegment(t, header{Algorithm: "RS256", Type: "JWT"}) + "." + encodeSegment(t, claims)
+	hashed := sha256.Sum256([]byte(signingString))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingString + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestHMACAuthenticator(t *testing.T) {
+	now = func() time.Time { return time.Unix(1000, 0) }
+	defer func() { now = time.Now }()
+
+	auth := NewHMACAuthenticator("secret", "captain")
+
+	tests := []struct {
+		description   string
+		token         string
+		expectedOK    bool
+		expectedError error
+		expectedUser  string
+	}{
+		{
+			description:  "valid token",
+			token:        signHMAC(t, Claims{Subject: "admin", Issuer: "captain", ExpiresAt: 2000, Groups: []string{"ops"}}, "secret"),
+			expectedOK:   true,
+			expectedUser: "admin",
+		},
+		{
+			description:  "username claim takes precedence over subject",
+			token:        signHMAC(t, Claims{Subject: "1", Username: "bob", Issuer: "captain", ExpiresAt: 2000}, "secret"),
+			expectedOK:   true,
+			expectedUser: "bob",
+		},
+		{
+			description:   "expired token",
+			token:         signHMAC(t, Claims{Subject: "admin", Issuer: "captain", ExpiresAt: 500}, "secret"),
+			expectedError: ErrTokenExpired,
+		},
+		{
+			description:   "token without expiration",
+			token:         signHMAC(t, Claims{Subject: "admin", Issuer: "captain"}, "secret"),
+			expectedError: ErrTokenNoExpiration,
+		},
+		{
+			description:   "not valid yet",
+			token:         signHMAC(t, Claims{Subject: "admin", Issuer: "captain", ExpiresAt: 2000, NotBefore: 1500}, "secret"),
+			expectedError: ErrTokenNotValidYet,
+		},
+		{
+			description:   "wrong issuer",
+			token:         signHMAC(t, Claims{Subject: "admin", Issuer: "someone", ExpiresAt: 2000}, "secret"),
+			expectedError: ErrTokenInvalidIssuer,
+		},
+		{
+			description:   "wrong secret",
+			token:         signHMAC(t, Claims{Subject: "admin", Issuer: "captain", ExpiresAt: 2000}, "another"),
+			expectedError: ErrTokenInvalidSignature,
+		},
+		{
+			description: "not a jwt token",
+			token:       "static-token",
+		},
+	}
+
+	for _, test := range tests {
+		resp, ok, err := auth.AuthenticateToken(context.Background(), test.token)
+		if err != test.expectedError {
+			t.Fatalf("%s: expected error %v, got %v", test.description, test.expectedError, err)
+		}
+		if ok != test.expectedOK {
+			t.Fatalf("%s: expected ok %v, got %v", test.description, test.expectedOK, ok)
+		}
+		if ok && resp.User.GetName() != test.expectedUser {
+			t.Fatalf("%s: expected user %s, got %s", test.description, test.expectedUser, resp.User.GetName())
+		}
+	}
+}
+
+func TestPublicKeyAuthenticator(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := ioutil.TempFile("", "jwt-public-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if err = pem.Encode(file, &pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	var auth authenticator.Token
+	auth, err = NewPublicKeyAuthenticator(file.Name(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, ok, err := auth.AuthenticateToken(context.Background(), signRSA(t, Claims{Subject: "admin", Groups: []string{"ops"}, ExpiresAt: time.Now().Add(time.Hour).Unix()}, key))
+	if err != nil || !ok {
+		t.Fatalf("expected token to be authenticated, got %v", err)
+	}
+	if resp.User.GetName() != "admin" {
+		t.Fatalf("expected user admin, got %s", resp.User.GetName())
+	}
+
+	// HMAC signed token must not be accepted by public key authenticator
+	_, ok, _ = auth.AuthenticateToken(context.Background(), signHMAC(t, Claims{Subject: "admin"}, "secret"))
+	if ok {
+		t.Fatal("expected HMAC signed token to be rejected")
+	}
+}
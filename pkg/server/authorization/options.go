@@ -0,0 +1,103 @@
+package authorization
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	"captain/pkg/server/authorization/authorizer"
+	"captain/pkg/server/authorization/rulebased"
+)
+
+const (
+	// ModeAlwaysAllow allows all requests
+	ModeAlwaysAllow = "AlwaysAllow"
+	// ModeRuleBased allows requests matched by the rules, denies the others
+	ModeRuleBased = "RuleBased"
+)
+
+type Options struct {
+	// Mode of the authorizer, one of AlwaysAllow, RuleBased. Rules are read from PolicyFile, Rules,
+	// and AuthorizationRule objects if CRDRules
+	Mode string `json:"mode" yaml:"mode" mapstructure:"mode"`
+
+	// PolicyFile is a yaml or json file holds rules, used in RuleBased mode
+	PolicyFile string `json:"policyFile,omitempty" yaml:"policyFile,omitempty" mapstructure:"policyFile"`
+
+	// Rules defined in configuration file, merged with rules in PolicyFile
+	Rules []rulebased.Rule `json:"rules,omitempty" yaml:"rules,omitempty" mapstructure:"rules"`
+
+	// CRDRules reads rules from AuthorizationRule objects of the host cluster as well, used in RuleBased mode.
+	// Changes of objects take effect without restarting
+	CRDRules bool `json:"crdRules,omitempty" yaml:"crdRules,omitempty" mapstructure:"crdRules"`
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Mode: ModeAlwaysAllow,
+	}
+}
+
+func (o *Options) Validate() []error {
+	var errs []error
+
+	switch o.Mode {
+	case "", ModeAlwaysAllow:
+	case ModeRuleBased:
+		if o.PolicyFile != "" {
+			if _, err := os.Stat(o.PolicyFile); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		for i, rule := range o.Rules {
+			if err := rule.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("invalid authorization rule %d: %v", i, err))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("authorization mode %s is not supported", o.Mode))
+	}
+
+	return errs
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet, s *Options) {
+	fs.StringVar(&o.Mode, "authorization-mode", s.Mode, ""+
+		"Authorization mode of the server, one of AlwaysAllow, RuleBased. Rules of RuleBased are read from "+
+		"the policy file, the configuration file, and AuthorizationRule objects if --authorization-crd-rules.")
+
+	fs.StringVar(&o.PolicyFile, "authorization-policy-file", s.PolicyFile, ""+
+		"File with authorization rules in yaml or json format, used in RuleBased mode.")
+
+	fs.BoolVar(&o.CRDRules, "authorization-crd-rules", s.CRDRules, ""+
+		"Read authorization rules from AuthorizationRule objects of iam.captain.io as well, used in RuleBased mode.")
+}
+
+// NewAuthorizer creates authorizer by the authorization mode, AuthorizationRule objects are read by the
+// client of config until stopCh is closed
+func NewAuthorizer(o *Options, config *rest.Config, stopCh <-chan struct{}) (authorizer.Authorizer, error) {
+	switch o.Mode {
+	case ModeRuleBased:
+		rules := append([]rulebased.Rule{}, o.Rules...)
+		if o.PolicyFile != "" {
+			fileRules, err := rulebased.LoadPolicyFile(o.PolicyFile)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, fileRules...)
+		}
+		if !o.CRDRules {
+			return rulebased.New(rules)
+		}
+		client, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		return rulebased.New(rules, rulebased.NewCRDRuleSource(client, stopCh))
+	default:
+		return authorizer.NewAlwaysAllowAuthorizer(), nil
+	}
+}
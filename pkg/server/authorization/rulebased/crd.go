@@ -0,0 +1,92 @@
+package rulebased
+
+import (
+	"sort"
+	"sync/atomic"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	iamv1alpha1 "captain/apis/iam/v1alpha1"
+)
+
+// AuthorizationRuleGVR is the resource of rules defined by CRDs
+var AuthorizationRuleGVR = iamv1alpha1.SchemeGroupVersion.WithResource(iamv1alpha1.ResourcesPluralAuthorizationRule)
+
+// crdRuleSource provides rules of AuthorizationRule objects, rules are rebuilt when objects change,
+// so that authorizing does not convert objects
+type crdRuleSource struct {
+	informer cache.SharedIndexInformer
+
+	// rules are the current rules sorted by names of objects
+	rules atomic.Value
+}
+
+// NewCRDRuleSource watches AuthorizationRule objects by client until stopCh is closed. There are no
+// rules until objects are synced, invalid objects are ignored
+func NewCRDRuleSource(client dynamic.Interface, stopCh <-chan struct{}) RuleSource {
+	s := &crdRuleSource{
+		informer: dynamicinformer.NewFilteredDynamicInformer(client, AuthorizationRuleGVR, metav1.NamespaceAll, 0, cache.Indexers{}, nil).Informer(),
+	}
+	s.rules.Store([]Rule(nil))
+	s.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			s.sync()
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			s.sync()
+		},
+		DeleteFunc: func(obj interface{}) {
+			s.sync()
+		},
+	})
+	go s.informer.Run(stopCh)
+	return s
+}
+
+func (s *crdRuleSource) Rules() []Rule {
+	return s.rules.Load().([]Rule)
+}
+
+func (s *crdRuleSource) sync() {
+	objects := s.informer.GetStore().List()
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].(metav1.Object).GetName() < objects[j].(metav1.Object).GetName()
+	})
+
+	rules := make([]Rule, 0, len(objects))
+	for _, obj := range objects {
+		rule, err := ruleOf(obj.(*unstructured.Unstructured))
+		if err != nil {
+			klog.Warningf("authorization rule %s is ignored, %v", obj.(metav1.Object).GetName(), err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	s.rules.Store(rules)
+}
+
+func ruleOf(obj *unstructured.Unstructured) (Rule, error) {
+	authorizationRule := &iamv1alpha1.AuthorizationRule{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, authorizationRule); err != nil {
+		return Rule{}, err
+	}
+	spec := authorizationRule.Spec
+	rule := Rule{
+		Users:           spec.Users,
+		Groups:          spec.Groups,
+		Regions:         spec.Regions,
+		Clusters:        spec.Clusters,
+		Namespaces:      spec.Namespaces,
+		APIGroups:       spec.APIGroups,
+		Resources:       spec.Resources,
+		Verbs:           spec.Verbs,
+		NonResourceURLs: spec.NonResourceURLs,
+	}
+	return rule, rule.Validate()
+}
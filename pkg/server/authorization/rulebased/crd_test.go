@@ -0,0 +1,85 @@
+package rulebased
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/authentication/user"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	iamv1alpha1 "captain/apis/iam/v1alpha1"
+	"captain/pkg/server/authorization/authorizer"
+)
+
+func newAuthorizationRule(name string, spec iamv1alpha1.AuthorizationRuleSpec) *unstructured.Unstructured {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&iamv1alpha1.AuthorizationRule{
+		TypeMeta:   metav1.TypeMeta{APIVersion: iamv1alpha1.SchemeGroupVersion.String(), Kind: iamv1alpha1.ResourceKindAuthorizationRule},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       spec,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func TestCRDRuleSource(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{AuthorizationRuleGVR: "AuthorizationRuleList"},
+		newAuthorizationRule("developers", iamv1alpha1.AuthorizationRuleSpec{
+			Groups:    []string{"developers"},
+			Clusters:  []string{"prod-*"},
+			Resources: []string{"pods"},
+			Verbs:     []string{"get", "list"},
+		}),
+		// rules without verbs are invalid
+		newAuthorizationRule("invalid", iamv1alpha1.AuthorizationRuleSpec{
+			Users:     []string{"alice"},
+			Resources: []string{"pods"},
+		}),
+	)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	source := NewCRDRuleSource(client, stopCh)
+
+	a, err := New(nil, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	developer := authorizer.AttributesRecord{
+		User:            &user.DefaultInfo{Name: "bob", Groups: []string{"developers"}},
+		Verb:            "list",
+		Cluster:         "prod-1",
+		Resource:        "pods",
+		ResourceRequest: true,
+	}
+	decisionOf := func(attributes authorizer.AttributesRecord) authorizer.Decision {
+		decision, _, _ := a.Authorize(context.Background(), attributes)
+		return decision
+	}
+
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return decisionOf(developer) == authorizer.DecisionAllow, nil
+	}); err != nil {
+		t.Fatalf("expected developers allowed by rules of objects, %v", err)
+	}
+	if rules := source.Rules(); len(rules) != 1 {
+		t.Errorf("expected invalid rules ignored, got %v", rules)
+	}
+
+	// deleted rules take effect without restarting
+	if err := client.Resource(AuthorizationRuleGVR).Delete(context.Background(), "developers", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return decisionOf(developer) == authorizer.DecisionDeny, nil
+	}); err != nil {
+		t.Errorf("expected developers denied after the rule is deleted, %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package rulebased
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+	"sigs.k8s.io/yaml"
+
+	"captain/pkg/server/authorization/authorizer"
+)
+
+const (
+	// All matches any value, e.g. verbs: ["*"]
+	All = "*"
+)
+
+// Rule grants the users and groups the verbs on resources in the given regions, clusters and namespaces.
+// Clusters left empty match the host cluster only, "*" matches the host and every member cluster.
+// Regions, namespaces and API groups left empty match everything, others must be set explicitly.
+// Values support "*" as wildcard and a trailing "*" as prefix match, e.g. clusters: ["prod-*"].
+type Rule struct {
+	Users  []string `json:"users,omitempty" yaml:"users,omitempty" mapstructure:"users"`
+	Groups []string `json:"groups,omitempty" yaml:"groups,omitempty" mapstructure:"groups"`
+
+	Regions    []string `json:"regions,omitempty" yaml:"regions,omitempty" mapstructure:"regions"`
+	Clusters   []string `json:"clusters,omitempty" yaml:"clusters,omitempty" mapstructure:"clusters"`
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty" mapstructure:"namespaces"`
+
+	APIGroups []string `json:"apiGroups,omitempty" yaml:"apiGroups,omitempty" mapstructure:"apiGroups"`
+	Resources []string `json:"resources,omitempty" yaml:"resources,omitempty" mapstructure:"resources"`
+	Verbs     []string `json:"verbs,omitempty" yaml:"verbs,omitempty" mapstructure:"verbs"`
+
+	// NonResourceURLs is a set of partial urls that a user should have access to, "*" is allowed as the final step.
+	NonResourceURLs []string `json:"nonResourceURLs,omitempty" yaml:"nonResourceURLs,omitempty" mapstructure:"nonResourceURLs"`
+}
+
+// Policy is the content of authorization policy file
+type Policy struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// RuleSource provides rules changing at runtime, e.g. rules defined by AuthorizationRule objects
+type RuleSource interface {
+	// Rules returns the current rules, which are valid and must not be modified
+	Rules() []Rule
+}
+
+type ruleBasedAuthorizer struct {
+	rules   []Rule
+	sources []RuleSource
+}
+
+// New returns an authorizer allows requests matched by any of the rules, or rules of sources, and denies the others
+func New(rules []Rule, sources ...RuleSource) (authorizer.Authorizer, error) {
+	for i, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid authorization rule %d: %v", i, err)
+		}
+	}
+	return &ruleBasedAuthorizer{rules: rules, sources: sources}, nil
+}
+
+// LoadPolicyFile reads rules from a yaml or json policy file
+func LoadPolicyFile(file string) ([]Rule, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	policy := &Policy{}
+	if err = yaml.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse authorization policy file %s: %v", file, err)
+	}
+	return policy.Rules, nil
+}
+
+func (r Rule) Validate() error {
+	if len(r.Users)+len(r.Groups) == 0 {
+		return fmt.Errorf("at least one of users and groups is required")
+	}
+	if len(r.Verbs) == 0 {
+		return fmt.Errorf("verbs is required")
+	}
+	if len(r.Resources)+len(r.NonResourceURLs) == 0 {
+		return fmt.Errorf("at least one of resources and nonResourceURLs is required")
+	}
+	return nil
+}
+
+func (r *ruleBasedAuthorizer) Authorize(ctx context.Context, a authorizer.Attributes) (authorizer.Decision, string, error) {
+	if anyRuleAllows(r.rules, a) {
+		return authorizer.DecisionAllow, "", nil
+	}
+	for _, source := range r.sources {
+		if anyRuleAllows(source.Rules(), a) {
+			return authorizer.DecisionAllow, "", nil
+		}
+	}
+
+	reason := fmt.Sprintf("user %q cannot %s", username(a.GetUser()), a.GetVerb())
+	if a.IsResourceRequest() {
+		reason = fmt.Sprintf("%s resource %q in namespace %q of cluster %q", reason, a.GetResource(), a.GetNamespace(), a.GetCluster())
+	} else {
+		reason = fmt.Sprintf("%s path %q", reason, a.GetPath())
+	}
+	return authorizer.DecisionDeny, reason, nil
+}
+
+func anyRuleAllows(rules []Rule, a authorizer.Attributes) bool {
+	for i := range rules {
+		if ruleAllows(&rules[i], a) {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleAllows(rule *Rule, a authorizer.Attributes) bool {
+	if !subjectMatches(rule, a.GetUser()) || !matches(rule.Verbs, a.GetVerb()) {
+		return false
+	}
+
+	if !a.IsResourceRequest() {
+		return matches(rule.NonResourceURLs, a.GetPath())
+	}
+
+	// subresources are matched in the form of pods/log
+	resource := a.GetResource()
+	if a.GetSubresource() != "" {
+		resource = resource + "/" + a.GetSubresource()
+	}
+
+	return emptyOrMatches(rule.Regions, a.GetRegion()) &&
+		clusterMatches(rule.Clusters, a.GetCluster()) &&
+		emptyOrMatches(rule.Namespaces, a.GetNamespace()) &&
+		emptyOrMatches(rule.APIGroups, a.GetAPIGroup()) &&
+		matches(rule.Resources, resource)
+}
+
+func subjectMatches(rule *Rule, u user.Info) bool {
+	if u == nil {
+		u = &user.DefaultInfo{Name: user.Anonymous, Groups: []string{user.AllUnauthenticated}}
+	}
+	if matches(rule.Users, u.GetName()) {
+		return true
+	}
+	for _, group := range u.GetGroups() {
+		if matches(rule.Groups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterMatches returns true if cluster is matched by patterns, the host cluster is the empty cluster,
+// so that rules without clusters do not grant anything of member clusters
+func clusterMatches(patterns []string, cluster string) bool {
+	if len(patterns) == 0 {
+		return len(cluster) == 0
+	}
+	return matches(patterns, cluster)
+}
+
+func emptyOrMatches(patterns []string, value string) bool {
+	return len(patterns) == 0 || matches(patterns, value)
+}
+
+func matches(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == All || pattern == value {
+			return true
+		}
+		if strings.HasSuffix(pattern, All) && strings.HasPrefix(value, strings.TrimSuffix(pattern, All)) {
+			return true
+		}
+	}
+	return false
+}
+
+func username(u user.Info) string {
+	if u == nil {
+		return user.Anonymous
+	}
+	return u.GetName()
+}
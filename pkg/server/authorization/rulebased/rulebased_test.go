@@ -0,0 +1,136 @@
+package rulebased
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"captain/pkg/server/authorization/authorizer"
+)
+
+func TestRuleBasedAuthorizer(t *testing.T) {
+	rules := []Rule{
+		{
+			Groups:    []string{"developers"},
+			Clusters:  []string{"prod-*"},
+			Resources: []string{"*"},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+		{
+			Groups:    []string{"developers"},
+			Clusters:  []string{"staging"},
+			Resources: []string{"*"},
+			Verbs:     []string{"*"},
+		},
+		{
+			Users:      []string{"alice"},
+			Namespaces: []string{"ops"},
+			Resources:  []string{"pods", "pods/log"},
+			Verbs:      []string{"get"},
+		},
+		{
+			Users:     []string{"operator"},
+			Clusters:  []string{All},
+			Resources: []string{"*"},
+			Verbs:     []string{"*"},
+		},
+		{
+			Groups:          []string{user.AllUnauthenticated},
+			NonResourceURLs: []string{"/version"},
+			Verbs:           []string{"get"},
+		},
+	}
+
+	developer := &user.DefaultInfo{Name: "bob", Groups: []string{"developers"}}
+	alice := &user.DefaultInfo{Name: "alice"}
+	operator := &user.DefaultInfo{Name: "operator"}
+
+	tests := []struct {
+		description string
+		attributes  authorizer.AttributesRecord
+		expected    authorizer.Decision
+	}{
+		{
+			description: "developer lists pods in prod cluster",
+			attributes:  authorizer.AttributesRecord{User: developer, Verb: "list", Cluster: "prod-1", Resource: "pods", ResourceRequest: true},
+			expected:    authorizer.DecisionAllow,
+		},
+		{
+			description: "developer deletes pod in prod cluster",
+			attributes:  authorizer.AttributesRecord{User: developer, Verb: "delete", Cluster: "prod-1", Resource: "pods", ResourceRequest: true},
+			expected:    authorizer.DecisionDeny,
+		},
+		{
+			description: "developer deletes pod in staging cluster",
+			attributes:  authorizer.AttributesRecord{User: developer, Verb: "delete", Cluster: "staging", Resource: "pods", ResourceRequest: true},
+			expected:    authorizer.DecisionAllow,
+		},
+		{
+			description: "alice reads pod logs in ops namespace",
+			attributes:  authorizer.AttributesRecord{User: alice, Verb: "get", Namespace: "ops", Resource: "pods", Subresource: "log", ResourceRequest: true},
+			expected:    authorizer.DecisionAllow,
+		},
+		{
+			description: "alice execs into pod in ops namespace",
+			attributes:  authorizer.AttributesRecord{User: alice, Verb: "get", Namespace: "ops", Resource: "pods", Subresource: "exec", ResourceRequest: true},
+			expected:    authorizer.DecisionDeny,
+		},
+		{
+			description: "alice reads pods in default namespace",
+			attributes:  authorizer.AttributesRecord{User: alice, Verb: "get", Namespace: "default", Resource: "pods", ResourceRequest: true},
+			expected:    authorizer.DecisionDeny,
+		},
+		{
+			description: "alice reads pods in ops namespace of member cluster",
+			attributes:  authorizer.AttributesRecord{User: alice, Verb: "get", Cluster: "prod-1", Namespace: "ops", Resource: "pods", ResourceRequest: true},
+			expected:    authorizer.DecisionDeny,
+		},
+		{
+			description: "operator deletes pods in member cluster of region",
+			attributes:  authorizer.AttributesRecord{User: operator, Verb: "delete", Region: "middle-earth", Cluster: "gondor", Resource: "pods", ResourceRequest: true},
+			expected:    authorizer.DecisionAllow,
+		},
+		{
+			description: "operator deletes pods in host cluster",
+			attributes:  authorizer.AttributesRecord{User: operator, Verb: "delete", Resource: "pods", ResourceRequest: true},
+			expected:    authorizer.DecisionAllow,
+		},
+		{
+			description: "developer lists pods in host cluster",
+			attributes:  authorizer.AttributesRecord{User: developer, Verb: "list", Resource: "pods", ResourceRequest: true},
+			expected:    authorizer.DecisionDeny,
+		},
+		{
+			description: "anonymous reads version",
+			attributes:  authorizer.AttributesRecord{Verb: "get", Path: "/version"},
+			expected:    authorizer.DecisionAllow,
+		},
+		{
+			description: "anonymous lists pods",
+			attributes:  authorizer.AttributesRecord{Verb: "list", Resource: "pods", ResourceRequest: true},
+			expected:    authorizer.DecisionDeny,
+		},
+	}
+
+	a, err := New(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		decision, reason, err := a.Authorize(context.Background(), test.attributes)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.description, err)
+		}
+		if decision != test.expected {
+			t.Errorf("%s: expected decision %v, got %v, reason: %s", test.description, test.expected, decision, reason)
+		}
+	}
+}
+
+func TestInvalidRule(t *testing.T) {
+	if _, err := New([]Rule{{Resources: []string{"pods"}, Verbs: []string{"get"}}}); err == nil {
+		t.Fatal("expected rule without subject to be rejected")
+	}
+}
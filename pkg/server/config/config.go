@@ -12,7 +12,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog"
 
+	"captain/pkg/bussiness/kube-resources/alpha1/search"
 	"captain/pkg/constants"
+	"captain/pkg/server/auditing"
+	"captain/pkg/server/authentication"
+	"captain/pkg/server/authorization"
+	"captain/pkg/server/impersonation"
+	"captain/pkg/server/logging"
+	"captain/pkg/server/tracing"
 	"captain/pkg/simple/client/cache"
 	"captain/pkg/simple/client/k8s"
 	"captain/pkg/simple/client/multicluster"
@@ -109,6 +116,14 @@ type Config struct {
 	KubernetesOptions   *k8s.KubernetesOptions `json:"kubernetes,omitempty" yaml:"kubernetes,omitempty" mapstructure:"kubernetes"`
 	RedisOptions        *cache.Options         `json:"redis,omitempty" yaml:"redis,omitempty" mapstructure:"redis"`
 	MultiClusterOptions *multicluster.Options  `json:"multicluster,omitempty" yaml:"multicluster,omitempty" mapstructure:"multicluster"`
+
+	AuthenticationOptions *authentication.Options `json:"authentication,omitempty" yaml:"authentication,omitempty" mapstructure:"authentication"`
+	AuthorizationOptions  *authorization.Options  `json:"authorization,omitempty" yaml:"authorization,omitempty" mapstructure:"authorization"`
+	AuditingOptions       *auditing.Options       `json:"auditing,omitempty" yaml:"auditing,omitempty" mapstructure:"auditing"`
+	ImpersonationOptions  *impersonation.Options  `json:"impersonation,omitempty" yaml:"impersonation,omitempty" mapstructure:"impersonation"`
+	LoggingOptions        *logging.Options        `json:"logging,omitempty" yaml:"logging,omitempty" mapstructure:"logging"`
+	TracingOptions        *tracing.Options        `json:"tracing,omitempty" yaml:"tracing,omitempty" mapstructure:"tracing"`
+	SearchOptions         *search.Options         `json:"search,omitempty" yaml:"search,omitempty" mapstructure:"search"`
 }
 
 // newConfig creates a default non-empty Config
@@ -117,6 +132,14 @@ func New() *Config {
 		KubernetesOptions:   k8s.NewKubernetesOptions(),
 		RedisOptions:        cache.NewRedisOptions(),
 		MultiClusterOptions: multicluster.NewOptions(),
+
+		AuthenticationOptions: authentication.NewOptions(),
+		AuthorizationOptions:  authorization.NewOptions(),
+		AuditingOptions:       auditing.NewOptions(),
+		ImpersonationOptions:  impersonation.NewOptions(),
+		LoggingOptions:        logging.NewOptions(),
+		TracingOptions:        tracing.NewOptions(),
+		SearchOptions:         search.NewOptions(),
 	}
 }
 
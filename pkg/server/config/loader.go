@@ -0,0 +1,289 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog"
+
+	"captain/pkg/constants"
+)
+
+const (
+	// DefaultConfigMapNamespace is the namespace captain is deployed in
+	DefaultConfigMapNamespace = "captain-system"
+
+	// ReasonInvalidConfiguration is the reason of events recorded for ConfigMaps with invalid configuration
+	ReasonInvalidConfiguration = "InvalidConfiguration"
+)
+
+// ConfigMapOptions locates the ConfigMap configuration is loaded from, in addition to configuration file
+type ConfigMapOptions struct {
+	Namespace string
+	Name      string
+}
+
+func NewConfigMapOptions() *ConfigMapOptions {
+	return &ConfigMapOptions{
+		Namespace: DefaultConfigMapNamespace,
+		Name:      "",
+	}
+}
+
+// Enabled returns whether configuration is loaded from ConfigMap
+func (o *ConfigMapOptions) Enabled() bool {
+	return len(o.Name) != 0
+}
+
+func (o *ConfigMapOptions) Validate() []error {
+	var errs []error
+	if o.Enabled() && len(o.Namespace) == 0 {
+		errs = append(errs, fmt.Errorf("--config-map-namespace must be specified with --config-map-name"))
+	}
+	return errs
+}
+
+func (o *ConfigMapOptions) AddFlags(fs *pflag.FlagSet, s *ConfigMapOptions) {
+	fs.StringVar(&o.Name, "config-map-name", s.Name, ""+
+		"Name of the ConfigMap to load configuration from, the ConfigMap is watched and its changes are applied at runtime. "+
+		"Configuration in ConfigMap takes precedence over configuration file, command line flags take precedence over both. "+
+		"Leave it empty to load configuration from configuration file only.")
+	fs.StringVar(&o.Namespace, "config-map-namespace", s.Namespace, "Namespace of the ConfigMap to load configuration from.")
+}
+
+// Loader loads configuration merged from command line flags, ConfigMap and configuration file, in the order
+// of precedence. ConfigMap and configuration file are watched, merged configuration is validated on every
+// change, invalid configuration is rejected and the last valid configuration is kept in use
+type Loader struct {
+	client  kubernetes.Interface
+	options *ConfigMapOptions
+
+	// overrideFlags sets options specified in command line to the configuration
+	overrideFlags func(conf *Config) error
+
+	// validate validates merged configuration
+	validate func(conf *Config) []error
+
+	// recorder records events of rejected ConfigMaps, nil if ConfigMap is not enabled
+	recorder record.EventRecorder
+
+	mu sync.Mutex
+	// data is the configuration of the last valid ConfigMap, empty if ConfigMap is not used or not found
+	data string
+	// current is the configuration in use
+	current *Config
+}
+
+// NewLoader creates a configuration loader for component, client is used to watch ConfigMap and can be nil
+// if ConfigMap is not enabled by options
+func NewLoader(client kubernetes.Interface, options *ConfigMapOptions, component string,
+	overrideFlags func(conf *Config) error, validate func(conf *Config) []error) *Loader {
+	l := &Loader{
+		client:        client,
+		options:       options,
+		overrideFlags: overrideFlags,
+		validate:      validate,
+	}
+
+	if client != nil && options.Enabled() {
+		broadcaster := record.NewBroadcaster()
+		broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events(options.Namespace)})
+		l.recorder = broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: component})
+	}
+
+	return l
+}
+
+// Load loads configuration at startup. Absence of ConfigMap is not an error, it is applied once created,
+// while invalid configuration at startup fails the loading
+func (l *Loader) Load() (*Config, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.client != nil && l.options.Enabled() {
+		cm, err := l.client.CoreV1().ConfigMaps(l.options.Namespace).Get(context.TODO(), l.options.Name, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get configmap %s/%s: %v", l.options.Namespace, l.options.Name, err)
+		}
+		if apierrors.IsNotFound(err) {
+			klog.Warningf("Configmap %s/%s not found, load configuration from configuration file", l.options.Namespace, l.options.Name)
+		} else {
+			data, ok := cm.Data[constants.CaptainConfigMapDataKey]
+			if !ok {
+				return nil, fmt.Errorf("configmap %s/%s has no key %s", l.options.Namespace, l.options.Name, constants.CaptainConfigMapDataKey)
+			}
+			l.data = data
+		}
+	}
+
+	conf, err := l.merge(l.data)
+	if err != nil {
+		return nil, err
+	}
+	l.current = conf
+	return conf, nil
+}
+
+// Watch watches configuration file and ConfigMap, merged configuration is sent to the returned channel once
+// it changes and is valid. Load must be called before Watch
+func (l *Loader) Watch(stopCh <-chan struct{}) <-chan Config {
+	ch := make(chan Config)
+
+	fileCh := WatchConfigChange()
+	go func() {
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-fileCh:
+				l.reload(nil, ch, stopCh)
+			}
+		}
+	}()
+
+	if l.client != nil && l.options.Enabled() {
+		factory := informers.NewSharedInformerFactoryWithOptions(l.client, 0,
+			informers.WithNamespace(l.options.Namespace),
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.FieldSelector = fields.OneTermEqualSelector("metadata.name", l.options.Name).String()
+			}))
+		factory.Core().V1().ConfigMaps().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				l.reload(obj.(*corev1.ConfigMap), ch, stopCh)
+			},
+			UpdateFunc: func(_, obj interface{}) {
+				l.reload(obj.(*corev1.ConfigMap), ch, stopCh)
+			},
+			DeleteFunc: func(_ interface{}) {
+				klog.Warningf("Configmap %s/%s is deleted, keep using the current configuration", l.options.Namespace, l.options.Name)
+			},
+		})
+		factory.Start(stopCh)
+	}
+
+	return ch
+}
+
+// reload merges configuration with ConfigMap cm, or with the last valid ConfigMap if cm is nil,
+// and sends it to ch if it's valid and differs from the current one
+func (l *Loader) reload(cm *corev1.ConfigMap, ch chan<- Config, stopCh <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	data := l.data
+	if cm != nil {
+		var ok bool
+		if data, ok = cm.Data[constants.CaptainConfigMapDataKey]; !ok {
+			l.reject(cm, fmt.Errorf("key %s not found", constants.CaptainConfigMapDataKey))
+			return
+		}
+	}
+
+	conf, err := l.merge(data)
+	if err != nil {
+		if cm != nil {
+			l.reject(cm, err)
+		} else {
+			klog.Errorf("Invalid configuration, keep using the current configuration: %v", err)
+		}
+		return
+	}
+
+	l.data = data
+	if reflect.DeepEqual(conf, l.current) {
+		return
+	}
+	l.current = conf
+
+	select {
+	case ch <- *conf:
+	case <-stopCh:
+	}
+}
+
+// reject records invalid configuration of cm as an event
+func (l *Loader) reject(cm *corev1.ConfigMap, err error) {
+	klog.Errorf("Invalid configuration in configmap %s/%s, keep using the current configuration: %v", cm.Namespace, cm.Name, err)
+	if l.recorder == nil {
+		return
+	}
+	l.recorder.Eventf(cm, corev1.EventTypeWarning, ReasonInvalidConfiguration,
+		"Invalid configuration is rejected, the last valid configuration is kept in use: %v", err)
+}
+
+// merge loads configuration file, overrides it with ConfigMap data and command line flags, and validates the result
+func (l *Loader) merge(data string) (*Config, error) {
+	conf := New()
+	if err := viper.Unmarshal(conf); err != nil {
+		return nil, fmt.Errorf("failed to load configuration file: %v", err)
+	}
+
+	if len(data) != 0 {
+		// fields missing in ConfigMap are kept as in configuration file
+		if err := yaml.Unmarshal([]byte(data), conf); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal configuration: %v", err)
+		}
+		// options set to null in ConfigMap fall back to defaults
+		defaults := reflect.ValueOf(New()).Elem()
+		c := reflect.ValueOf(conf).Elem()
+		for i := 0; i < c.NumField(); i++ {
+			if c.Field(i).IsNil() {
+				c.Field(i).Set(defaults.Field(i))
+			}
+		}
+	}
+
+	if l.overrideFlags != nil {
+		if err := l.overrideFlags(conf); err != nil {
+			return nil, err
+		}
+	}
+
+	if l.validate != nil {
+		if errs := l.validate(conf); len(errs) != 0 {
+			return nil, utilerrors.NewAggregate(errs)
+		}
+	}
+
+	return conf, nil
+}
+
+// OverrideChangedFlags sets flags changed in command line flag set fs to target, target is a flag set
+// whose flags are bound to the configuration to be overridden
+func OverrideChangedFlags(fs *pflag.FlagSet, target *pflag.FlagSet) error {
+	var errs []error
+	fs.Visit(func(f *pflag.Flag) {
+		t := target.Lookup(f.Name)
+		if t == nil {
+			return
+		}
+		if from, ok := f.Value.(pflag.SliceValue); ok {
+			if to, ok := t.Value.(pflag.SliceValue); ok {
+				if err := to.Replace(from.GetSlice()); err != nil {
+					errs = append(errs, fmt.Errorf("invalid flag %s: %v", f.Name, err))
+				}
+				return
+			}
+		}
+		if err := t.Value.Set(f.Value.String()); err != nil {
+			errs = append(errs, fmt.Errorf("invalid flag %s: %v", f.Name, err))
+		}
+	})
+	return utilerrors.NewAggregate(errs)
+}
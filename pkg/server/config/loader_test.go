@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	"captain/pkg/constants"
+)
+
+func newTestConfigMap(data string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: DefaultConfigMapNamespace, Name: "captain-config"},
+		Data:       map[string]string{constants.CaptainConfigMapDataKey: data},
+	}
+}
+
+func TestLoaderPrecedence(t *testing.T) {
+	cm := newTestConfigMap(`
+redis:
+  host: redis.configmap
+  port: 6379
+multicluster:
+  enable: true
+`)
+
+	// flags parsed from command line
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	New().RedisOptions.AddFlags(fs, New().RedisOptions)
+	if err := fs.Parse([]string{"--redis-host=redis.flag"}); err != nil {
+		t.Fatal(err)
+	}
+
+	overrideFlags := func(conf *Config) error {
+		target := pflag.NewFlagSet("target", pflag.ContinueOnError)
+		conf.RedisOptions.AddFlags(target, conf.RedisOptions)
+		return OverrideChangedFlags(fs, target)
+	}
+	validate := func(conf *Config) []error {
+		if conf.RedisOptions.Port == 0 {
+			return []error{fmt.Errorf("invalid redis port")}
+		}
+		return nil
+	}
+
+	options := &ConfigMapOptions{Namespace: DefaultConfigMapNamespace, Name: "captain-config"}
+	l := NewLoader(fake.NewSimpleClientset(cm), options, "test", overrideFlags, validate)
+	recorder := record.NewFakeRecorder(10)
+	l.recorder = recorder
+
+	conf, err := l.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.RedisOptions.Host != "redis.flag" {
+		t.Errorf("expected redis host from flags, got %s", conf.RedisOptions.Host)
+	}
+	if conf.RedisOptions.Port != 6379 || !conf.MultiClusterOptions.Enable {
+		t.Errorf("expected options from configmap, got %+v %+v", conf.RedisOptions, conf.MultiClusterOptions)
+	}
+
+	ch := make(chan Config, 1)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	// invalid configuration is rejected with an event
+	l.reload(newTestConfigMap("redis:\n  port: 0\n"), ch, stopCh)
+	if len(ch) != 0 {
+		t.Errorf("expected invalid configuration rejected")
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, ReasonInvalidConfiguration) {
+			t.Errorf("unexpected event %s", event)
+		}
+	default:
+		t.Errorf("expected event recorded for invalid configuration")
+	}
+
+	// valid change is sent
+	l.reload(newTestConfigMap("redis:\n  port: 6380\n"), ch, stopCh)
+	select {
+	case conf := <-ch:
+		if conf.RedisOptions.Port != 6380 || conf.RedisOptions.Host != "redis.flag" || conf.MultiClusterOptions.Enable {
+			t.Errorf("unexpected configuration %+v %+v", conf.RedisOptions, conf.MultiClusterOptions)
+		}
+	default:
+		t.Errorf("expected configuration change sent")
+	}
+
+	// unchanged configuration is not sent again
+	l.reload(newTestConfigMap("redis:\n  port: 6380\n"), ch, stopCh)
+	if len(ch) != 0 {
+		t.Errorf("expected unchanged configuration not sent")
+	}
+}
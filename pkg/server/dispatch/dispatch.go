@@ -4,12 +4,17 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	clusterv1alpha1 "captain/apis/cluster/v1alpha1"
-	clusterinformer "captain/pkg/client/informers/externalversions/cluster/v1alpha1"
+	"captain/pkg/server/impersonation"
+	"captain/pkg/server/metrics"
 	"captain/pkg/server/request"
+	"captain/pkg/server/tracing"
 	"captain/pkg/utils/clusterclient"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/httpstream"
 	"k8s.io/apimachinery/pkg/util/proxy"
@@ -29,16 +34,28 @@ type Dispatcher interface {
 
 type clusterDispatch struct {
 	clusterclient.ClusterClients
+
+	// impersonator forwards the requesting user to member clusters, nil means requests
+	// are sent with the identity of cluster kubeconfig
+	impersonator *impersonation.Impersonator
 }
 
-func NewClusterDispatch(clusterInformer clusterinformer.ClusterInformer) Dispatcher {
-	return &clusterDispatch{clusterclient.NewClusterClients(clusterInformer)}
+func NewClusterDispatch(clients clusterclient.ClusterClients, impersonator *impersonation.Impersonator) Dispatcher {
+	return &clusterDispatch{
+		ClusterClients: clients,
+		impersonator:   impersonator,
+	}
 }
 
 // Dispatch dispatch requests to designated cluster
 func (c *clusterDispatch) Dispatch(w http.ResponseWriter, req *http.Request, handler http.Handler) {
 	info, _ := request.RequestInfoFrom(req.Context())
 
+	ctx, span := tracing.Tracer().Start(req.Context(), "Dispatch",
+		trace.WithAttributes(tracing.RegionKey.String(info.Region), tracing.ClusterKey.String(info.Cluster)))
+	defer span.End()
+	req = req.WithContext(ctx)
+
 	if len(info.Cluster) == 0 {
 		klog.Warningf("Request with empty cluster, %v", req.URL)
 		http.Error(w, "Bad request, empty cluster", http.StatusBadRequest)
@@ -47,9 +64,13 @@ func (c *clusterDispatch) Dispatch(w http.ResponseWriter, req *http.Request, han
 
 	cluster, err := c.Get(info.Region, info.Cluster)
 	if err != nil {
+		tracing.SetError(span, err)
 		if errors.IsNotFound(err) {
+			// names of clusters not found are taken from URLs, so they are not recorded
+			metrics.RecordDispatchError(metrics.OtherLabel, metrics.OtherLabel, "cluster_not_found")
 			http.Error(w, fmt.Sprintf("cluster %s not found", info.Cluster), http.StatusNotFound)
 		} else {
+			metrics.RecordDispatchError(info.Region, info.Cluster, "cluster_error")
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
 		return
@@ -69,6 +90,8 @@ func (c *clusterDispatch) Dispatch(w http.ResponseWriter, req *http.Request, han
 
 	innCluster := c.GetInnerCluster(cluster.Name)
 	if innCluster == nil {
+		metrics.RecordDispatchError(info.Region, info.Cluster, "cluster_not_ready")
+		tracing.SetError(span, fmt.Errorf("cluster %s is not ready", cluster.Name))
 		http.Error(w, fmt.Sprintf("cluster %s is not ready", cluster.Name), http.StatusBadRequest)
 		return
 	}
@@ -104,6 +127,11 @@ func (c *clusterDispatch) Dispatch(w http.ResponseWriter, req *http.Request, han
 		// https://github.com/kubernetes/client-go/blob/master/transport/round_trippers.go#L285
 		req.Header.Del("Authorization")
 
+		// Requests are sent with the cluster kubeconfig, which is usually cluster-admin, so forward
+		// the requesting user to make RBAC and audit of member cluster work on the real user.
+		// Requests sent to member captain-apiserver are impersonated by itself after authentication.
+		c.impersonator.Impersonate(req)
+
 		// Dirty trick again. The kube-apiserver apiserver proxy rejects all proxy requests with dryRun parameter
 		// https://github.com/kubernetes/kubernetes/pull/66083
 		// Really don't understand why they do this. And here we are, bypass with replacing 'dryRun'
@@ -126,11 +154,42 @@ func (c *clusterDispatch) Dispatch(w http.ResponseWriter, req *http.Request, han
 		u.Scheme = innCluster.CaptainURL.Scheme
 	}
 
-	httpProxy := proxy.NewUpgradeAwareHandler(&u, transport, false, false, c)
+	// continue the trace on member cluster, the span of member captain-apiserver or kube-apiserver
+	// is a child of the dispatch span
+	tracing.Inject(ctx, req.Header)
+
+	if httpstream.IsUpgradeRequest(req) {
+		// upgraded sessions last until either side closes the connection
+		defer metrics.TrackUpgradeSession(info.Region, info.Cluster)()
+	}
+
+	httpProxy := proxy.NewUpgradeAwareHandler(&u, &instrumentedRoundTripper{delegate: transport, region: info.Region, cluster: info.Cluster}, false, false, c)
 	httpProxy.UpgradeTransport = proxy.NewUpgradeRequestRoundTripper(transport, transport)
 	httpProxy.ServeHTTP(w, req)
 }
 
 func (c *clusterDispatch) Error(w http.ResponseWriter, req *http.Request, err error) {
+	if info, ok := request.RequestInfoFrom(req.Context()); ok {
+		metrics.RecordDispatchError(info.Region, info.Cluster, "upstream_error")
+	}
+	tracing.SetError(trace.SpanFromContext(req.Context()), err)
 	responsewriters.InternalError(w, req, err)
 }
+
+// instrumentedRoundTripper records response code and latency of requests sent to member clusters
+type instrumentedRoundTripper struct {
+	delegate http.RoundTripper
+	region   string
+	cluster  string
+}
+
+func (rt *instrumentedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := rt.delegate.RoundTrip(req)
+	if err != nil {
+		// upstream errors are recorded by clusterDispatch.Error
+		return nil, err
+	}
+	metrics.RecordDispatch(rt.region, rt.cluster, resp.StatusCode, time.Since(start))
+	return resp, nil
+}
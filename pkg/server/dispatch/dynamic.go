@@ -0,0 +1,36 @@
+package dispatch
+
+import (
+	"net/http"
+	"sync"
+)
+
+// DynamicDispatcher delegates to a Dispatcher which can be replaced at runtime, e.g. when multicluster
+// mode is toggled by configuration reloading. Requests are passed to the next handler untouched while
+// no dispatcher is set, the same as multicluster mode is disabled
+type DynamicDispatcher struct {
+	mu         sync.RWMutex
+	dispatcher Dispatcher
+}
+
+// Set replaces the dispatcher, nil disables dispatching
+func (d *DynamicDispatcher) Set(dispatcher Dispatcher) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dispatcher = dispatcher
+}
+
+func (d *DynamicDispatcher) get() Dispatcher {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.dispatcher
+}
+
+func (d *DynamicDispatcher) Dispatch(w http.ResponseWriter, req *http.Request, handler http.Handler) {
+	dispatcher := d.get()
+	if dispatcher == nil {
+		handler.ServeHTTP(w, req)
+		return
+	}
+	dispatcher.Dispatch(w, req, handler)
+}
@@ -0,0 +1,41 @@
+package dispatch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeDispatcher struct{}
+
+func (f *fakeDispatcher) Dispatch(w http.ResponseWriter, req *http.Request, handler http.Handler) {
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func TestDynamicDispatcher(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	d := &DynamicDispatcher{}
+	tests := []struct {
+		name       string
+		dispatcher Dispatcher
+		expected   int
+	}{
+		{name: "no dispatcher", dispatcher: nil, expected: http.StatusOK},
+		{name: "dispatcher set", dispatcher: &fakeDispatcher{}, expected: http.StatusAccepted},
+		{name: "dispatcher unset", dispatcher: nil, expected: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d.Set(test.dispatcher)
+			w := httptest.NewRecorder()
+			d.Dispatch(w, httptest.NewRequest(http.MethodGet, "/api/v1/pods", nil), next)
+			if w.Code != test.expected {
+				t.Errorf("expected status %d, got %d", test.expected, w.Code)
+			}
+		})
+	}
+}
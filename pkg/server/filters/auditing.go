@@ -0,0 +1,38 @@
+package filters
+
+import (
+	"fmt"
+	"net/http"
+
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/klog"
+
+	"captain/pkg/server/auditing"
+	"captain/pkg/server/request"
+)
+
+// WithAuditing records every request with user, request info and response code.
+// It should be installed after WithAuthentication so the user is known
+func WithAuditing(handler http.Handler, a auditing.Auditing) http.Handler {
+	if a == nil {
+		klog.Warningf("Auditing is disabled")
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		info, ok := request.RequestInfoFrom(req.Context())
+		if !ok {
+			responsewriters.InternalError(w, req, fmt.Errorf("no RequestInfo found in the context"))
+			return
+		}
+
+		event := a.LogRequestObject(req, info)
+		resp := &responseWriterDelegator{ResponseWriter: w}
+
+		defer func() {
+			a.LogResponseObject(event, resp.StatusCode())
+		}()
+
+		handler.ServeHTTP(resp, req)
+	})
+}
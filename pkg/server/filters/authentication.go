@@ -0,0 +1,46 @@
+package filters
+
+import (
+	"fmt"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/klog"
+
+	"captain/pkg/server/request"
+)
+
+// WithAuthentication installs authentication handler to handler chain.
+// The authenticated user.Info is put on the request context, retrieve it by request.UserFrom
+func WithAuthentication(handler http.Handler, authRequest authenticator.Request) http.Handler {
+	if authRequest == nil {
+		klog.Warningf("Authentication is disabled")
+		return handler
+	}
+	s := serializer.NewCodecFactory(runtime.NewScheme()).WithoutConversion()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		resp, ok, err := authRequest.AuthenticateRequest(req)
+		if err != nil || !ok {
+			info, found := request.RequestInfoFrom(req.Context())
+			if !found {
+				responsewriters.InternalError(w, req, fmt.Errorf("no RequestInfo found in the context"))
+				return
+			}
+			if err != nil {
+				klog.V(4).Infof("Unable to authenticate the request %s due to error: %v", req.URL, err)
+			}
+			gv := schema.GroupVersion{Group: info.APIGroup, Version: info.APIVersion}
+			responsewriters.ErrorNegotiated(apierrors.NewUnauthorized("Unauthorized"), s, gv, w, req)
+			return
+		}
+
+		req = req.WithContext(request.WithUser(req.Context(), resp.User))
+		handler.ServeHTTP(w, req)
+	})
+}
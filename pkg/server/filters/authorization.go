@@ -0,0 +1,80 @@
+package filters
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/klog"
+
+	"captain/pkg/server/authorization/authorizer"
+	"captain/pkg/server/request"
+)
+
+// WithAuthorization passes all authorized requests on to handler, and returns forbidden error otherwise.
+func WithAuthorization(handler http.Handler, authorizers authorizer.Authorizer) http.Handler {
+	if authorizers == nil {
+		klog.Warningf("Authorization is disabled")
+		return handler
+	}
+	s := serializer.NewCodecFactory(runtime.NewScheme()).WithoutConversion()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		info, found := request.RequestInfoFrom(ctx)
+		if !found {
+			responsewriters.InternalError(w, req, errors.New("no RequestInfo found in the context"))
+			return
+		}
+
+		attributes := getAuthorizerAttributes(req, info)
+
+		decision, reason, err := authorizers.Authorize(ctx, attributes)
+		if decision == authorizer.DecisionAllow {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		if err != nil {
+			responsewriters.InternalError(w, req, err)
+			return
+		}
+
+		klog.V(4).Infof("Forbidden: %#v, Reason: %q", req.RequestURI, reason)
+		gv := schema.GroupVersion{Group: info.APIGroup, Version: info.APIVersion}
+		gr := schema.GroupResource{Group: attributes.GetAPIGroup(), Resource: attributes.GetResource()}
+		responsewriters.ErrorNegotiated(apierrors.NewForbidden(gr, attributes.GetName(), errors.New(reason)), s, gv, w, req)
+	})
+}
+
+func getAuthorizerAttributes(req *http.Request, info *request.RequestInfo) authorizer.Attributes {
+	u, _ := request.UserFrom(req.Context())
+
+	attributes := authorizer.AttributesRecord{
+		User:            u,
+		Region:          info.Region,
+		Cluster:         info.Cluster,
+		Workspace:       info.Workspace,
+		ResourceRequest: info.IsResourceRequest,
+		Path:            req.URL.Path,
+	}
+
+	if !info.IsResourceRequest {
+		// non-resource requests are authorized by lowercased http method
+		attributes.Verb = strings.ToLower(req.Method)
+		return attributes
+	}
+
+	attributes.Verb = info.Verb
+	attributes.APIGroup = info.APIGroup
+	attributes.Namespace = info.Namespace
+	attributes.Resource = info.Resource
+	attributes.Subresource = info.Subresource
+	attributes.Name = info.Name
+
+	return attributes
+}
@@ -25,7 +25,8 @@ func WithMultipleClusterDispatcher(handler http.Handler, dispatch dispatch.Dispa
 			return
 		}
 
-		if info.Cluster == "" || len(info.APIPrefix) == 0 {
+		// captain apis of member clusters are served by host cluster, only kubernetes requests need dispatching
+		if info.Cluster == "" || !info.IsKubernetesRequest {
 			handler.ServeHTTP(w, req)
 		} else {
 			dispatch.Dispatch(w, req, handler)
@@ -0,0 +1,75 @@
+package filters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"captain/pkg/server/request"
+)
+
+// fakeDispatcher records requests dispatched to member clusters
+type fakeDispatcher struct {
+	dispatched bool
+}
+
+func (d *fakeDispatcher) Dispatch(w http.ResponseWriter, req *http.Request, handler http.Handler) {
+	d.dispatched = true
+}
+
+func TestWithMultipleClusterDispatcher(t *testing.T) {
+	tests := []struct {
+		name               string
+		url                string
+		expectedDispatched bool
+	}{
+		{
+			name:               "kubernetes request of host cluster",
+			url:                "/api/v1/nodes",
+			expectedDispatched: false,
+		},
+		{
+			name:               "kubernetes request of member cluster",
+			url:                "/clusters/gondor/api/v1/nodes",
+			expectedDispatched: true,
+		},
+		{
+			name:               "captain api of member cluster",
+			url:                "/regions/middle-earth/clusters/gondor/capis/resources.captain.io/alpha1/resources/nodes",
+			expectedDispatched: false,
+		},
+		{
+			name:               "captain api of host cluster",
+			url:                "/capis/resources.captain.io/alpha1/resources/nodes",
+			expectedDispatched: false,
+		},
+	}
+
+	resolver := &request.RequestInfoFactory{
+		APIPrefixes: sets.NewString("api", "apis", "capis"),
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			served := false
+			dispatcher := &fakeDispatcher{}
+			handler := WithMultipleClusterDispatcher(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				served = true
+			}), dispatcher)
+
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+			info, err := resolver.NewRequestInfo(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = req.WithContext(request.WithRequestInfo(req.Context(), info))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if dispatcher.dispatched != test.expectedDispatched || served == test.expectedDispatched {
+				t.Errorf("expected dispatched %v, got dispatched %v, served by host %v", test.expectedDispatched, dispatcher.dispatched, served)
+			}
+		})
+	}
+}
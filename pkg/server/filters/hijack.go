@@ -0,0 +1,166 @@
+package filters
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+)
+
+// websocketCloseGoingAway is a websocket close frame with status 1001 (going away), sent unmasked
+// as frames of server are
+var websocketCloseGoingAway = []byte{0x88, 0x02, 0x03, 0xe9}
+
+// HijackedConnections tracks connections hijacked from http.Server, e.g. proxied exec and websocket
+// connections, which are neither tracked nor closed by Server.Shutdown
+type HijackedConnections struct {
+	mu    sync.Mutex
+	conns map[*hijackedConn]struct{}
+}
+
+func NewHijackedConnections() *HijackedConnections {
+	return &HijackedConnections{conns: make(map[*hijackedConn]struct{})}
+}
+
+// WithHijackTracking tracks connections hijacked by handler in conns
+func WithHijackTracking(handler http.Handler, conns *HijackedConnections) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		handler.ServeHTTP(&hijackTrackingWriter{
+			ResponseWriter: w,
+			conns:          conns,
+			websocket:      strings.EqualFold(req.Header.Get("Upgrade"), "websocket"),
+		}, req)
+	})
+}
+
+// Len returns the number of open hijacked connections
+func (c *HijackedConnections) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.conns)
+}
+
+// Shutdown waits for hijacked connections to be closed by their handlers until ctx is done, then
+// closes the remaining ones. Websocket connections are sent a close frame before closed, so that
+// clients know they can reconnect
+func (c *HijackedConnections) Shutdown(ctx context.Context) error {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for c.Len() != 0 {
+		select {
+		case <-ctx.Done():
+			c.closeAll()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+func (c *HijackedConnections) closeAll() {
+	c.mu.Lock()
+	conns := make([]*hijackedConn, 0, len(c.conns))
+	for conn := range c.conns {
+		conns = append(conns, conn)
+	}
+	c.mu.Unlock()
+
+	klog.V(0).Infof("Closing %d hijacked connections", len(conns))
+	for _, conn := range conns {
+		if conn.websocket {
+			conn.writeCloseFrame()
+		}
+		_ = conn.Close()
+	}
+}
+
+func (c *HijackedConnections) add(conn *hijackedConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conns[conn] = struct{}{}
+}
+
+func (c *HijackedConnections) remove(conn *hijackedConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.conns, conn)
+}
+
+// hijackedConn removes itself from conns once closed. Writes are serialized so that the close frame
+// is not written in the middle of a write of the handler
+type hijackedConn struct {
+	net.Conn
+	conns     *HijackedConnections
+	websocket bool
+
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+}
+
+func (c *hijackedConn) Write(b []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.Conn.Write(b)
+}
+
+func (c *hijackedConn) writeCloseFrame() {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.Conn.SetWriteDeadline(time.Now().Add(time.Second))
+	if _, err := c.Conn.Write(websocketCloseGoingAway); err != nil {
+		klog.V(4).Infof("Failed to send close frame to %s: %v", c.RemoteAddr(), err)
+	}
+}
+
+func (c *hijackedConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		c.conns.remove(c)
+		err = c.Conn.Close()
+	})
+	return err
+}
+
+// hijackTrackingWriter delegates Flush and CloseNotify like responseWriterDelegator, and tracks
+// the connection once hijacked
+type hijackTrackingWriter struct {
+	http.ResponseWriter
+	conns     *HijackedConnections
+	websocket bool
+}
+
+func (w *hijackTrackingWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *hijackTrackingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("http.Hijacker is not implemented by the underlying ResponseWriter")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	tracked := &hijackedConn{Conn: conn, conns: w.conns, websocket: w.websocket}
+	w.conns.add(tracked)
+	// buffered writes must go through the tracked connection as well
+	return tracked, bufio.NewReadWriter(rw.Reader, bufio.NewWriter(tracked)), nil
+}
+
+// CloseNotify is required by the kube-apiserver proxy for watch requests
+func (w *hijackTrackingWriter) CloseNotify() <-chan bool {
+	//nolint:staticcheck
+	if notifier, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return make(chan bool)
+}
@@ -11,12 +11,14 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/klog"
 
+	"captain/pkg/server/impersonation"
 	"captain/pkg/server/request"
 	"captain/pkg/simple/server/errors"
 )
 
-// WithKubeAPIServer proxy request to kubernetes service if requests path starts with /api
-func WithKubeAPIServer(handler http.Handler, config *rest.Config, failed proxy.ErrorResponder) http.Handler {
+// WithKubeAPIServer proxy request to kubernetes service if requests path starts with /api,
+// the requesting user is forwarded by impersonation headers if impersonator is not nil
+func WithKubeAPIServer(handler http.Handler, config *rest.Config, impersonator *impersonation.Impersonator, failed proxy.ErrorResponder) http.Handler {
 	kubernetes, _ := url.Parse(config.Host)
 	defaultTransport, err := rest.TransportFor(config)
 	if err != nil {
@@ -39,6 +41,7 @@ func WithKubeAPIServer(handler http.Handler, config *rest.Config, failed proxy.E
 
 			// make sure we don't override kubernetes's authorization
 			req.Header.Del("Authorization")
+			impersonator.Impersonate(req)
 			httpProxy := proxy.NewUpgradeAwareHandler(&s, defaultTransport, true, false, failed)
 			httpProxy.UpgradeTransport = proxy.NewUpgradeRequestRoundTripper(defaultTransport, defaultTransport)
 			httpProxy.ServeHTTP(w, req)
@@ -0,0 +1,32 @@
+package filters
+
+import (
+	"net/http"
+	"time"
+
+	"k8s.io/klog"
+
+	"captain/pkg/server/metrics"
+	"captain/pkg/server/request"
+)
+
+// WithMetrics records count and latency of every request, it should be installed right after
+// WithRequestInfo so that requests rejected by other filters are recorded as well
+func WithMetrics(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		info, ok := request.RequestInfoFrom(req.Context())
+		if !ok {
+			klog.Warningf("no RequestInfo found in the context, request %s is not recorded", req.URL)
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		start := time.Now()
+		resp := &responseWriterDelegator{ResponseWriter: w}
+		defer func() {
+			metrics.RecordRequest(info.Verb, info.APIGroup, info.Resource, resp.StatusCode(), time.Since(start))
+		}()
+
+		handler.ServeHTTP(resp, req)
+	})
+}
@@ -0,0 +1,63 @@
+package filters
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// responseWriterDelegator records the status code written to the underlying ResponseWriter,
+// Flush and Hijack are delegated so that watch and upgraded connections keep working
+type responseWriterDelegator struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *responseWriterDelegator) StatusCode() int {
+	if w.statusCode == 0 {
+		return http.StatusOK
+	}
+	return w.statusCode
+}
+
+func (w *responseWriterDelegator) WriteHeader(code int) {
+	if w.statusCode == 0 {
+		w.statusCode = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriterDelegator) Write(b []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseWriterDelegator) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *responseWriterDelegator) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("http.Hijacker is not implemented by the underlying ResponseWriter")
+	}
+	// hijacked connections are upgraded, e.g. exec and websocket
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusSwitchingProtocols
+	}
+	return hijacker.Hijack()
+}
+
+// CloseNotify is required by the kube-apiserver proxy for watch requests
+func (w *responseWriterDelegator) CloseNotify() <-chan bool {
+	//nolint:staticcheck
+	if notifier, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return make(chan bool)
+}
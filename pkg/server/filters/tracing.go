@@ -0,0 +1,48 @@
+package filters
+
+import (
+	"fmt"
+	"net/http"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/klog"
+
+	"captain/pkg/server/request"
+	"captain/pkg/server/tracing"
+)
+
+// WithTracing starts a server span for every request, continuing the trace of caller carried by
+// traceparent header. It should be installed right after WithRequestInfo so that the span covers
+// all other filters
+func WithTracing(handler http.Handler, tp trace.TracerProvider) http.Handler {
+	if tp == nil {
+		klog.V(4).Infof("Tracing is disabled")
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		attributes := semconv.HTTPServerAttributesFromHTTPRequest("captain", "", req)
+		name := "HTTP " + req.Method
+		if info, ok := request.RequestInfoFrom(req.Context()); ok {
+			if info.IsResourceRequest {
+				name = fmt.Sprintf("%s %s", info.Verb, info.Resource)
+				if len(info.Subresource) != 0 {
+					name = fmt.Sprintf("%s/%s", name, info.Subresource)
+				}
+			}
+			if len(info.Cluster) != 0 {
+				attributes = append(attributes, tracing.RegionKey.String(info.Region), tracing.ClusterKey.String(info.Cluster))
+			}
+		}
+
+		ctx := tracing.Extract(req.Context(), req.Header)
+		ctx, span := tp.Tracer(tracing.InstrumentationName).Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attributes...))
+		defer span.End()
+
+		resp := &responseWriterDelegator{ResponseWriter: w}
+		handler.ServeHTTP(resp, req.WithContext(ctx))
+		tracing.SetHTTPStatus(span, resp.StatusCode(), trace.SpanKindServer)
+	})
+}
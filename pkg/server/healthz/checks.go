@@ -0,0 +1,175 @@
+package healthz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/server/healthz"
+	"k8s.io/client-go/discovery"
+
+	clusterv1alpha1 "captain/apis/cluster/v1alpha1"
+	clusterlister "captain/pkg/client/listers/cluster/v1alpha1"
+	"captain/pkg/simple/client/cache"
+	"captain/pkg/utils/clusterclient"
+)
+
+const (
+	// checkTimeout limits the time spent on remote services of a single check
+	checkTimeout = 5 * time.Second
+
+	// reportInterval is how long reports of member clusters are reused, so that requests
+	// to health endpoints don't probe every member cluster each time
+	reportInterval = 30 * time.Second
+)
+
+// InformerSyncCheck fails until synced is closed
+func InformerSyncCheck(synced <-chan struct{}) healthz.HealthChecker {
+	return healthz.NamedCheck("informer-sync", func(_ *http.Request) error {
+		select {
+		case <-synced:
+			return nil
+		default:
+			return errors.New("informer caches are not synced yet")
+		}
+	})
+}
+
+// ShutdownCheck fails once shuttingDown is closed, so that load balancers stop sending new requests
+// while in-flight ones are drained
+func ShutdownCheck(shuttingDown <-chan struct{}) healthz.HealthChecker {
+	return healthz.NamedCheck("shutdown", func(_ *http.Request) error {
+		select {
+		case <-shuttingDown:
+			return errors.New("server is shutting down")
+		default:
+			return nil
+		}
+	})
+}
+
+// KubeAPIServerCheck checks kube-apiserver is reachable
+func KubeAPIServerCheck(client discovery.DiscoveryInterface) healthz.HealthChecker {
+	return healthz.NamedCheck("kube-apiserver", func(r *http.Request) error {
+		ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+		defer cancel()
+		_, err := client.RESTClient().Get().AbsPath("/version").Do(ctx).Raw()
+		return err
+	})
+}
+
+// RedisCheck checks redis returned by client is reachable, client is called on every check since
+// redis can be reconnected at runtime. The check passes while redis is not configured
+func RedisCheck(client func() cache.Interface) healthz.HealthChecker {
+	return healthz.NamedCheck("redis", func(_ *http.Request) error {
+		c := client()
+		if c == nil {
+			return nil
+		}
+		return c.Ping()
+	})
+}
+
+type memberClusterReporter struct {
+	clusterLister  clusterlister.ClusterLister
+	clusterClients clusterclient.ClusterClients
+
+	// lock serializes probes, report is reused until reportInterval after reportedAt
+	lock       sync.Mutex
+	report     map[string]error
+	reportedAt time.Time
+}
+
+// NewMemberClusterReporter reports connectivity of every member cluster, requests are sent the same
+// way as dispatching, directly to kube-apiserver or to captain-apiserver of the member cluster.
+// Member clusters are probed at most once every reportInterval
+func NewMemberClusterReporter(clusterLister clusterlister.ClusterLister, clusterClients clusterclient.ClusterClients) Reporter {
+	return &memberClusterReporter{
+		clusterLister:  clusterLister,
+		clusterClients: clusterClients,
+	}
+}
+
+func (m *memberClusterReporter) Name() string {
+	return "member-cluster"
+}
+
+func (m *memberClusterReporter) Report() map[string]error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.report == nil || time.Since(m.reportedAt) > reportInterval {
+		m.report = m.probe()
+		m.reportedAt = time.Now()
+	}
+	return m.report
+}
+
+// probe checks every member cluster concurrently
+func (m *memberClusterReporter) probe() map[string]error {
+	report := make(map[string]error)
+
+	clusters, err := m.clusterLister.List(labels.Everything())
+	if err != nil {
+		report["*"] = err
+		return report
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for _, cluster := range clusters {
+		if m.clusterClients.IsHostCluster(cluster) {
+			continue
+		}
+		wg.Add(1)
+		go func(cluster *clusterv1alpha1.Cluster) {
+			defer wg.Done()
+			err := m.check(ctx, cluster)
+			lock.Lock()
+			report[cluster.Name] = err
+			lock.Unlock()
+		}(cluster)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (m *memberClusterReporter) check(ctx context.Context, cluster *clusterv1alpha1.Cluster) error {
+	innCluster := m.clusterClients.GetInnerCluster(cluster.Name)
+	if innCluster == nil {
+		return errors.New("cluster is not ready")
+	}
+
+	transport := http.DefaultTransport
+	endpoint := innCluster.CaptainURL
+	if cluster.Spec.Connection.Type == clusterv1alpha1.ConnectionTypeDirect &&
+		len(cluster.Spec.Connection.CaptainAPIEndpoint) == 0 {
+		transport = innCluster.Transport
+		endpoint = innCluster.KubernetesURL
+	}
+
+	u := *endpoint
+	u.Path = "/healthz"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with status %d", u.Host, resp.StatusCode)
+	}
+	return nil
+}
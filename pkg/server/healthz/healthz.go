@@ -0,0 +1,161 @@
+package healthz
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/server/healthz"
+	"k8s.io/klog"
+)
+
+// Reporter reports status of a dynamic set of targets, e.g. member clusters. Reports are
+// only shown in verbose output, and failures of them never fail the health endpoint. Health
+// endpoints are public, so Report should be cheap, e.g. return results cached for a while
+type Reporter interface {
+	Name() string
+	Report() map[string]error
+}
+
+type mux interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// InstallPathHandler registers handler of checks on path, and handler of every single check on path/{check},
+// output is compatible with health endpoints of kubernetes:
+//
+//	GET /readyz                     "ok" or 500 if any check fails
+//	GET /readyz?verbose             status of every check and report
+//	GET /readyz?exclude=redis       skip the named checks
+//	GET /readyz/informer-sync       status of a single check
+//
+// Targets of reports are only listed to users authenticated by authRequest, the others get a single
+// line of every report. Nobody is authenticated if authRequest is nil
+func InstallPathHandler(mux mux, path string, checks []healthz.HealthChecker, authRequest authenticator.Request, reporters ...Reporter) {
+	klog.V(5).Infof("Installing health checkers for %s: %v", path, checkNames(checks))
+
+	mux.Handle(path, handleRootHealth(strings.TrimPrefix(path, "/"), checks, authRequest, reporters))
+	for _, check := range checks {
+		mux.Handle(fmt.Sprintf("%s/%s", path, check.Name()), adaptCheckToHandler(check.Check))
+	}
+}
+
+func handleRootHealth(name string, checks []healthz.HealthChecker, authRequest authenticator.Request, reporters []Reporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		excluded := sets.NewString()
+		for _, exclude := range r.URL.Query()["exclude"] {
+			excluded.Insert(strings.Split(exclude, ",")...)
+		}
+
+		var verboseOut bytes.Buffer
+		var failedChecks []string
+		for _, check := range checks {
+			if excluded.Has(check.Name()) {
+				excluded.Delete(check.Name())
+				fmt.Fprintf(&verboseOut, "[+]%s excluded: ok\n", check.Name())
+				continue
+			}
+			if err := check.Check(r); err != nil {
+				// don't include the error since this endpoint is public, details are logged
+				fmt.Fprintf(&verboseOut, "[-]%s failed: reason withheld\n", check.Name())
+				klog.V(2).Infof("%s check %s failed: %v", name, check.Name(), err)
+				failedChecks = append(failedChecks, check.Name())
+				continue
+			}
+			fmt.Fprintf(&verboseOut, "[+]%s ok\n", check.Name())
+		}
+		if excluded.Len() > 0 {
+			fmt.Fprintf(&verboseOut, "warn: some health checks cannot be excluded: no matches for %s\n", strings.Join(excluded.List(), ","))
+		}
+
+		_, verbose := r.URL.Query()["verbose"]
+		if verbose && len(reporters) > 0 {
+			detailed := authenticated(r, authRequest)
+			for _, reporter := range reporters {
+				report := reporter.Report()
+				if !detailed {
+					writeReportSummary(&verboseOut, reporter.Name(), report)
+					continue
+				}
+				targets := make([]string, 0, len(report))
+				for target := range report {
+					targets = append(targets, target)
+				}
+				sort.Strings(targets)
+				for _, target := range targets {
+					if err := report[target]; err != nil {
+						// withhold errors the same as checks, errors of member clusters may tell their addresses
+						fmt.Fprintf(&verboseOut, "[-]%s/%s failed: reason withheld (informational)\n", reporter.Name(), target)
+						klog.V(2).Infof("%s report %s/%s failed: %v", name, reporter.Name(), target, err)
+					} else {
+						fmt.Fprintf(&verboseOut, "[+]%s/%s ok (informational)\n", reporter.Name(), target)
+					}
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		if len(failedChecks) > 0 {
+			klog.V(2).Infof("%s check failed: %s", name, strings.Join(failedChecks, ","))
+			http.Error(w, fmt.Sprintf("%s%s check failed", verboseOut.String(), name), http.StatusInternalServerError)
+			return
+		}
+
+		if !verbose {
+			fmt.Fprint(w, "ok")
+			return
+		}
+		verboseOut.WriteTo(w)
+		fmt.Fprintf(w, "%s check passed\n", name)
+	}
+}
+
+func adaptCheckToHandler(c func(r *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := c(r); err != nil {
+			// don't include the error since this endpoint is public, the same as checks of the root
+			klog.V(2).Infof("check of %s failed: %v", r.URL.Path, err)
+			http.Error(w, "internal server error: reason withheld", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}
+}
+
+// authenticated returns true if the user of r is authenticated by authRequest
+func authenticated(r *http.Request, authRequest authenticator.Request) bool {
+	if authRequest == nil {
+		return false
+	}
+	_, ok, err := authRequest.AuthenticateRequest(r)
+	return err == nil && ok
+}
+
+// writeReportSummary writes report of name in a single line, without listing its targets
+func writeReportSummary(out *bytes.Buffer, name string, report map[string]error) {
+	failed := 0
+	for _, err := range report {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(out, "[-]%s failed: reason withheld (informational)\n", name)
+		return
+	}
+	fmt.Fprintf(out, "[+]%s ok (informational)\n", name)
+}
+
+func checkNames(checks []healthz.HealthChecker) []string {
+	names := make([]string, 0, len(checks))
+	for _, check := range checks {
+		names = append(names, check.Name())
+	}
+	return names
+}
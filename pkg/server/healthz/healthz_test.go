@@ -0,0 +1,123 @@
+package healthz
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/server/healthz"
+)
+
+type fakeReporter map[string]error
+
+func (f fakeReporter) Name() string {
+	return "member-cluster"
+}
+
+func (f fakeReporter) Report() map[string]error {
+	return f
+}
+
+// fakeAuthenticator authenticates requests with the header Authorization
+var fakeAuthenticator = authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+	if len(req.Header.Get("Authorization")) == 0 {
+		return nil, false, nil
+	}
+	return &authenticator.Response{User: &user.DefaultInfo{Name: "alice"}}, true, nil
+})
+
+func TestInstallPathHandler(t *testing.T) {
+	synced := make(chan struct{})
+	checks := []healthz.HealthChecker{
+		healthz.PingHealthz,
+		InformerSyncCheck(synced),
+	}
+	reporter := fakeReporter{"bar": errors.New("connection refused"), "foo": nil}
+
+	mux := http.NewServeMux()
+	InstallPathHandler(mux, "/readyz", checks, fakeAuthenticator, reporter)
+
+	tests := []struct {
+		name          string
+		path          string
+		synced        bool
+		authenticated bool
+		expectedCode  int
+		expectedBody  string
+	}{
+		{
+			name:         "not synced",
+			path:         "/readyz",
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "[+]ping ok\n[-]informer-sync failed: reason withheld\nreadyz check failed\n",
+		},
+		{
+			name:         "not synced excluded",
+			path:         "/readyz?exclude=informer-sync",
+			expectedCode: http.StatusOK,
+			expectedBody: "ok",
+		},
+		{
+			name:         "single check",
+			path:         "/readyz/informer-sync",
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "internal server error: reason withheld\n",
+		},
+		{
+			name:         "synced",
+			path:         "/readyz",
+			synced:       true,
+			expectedCode: http.StatusOK,
+			expectedBody: "ok",
+		},
+		{
+			name:         "synced verbose with failed report",
+			path:         "/readyz?verbose",
+			synced:       true,
+			expectedCode: http.StatusOK,
+			expectedBody: "[+]ping ok\n[+]informer-sync ok\n" +
+				"[-]member-cluster failed: reason withheld (informational)\n" +
+				"readyz check passed\n",
+		},
+		{
+			name:          "synced verbose with failed report of authenticated users",
+			path:          "/readyz?verbose",
+			synced:        true,
+			authenticated: true,
+			expectedCode:  http.StatusOK,
+			expectedBody: "[+]ping ok\n[+]informer-sync ok\n" +
+				"[-]member-cluster/bar failed: reason withheld (informational)\n" +
+				"[+]member-cluster/foo ok (informational)\n" +
+				"readyz check passed\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.synced {
+				select {
+				case <-synced:
+				default:
+					close(synced)
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodGet, test.path, nil)
+			if test.authenticated {
+				req.Header.Set("Authorization", "Bearer token")
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != test.expectedCode {
+				t.Errorf("expected code %d, got %d", test.expectedCode, w.Code)
+			}
+			if w.Body.String() != test.expectedBody {
+				t.Errorf("expected body %q, got %q", test.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
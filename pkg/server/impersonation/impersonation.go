@@ -0,0 +1,88 @@
+package impersonation
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/rest"
+
+	"captain/pkg/server/request"
+)
+
+// Impersonator sets impersonation headers on requests proxied to kubernetes
+type Impersonator struct {
+	skipUsers  sets.String
+	skipGroups sets.String
+}
+
+// NewImpersonator returns nil if impersonation is disabled
+func NewImpersonator(o *Options) *Impersonator {
+	if o == nil || !o.Enable {
+		return nil
+	}
+	return &Impersonator{
+		skipUsers:  sets.NewString(o.SkipUsers...),
+		skipGroups: sets.NewString(o.SkipGroups...),
+	}
+}
+
+// Impersonate replaces impersonation headers of req with the user from request context.
+// Impersonation headers sent by client are always removed, otherwise client could act as
+// anyone with captain's identity. It's safe to call on a nil Impersonator, which only removes them
+func (i *Impersonator) Impersonate(req *http.Request) {
+	removeImpersonationHeaders(req.Header)
+	if i == nil {
+		return
+	}
+
+	u, ok := request.UserFrom(req.Context())
+	if !ok || u == nil || i.skip(u) {
+		return
+	}
+
+	req.Header.Set(authenticationv1.ImpersonateUserHeader, u.GetName())
+	for _, group := range u.GetGroups() {
+		req.Header.Add(authenticationv1.ImpersonateGroupHeader, group)
+	}
+	for key, values := range u.GetExtra() {
+		// extra keys are escaped the same way as client-go does
+		header := authenticationv1.ImpersonateUserExtraHeaderPrefix + url.PathEscape(key)
+		for _, value := range values {
+			req.Header.Add(header, value)
+		}
+	}
+}
+
+// Config returns the impersonation config of clients sending requests on behalf of the user from ctx,
+// it's empty if there is no user or the user is skipped. It's safe to call on a nil Impersonator,
+// which returns an empty config
+func (i *Impersonator) Config(ctx context.Context) rest.ImpersonationConfig {
+	if i == nil {
+		return rest.ImpersonationConfig{}
+	}
+	u, ok := request.UserFrom(ctx)
+	if !ok || u == nil || i.skip(u) {
+		return rest.ImpersonationConfig{}
+	}
+	return rest.ImpersonationConfig{UserName: u.GetName(), Groups: u.GetGroups(), Extra: u.GetExtra()}
+}
+
+func (i *Impersonator) skip(u user.Info) bool {
+	if i.skipUsers.Has(u.GetName()) {
+		return true
+	}
+	return i.skipGroups.HasAny(u.GetGroups()...)
+}
+
+func removeImpersonationHeaders(header http.Header) {
+	for key := range header {
+		if strings.HasPrefix(key, "Impersonate-") {
+			header.Del(key)
+		}
+	}
+}
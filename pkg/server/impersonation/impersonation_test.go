@@ -0,0 +1,116 @@
+package impersonation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/rest"
+
+	"captain/pkg/server/request"
+)
+
+func TestImpersonate(t *testing.T) {
+	o := &Options{
+		Enable:     true,
+		SkipUsers:  []string{"system:serviceaccount:captain-system:captain"},
+		SkipGroups: []string{"system:masters"},
+	}
+
+	tests := []struct {
+		name     string
+		user     user.Info
+		header   http.Header
+		expected http.Header
+	}{
+		{
+			name: "impersonate user and groups",
+			user: &user.DefaultInfo{Name: "alice", Groups: []string{"dev", "system:authenticated"}, Extra: map[string][]string{"scopes.example.com/project": {"a"}}},
+			expected: http.Header{
+				"Impersonate-User":  {"alice"},
+				"Impersonate-Group": {"dev", "system:authenticated"},
+				"Impersonate-Extra-Scopes.example.com%2fproject": {"a"},
+			},
+		},
+		{
+			name:     "client impersonation headers are removed",
+			user:     &user.DefaultInfo{Name: "alice"},
+			header:   http.Header{"Impersonate-User": {"admin"}, "Impersonate-Group": {"system:masters"}},
+			expected: http.Header{"Impersonate-User": {"alice"}},
+		},
+		{
+			name:     "skip user",
+			user:     &user.DefaultInfo{Name: "system:serviceaccount:captain-system:captain"},
+			header:   http.Header{"Impersonate-User": {"admin"}},
+			expected: http.Header{},
+		},
+		{
+			name:     "skip group",
+			user:     &user.DefaultInfo{Name: "bob", Groups: []string{"system:masters"}},
+			expected: http.Header{},
+		},
+		{
+			name:     "no user",
+			header:   http.Header{"Impersonate-User": {"admin"}},
+			expected: http.Header{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/pods", nil)
+			req.Header = http.Header{}
+			for k, v := range test.header {
+				req.Header[k] = v
+			}
+			if test.user != nil {
+				req = req.WithContext(request.WithUser(req.Context(), test.user))
+			}
+
+			NewImpersonator(o).Impersonate(req)
+
+			if diff := cmp.Diff(test.expected, req.Header); diff != "" {
+				t.Errorf("%T differ (-expected, +got): %s", test.expected, diff)
+			}
+		})
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pods", nil)
+	req.Header.Set("Impersonate-User", "admin")
+	req.Header.Add("Impersonate-Group", "system:masters")
+	req.Header.Set("Impersonate-Extra-Scopes", "a")
+	req = req.WithContext(request.WithUser(req.Context(), &user.DefaultInfo{Name: "alice"}))
+
+	NewImpersonator(NewOptions()).Impersonate(req)
+
+	for key := range req.Header {
+		if strings.HasPrefix(key, "Impersonate-") {
+			t.Errorf("expected client impersonation headers removed when impersonation is disabled, got %s", key)
+		}
+	}
+}
+
+func TestConfig(t *testing.T) {
+	o := &Options{Enable: true, SkipGroups: []string{"system:masters"}}
+	alice := &user.DefaultInfo{Name: "alice", Groups: []string{"dev"}, Extra: map[string][]string{"scope": {"a"}}}
+
+	config := NewImpersonator(o).Config(request.WithUser(context.Background(), alice))
+	expected := rest.ImpersonationConfig{UserName: "alice", Groups: []string{"dev"}, Extra: map[string][]string{"scope": {"a"}}}
+	if diff := cmp.Diff(expected, config); diff != "" {
+		t.Errorf("%T differ (-expected, +got): %s", expected, diff)
+	}
+
+	bob := &user.DefaultInfo{Name: "bob", Groups: []string{"system:masters"}}
+	if config := NewImpersonator(o).Config(request.WithUser(context.Background(), bob)); len(config.UserName) != 0 {
+		t.Errorf("expected skipped users not impersonated, got %v", config)
+	}
+	if config := NewImpersonator(NewOptions()).Config(request.WithUser(context.Background(), alice)); len(config.UserName) != 0 {
+		t.Errorf("expected no impersonation when disabled, got %v", config)
+	}
+}
@@ -0,0 +1,39 @@
+package impersonation
+
+import (
+	"github.com/spf13/pflag"
+)
+
+type Options struct {
+	// Enable forwards the authenticated user to kubernetes by Impersonate-User and Impersonate-Group headers,
+	// so that RBAC and audit of kubernetes see the real user instead of captain's own identity
+	Enable bool `json:"enable" yaml:"enable" mapstructure:"enable"`
+
+	// SkipUsers are service identities whose requests are forwarded with captain's own identity
+	SkipUsers []string `json:"skipUsers,omitempty" yaml:"skipUsers,omitempty" mapstructure:"skipUsers"`
+
+	// SkipGroups are groups whose members' requests are forwarded with captain's own identity
+	SkipGroups []string `json:"skipGroups,omitempty" yaml:"skipGroups,omitempty" mapstructure:"skipGroups"`
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Enable:     false,
+		SkipUsers:  []string{},
+		SkipGroups: []string{},
+	}
+}
+
+func (o *Options) Validate() []error {
+	return nil
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet, s *Options) {
+	fs.BoolVar(&o.Enable, "impersonation-enable", s.Enable, ""+
+		"Forward the authenticated user to kubernetes and member clusters by impersonation headers. "+
+		"Writes of resources.captain.io are forbidden if it is disabled.")
+	fs.StringSliceVar(&o.SkipUsers, "impersonation-skip-users", s.SkipUsers, ""+
+		"Users whose requests are forwarded with captain's own identity, e.g. service accounts of system components.")
+	fs.StringSliceVar(&o.SkipGroups, "impersonation-skip-groups", s.SkipGroups, ""+
+		"Groups whose members' requests are forwarded with captain's own identity.")
+}
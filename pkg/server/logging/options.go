@@ -0,0 +1,36 @@
+package logging
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"k8s.io/klog"
+)
+
+type Options struct {
+	// Verbosity is the log level verbosity of klog. Non-zero verbosity overrides --v at startup,
+	// and any change of it in configuration file is applied at runtime
+	Verbosity int `json:"verbosity,omitempty" yaml:"verbosity,omitempty" mapstructure:"verbosity"`
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Verbosity: 0,
+	}
+}
+
+func (o *Options) Validate() []error {
+	var errs []error
+	if o.Verbosity < 0 {
+		errs = append(errs, fmt.Errorf("log verbosity %d must not be negative", o.Verbosity))
+	}
+	return errs
+}
+
+// SetVerbosity changes the log level verbosity of klog at runtime
+func SetVerbosity(v int) error {
+	fs := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(fs)
+	return fs.Set("v", strconv.Itoa(v))
+}
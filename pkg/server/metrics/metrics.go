@@ -0,0 +1,205 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	namespace = "captain"
+	subsystem = "server"
+
+	// OtherLabel replaces label values taken from requests that are not known to be valid, values
+	// of requests are sent by clients, so they are not used directly to keep the number of series bounded
+	OtherLabel = "other"
+)
+
+// knownVerbs are verbs of resource requests and methods of non-resource requests, the others are OtherLabel
+var knownVerbs = sets.NewString("get", "list", "watch", "create", "update", "patch", "delete", "deletecollection", "proxy",
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions)
+
+var (
+	requestCounter = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "request_total",
+			Help:           "Counter of captain-server requests broken out by verb, API group, resource and HTTP response code.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"verb", "group", "resource", "code"},
+	)
+
+	requestLatencies = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "request_duration_seconds",
+			Help:           "Response latency distribution in seconds of captain-server requests broken out by verb, API group and resource.",
+			Buckets:        []float64{0.005, 0.025, 0.05, 0.1, 0.2, 0.4, 0.6, 0.8, 1.0, 1.5, 2, 3, 5, 10, 30, 60},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"verb", "group", "resource"},
+	)
+
+	dispatchCounter = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "dispatch_request_total",
+			Help:           "Counter of requests dispatched to member clusters broken out by region, cluster and upstream HTTP response code.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"region", "cluster", "code"},
+	)
+
+	dispatchLatencies = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "dispatch_duration_seconds",
+			Help:           "Latency distribution in seconds of requests dispatched to member clusters broken out by region and cluster.",
+			Buckets:        []float64{0.005, 0.025, 0.05, 0.1, 0.2, 0.4, 0.6, 0.8, 1.0, 1.5, 2, 3, 5, 10, 30, 60},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"region", "cluster"},
+	)
+
+	dispatchErrors = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "dispatch_error_total",
+			Help:           "Counter of requests failed to dispatch to member clusters broken out by region, cluster and reason.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"region", "cluster", "reason"},
+	)
+
+	dispatchUpgradeSessions = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "dispatch_upgrade_sessions",
+			Help:           "Number of active upgraded (websocket, exec, port-forward) sessions to member clusters broken out by region and cluster.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"region", "cluster"},
+	)
+
+	dispatchUpgradeCounter = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "dispatch_upgrade_session_total",
+			Help:           "Counter of upgraded (websocket, exec, port-forward) sessions to member clusters broken out by region and cluster.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"region", "cluster"},
+	)
+
+	informerSynced = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "informer_synced",
+			Help:           "Whether the informer cache of a resource has synced, 1 for synced and 0 for not yet.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"group", "version", "resource"},
+	)
+
+	cacheSyncDuration = compbasemetrics.NewGauge(
+		&compbasemetrics.GaugeOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "cache_sync_duration_seconds",
+			Help:           "Time in seconds spent waiting for informer caches to sync when captain-server starts.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+
+	metrics = []compbasemetrics.Registerable{
+		requestCounter,
+		requestLatencies,
+		dispatchCounter,
+		dispatchLatencies,
+		dispatchErrors,
+		dispatchUpgradeSessions,
+		dispatchUpgradeCounter,
+		informerSynced,
+		cacheSyncDuration,
+	}
+)
+
+var registerMetrics sync.Once
+
+// Register registers all metrics of captain-server to the legacy registry,
+// it's safe to be called multiple times, e.g. server restarted by config change
+func Register() {
+	registerMetrics.Do(func() {
+		for _, metric := range metrics {
+			legacyregistry.MustRegister(metric)
+		}
+	})
+}
+
+// Handler returns the http handler exposing metrics in prometheus format
+func Handler() http.Handler {
+	return legacyregistry.Handler()
+}
+
+// RecordRequest records a request served by captain-server. Group and resource are only recorded if
+// the request is not rejected by a client error, e.g. unauthenticated, forbidden or not found, since
+// they are taken from URLs of requests and not known to be valid then
+func RecordRequest(verb, group, resource string, code int, elapsed time.Duration) {
+	if !knownVerbs.Has(verb) {
+		verb = OtherLabel
+	}
+	if code >= http.StatusBadRequest && code < http.StatusInternalServerError {
+		group, resource = OtherLabel, OtherLabel
+	}
+	requestCounter.WithLabelValues(verb, group, resource, strconv.Itoa(code)).Inc()
+	requestLatencies.WithLabelValues(verb, group, resource).Observe(elapsed.Seconds())
+}
+
+// RecordDispatch records a request dispatched to member cluster and responded by upstream
+func RecordDispatch(region, cluster string, code int, elapsed time.Duration) {
+	dispatchCounter.WithLabelValues(region, cluster, strconv.Itoa(code)).Inc()
+	dispatchLatencies.WithLabelValues(region, cluster).Observe(elapsed.Seconds())
+}
+
+// RecordDispatchError records a request failed to dispatch to member cluster, reason should be
+// a short fixed string to keep cardinality low, e.g. cluster_not_found, upstream_error
+func RecordDispatchError(region, cluster, reason string) {
+	dispatchErrors.WithLabelValues(region, cluster, reason).Inc()
+}
+
+// TrackUpgradeSession marks the start of an upgraded session to member cluster,
+// the returned func should be called when the session ends
+func TrackUpgradeSession(region, cluster string) func() {
+	dispatchUpgradeCounter.WithLabelValues(region, cluster).Inc()
+	sessions := dispatchUpgradeSessions.WithLabelValues(region, cluster)
+	sessions.Inc()
+	return sessions.Dec
+}
+
+// SetInformerSynced records the cache sync status of informer of a resource
+func SetInformerSynced(group, version, resource string, synced bool) {
+	value := 0.0
+	if synced {
+		value = 1
+	}
+	informerSynced.WithLabelValues(group, version, resource).Set(value)
+}
+
+// SetCacheSyncDuration records the time spent waiting for informer caches to sync
+func SetCacheSyncDuration(elapsed time.Duration) {
+	cacheSyncDuration.Set(elapsed.Seconds())
+}
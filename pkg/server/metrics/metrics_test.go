@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+func TestRecordRequest(t *testing.T) {
+	requestCounter.Reset()
+	Register()
+
+	RecordRequest("list", "apps", "deployments", http.StatusOK, time.Millisecond)
+	RecordRequest("list", "random", "garbage", http.StatusUnauthorized, time.Millisecond)
+	RecordRequest("BREW", "", "", http.StatusNotFound, time.Millisecond)
+
+	expected := `
+# HELP captain_server_request_total [ALPHA] Counter of captain-server requests broken out by verb, API group, resource and HTTP response code.
+# TYPE captain_server_request_total counter
+captain_server_request_total{code="200",group="apps",resource="deployments",verb="list"} 1
+captain_server_request_total{code="401",group="other",resource="other",verb="list"} 1
+captain_server_request_total{code="404",group="other",resource="other",verb="other"} 1
+`
+	if err := testutil.GatherAndCompare(legacyregistry.DefaultGatherer, strings.NewReader(expected), "captain_server_request_total"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,118 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/emicklei/go-restful"
+	restfulspec "github.com/emicklei/go-restful-openapi"
+	"github.com/go-openapi/spec"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/klog"
+	"k8s.io/kube-openapi/pkg/openapiconv"
+	kubespec "k8s.io/kube-openapi/pkg/validation/spec"
+
+	"captain/pkg/version"
+)
+
+const (
+	V2Path = "/openapi/v2"
+	V3Path = "/openapi/v3"
+)
+
+// AddToContainer serves the OpenAPI v2 and v3 documents of all web services registered in c,
+// documents are built on the first request, so this can be called before all apis are installed
+func AddToContainer(c *restful.Container) {
+	o := &openAPI{container: c}
+	c.Handle(V2Path, http.HandlerFunc(o.handleV2))
+	c.Handle(V3Path, http.HandlerFunc(o.handleV3))
+}
+
+type openAPI struct {
+	container *restful.Container
+
+	once sync.Once
+	v2   []byte
+	v3   []byte
+	err  error
+}
+
+func (o *openAPI) handleV2(w http.ResponseWriter, req *http.Request) {
+	o.once.Do(o.build)
+	o.write(w, req, o.v2)
+}
+
+func (o *openAPI) handleV3(w http.ResponseWriter, req *http.Request) {
+	o.once.Do(o.build)
+	o.write(w, req, o.v3)
+}
+
+func (o *openAPI) write(w http.ResponseWriter, req *http.Request, data []byte) {
+	if o.err != nil {
+		responsewriters.InternalError(w, req, o.err)
+		return
+	}
+	w.Header().Set("Content-Type", restful.MIME_JSON)
+	_, _ = w.Write(data)
+}
+
+func (o *openAPI) build() {
+	swagger := BuildSwagger(o.container.RegisteredWebServices())
+
+	o.v2, o.err = json.Marshal(swagger)
+	if o.err != nil {
+		klog.Errorf("failed to marshal openapi v2 document: %v", o.err)
+		return
+	}
+
+	// kube-openapi converts v2 to v3 with its own spec types, which share the json format with go-openapi
+	var kubeSwagger kubespec.Swagger
+	if o.err = json.Unmarshal(o.v2, &kubeSwagger); o.err != nil {
+		klog.Errorf("failed to convert openapi v2 document: %v", o.err)
+		return
+	}
+
+	o.v3, o.err = json.Marshal(openapiconv.ConvertV2ToV3(&kubeSwagger))
+	if o.err != nil {
+		klog.Errorf("failed to marshal openapi v3 document: %v", o.err)
+	}
+}
+
+// BuildSwagger builds the OpenAPI v2 document from restful web services
+func BuildSwagger(webServices []*restful.WebService) *spec.Swagger {
+	return restfulspec.BuildSwagger(restfulspec.Config{
+		WebServices:                   webServices,
+		ModelTypeNameHandler:          modelTypeName,
+		PostBuildSwaggerObjectHandler: enrichSwaggerObject,
+	})
+}
+
+// modelTypeName names arbitrary objects, e.g. map[string]interface{}, as "object",
+// instead of the go type name which is not a valid definition name
+func modelTypeName(t reflect.Type) (string, bool) {
+	if t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.Interface {
+		return "object", true
+	}
+	return "", false
+}
+
+func enrichSwaggerObject(swagger *spec.Swagger) {
+	swagger.Info = &spec.Info{
+		InfoProps: spec.InfoProps{
+			Title:       "Captain",
+			Description: "Captain API",
+			Version:     version.Get().GitVersion,
+		},
+	}
+
+	// interface{} fields, e.g. items of response.ListResult, are built as empty definitions,
+	// they are always kubernetes objects in captain apis
+	for name, schema := range swagger.Definitions {
+		if len(schema.Type) == 0 && len(schema.Properties) == 0 && schema.Ref.String() == "" {
+			schema.Type = []string{"object"}
+			swagger.Definitions[name] = schema
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+
+	clusterv1alpha1 "captain/apis/cluster/v1alpha1"
+	"captain/pkg/unify/response"
+)
+
+type clusterList struct {
+	Items      []clusterv1alpha1.Cluster `json:"items"`
+	TotalItems int                       `json:"totalItems"`
+}
+
+func newContainer() *restful.Container {
+	c := restful.NewContainer()
+	ws := new(restful.WebService)
+	ws.Path("/capis/cluster.captain.io/v1alpha1").Produces(restful.MIME_JSON)
+	ws.Route(ws.GET("/clusters").To(func(*restful.Request, *restful.Response) {}).
+		Returns(http.StatusOK, "ok", clusterList{}))
+	ws.Route(ws.POST("/clusters").To(func(*restful.Request, *restful.Response) {}).
+		Reads(clusterv1alpha1.Cluster{}).
+		Returns(http.StatusOK, "ok", clusterv1alpha1.Cluster{}))
+	ws.Route(ws.GET("/resources/{resources}").To(func(*restful.Request, *restful.Response) {}).
+		Param(ws.PathParameter("resources", "resource type")).
+		Returns(http.StatusOK, "ok", response.ListResult{}))
+	ws.Route(ws.GET("/resources/{resources}/name/{name}").To(func(*restful.Request, *restful.Response) {}).
+		Param(ws.PathParameter("resources", "resource type")).
+		Param(ws.PathParameter("name", "name of resource")).
+		Returns(http.StatusOK, "ok", map[string]interface{}{}))
+	c.Add(ws)
+	AddToContainer(c)
+	return c
+}
+
+func get(t *testing.T, c *restful.Container, path string) map[string]interface{} {
+	w := httptest.NewRecorder()
+	c.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s responded %d: %s", path, w.Code, w.Body.String())
+	}
+	doc := make(map[string]interface{})
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("GET %s responded invalid json: %v", path, err)
+	}
+	return doc
+}
+
+func TestOpenAPIV2(t *testing.T) {
+	doc := get(t, newContainer(), V2Path)
+
+	if doc["swagger"] != "2.0" {
+		t.Errorf("expected swagger 2.0, got %v", doc["swagger"])
+	}
+
+	definitions := doc["definitions"].(map[string]interface{})
+	for _, name := range []string{"v1alpha1.Cluster", "response.ListResult", "openapi.clusterList", "object"} {
+		if _, ok := definitions[name]; !ok {
+			t.Errorf("definition %s not found", name)
+		}
+	}
+
+	creationTimestamp := definitions["v1.ObjectMeta"].(map[string]interface{})["properties"].(map[string]interface{})["creationTimestamp"].(map[string]interface{})
+	if creationTimestamp["type"] != "string" {
+		t.Errorf("expected creationTimestamp to be a string, got %v", creationTimestamp)
+	}
+
+	if items := definitions["response.ListResult.items"].(map[string]interface{}); items["type"] != "object" {
+		t.Errorf("expected items of response.ListResult to be objects, got %v", items)
+	}
+
+	listItems := definitions["openapi.clusterList"].(map[string]interface{})["properties"].(map[string]interface{})["items"].(map[string]interface{})
+	if ref := listItems["items"].(map[string]interface{})["$ref"]; ref != "#/definitions/v1alpha1.Cluster" {
+		t.Errorf("expected items of cluster list refer to v1alpha1.Cluster, got %v", ref)
+	}
+
+	listResult := definitions["response.ListResult"].(map[string]interface{})["properties"].(map[string]interface{})
+	for _, field := range []string{"items", "totalItems", "pageSize", "totalPages", "currentPage"} {
+		if _, ok := listResult[field]; !ok {
+			t.Errorf("field %s of response.ListResult not found", field)
+		}
+	}
+}
+
+func TestOpenAPIV3(t *testing.T) {
+	doc := get(t, newContainer(), V3Path)
+
+	if doc["openapi"] != "3.0.0" {
+		t.Errorf("expected openapi 3.0.0, got %v", doc["openapi"])
+	}
+
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	if _, ok := schemas["v1alpha1.Cluster"]; !ok {
+		t.Errorf("schema v1alpha1.Cluster not found")
+	}
+
+	paths := doc["paths"].(map[string]interface{})
+	if _, ok := paths["/capis/cluster.captain.io/v1alpha1/clusters"]; !ok {
+		t.Errorf("path of clusters not found")
+	}
+}
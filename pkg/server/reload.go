@@ -0,0 +1,142 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog"
+
+	captainserverconfig "captain/pkg/server/config"
+	"captain/pkg/server/logging"
+	captaincache "captain/pkg/simple/client/cache"
+)
+
+// ApplyConfig applies configuration changes to the running server without restarting it.
+// Log verbosity, redis connection and multicluster mode are applied in place, changes of
+// other options only take effect after restart and are reported as warnings.
+// Options failed to be applied are left as they were, and returned as error.
+func (s *CaptainAPIServer) ApplyConfig(conf *captainserverconfig.Config) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	// applied is the configuration in effect, updated with every change applied successfully
+	applied := *s.Config
+	var errs []error
+
+	if conf.LoggingOptions != nil && !reflect.DeepEqual(applied.LoggingOptions, conf.LoggingOptions) {
+		if err := s.applyLogging(conf.LoggingOptions); err != nil {
+			errs = append(errs, err)
+		} else {
+			applied.LoggingOptions = conf.LoggingOptions
+		}
+	}
+
+	if conf.RedisOptions != nil && !reflect.DeepEqual(applied.RedisOptions, conf.RedisOptions) {
+		if err := s.applyRedis(conf.RedisOptions); err != nil {
+			errs = append(errs, err)
+		} else {
+			applied.RedisOptions = conf.RedisOptions
+		}
+	}
+
+	if conf.MultiClusterOptions != nil && applied.MultiClusterOptions != nil {
+		multiClusterOptions := *applied.MultiClusterOptions
+		if multiClusterOptions.Enable != conf.MultiClusterOptions.Enable {
+			s.applyMultiCluster(conf.MultiClusterOptions.Enable)
+			multiClusterOptions.Enable = conf.MultiClusterOptions.Enable
+		}
+		// resync period is used by cluster controller of controller-manager, which applies the
+		// change by itself, there is nothing to do in captain-server
+		multiClusterOptions.ClusterControllerResyncPeriod = conf.MultiClusterOptions.ClusterControllerResyncPeriod
+		applied.MultiClusterOptions = &multiClusterOptions
+	}
+
+	for _, name := range changedOptions(&applied, conf) {
+		klog.Warningf("configuration %s changed, restart captain-server to apply the change", name)
+	}
+
+	s.Config = &applied
+	return utilerrors.NewAggregate(errs)
+}
+
+func (s *CaptainAPIServer) applyLogging(o *logging.Options) error {
+	if errs := o.Validate(); len(errs) != 0 {
+		return fmt.Errorf("invalid logging configuration: %v", utilerrors.NewAggregate(errs))
+	}
+	if err := logging.SetVerbosity(o.Verbosity); err != nil {
+		return fmt.Errorf("failed to set log verbosity: %v", err)
+	}
+	klog.V(0).Infof("Log verbosity changed to %d", o.Verbosity)
+	return nil
+}
+
+// applyRedis connects to the new redis and replaces the current client, the current client is
+// kept if the new redis is not reachable
+func (s *CaptainAPIServer) applyRedis(o *captaincache.Options) error {
+	var client captaincache.Interface
+	if len(o.Host) != 0 {
+		if errs := o.Validate(); len(errs) != 0 {
+			return fmt.Errorf("invalid redis configuration: %v", utilerrors.NewAggregate(errs))
+		}
+		c, err := captaincache.NewRedisClient(o, s.stopCh)
+		if err != nil {
+			return fmt.Errorf("failed to connect to redis %s:%d, error: %v", o.Host, o.Port, err)
+		}
+		client = c
+	}
+
+	s.cacheMu.Lock()
+	old := s.CacheClient
+	s.CacheClient = client
+	s.cacheMu.Unlock()
+
+	if closer, ok := old.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			klog.Warningf("failed to close previous redis client, error: %v", err)
+		}
+	}
+
+	if client == nil {
+		klog.V(0).Info("Redis is disabled")
+	} else {
+		klog.V(0).Infof("Reconnected to redis %s:%d", o.Host, o.Port)
+	}
+	return nil
+}
+
+// applyMultiCluster starts or stops dispatching requests to member clusters
+func (s *CaptainAPIServer) applyMultiCluster(enable bool) {
+	if !enable {
+		s.dispatcher.Set(nil)
+		klog.V(0).Info("Multicluster mode is disabled")
+		return
+	}
+
+	s.dispatcher.Set(s.newClusterDispatch())
+	// cluster informer is not started if multicluster mode was disabled at startup
+	s.InformerFactory.CaptainSharedInformerFactory().Start(s.stopCh)
+	klog.V(0).Info("Multicluster mode is enabled")
+}
+
+// changedOptions returns json names of options which differ between current and desired,
+// options missing in desired are ignored
+func changedOptions(current, desired *captainserverconfig.Config) []string {
+	var changed []string
+
+	c := reflect.Indirect(reflect.ValueOf(current))
+	d := reflect.Indirect(reflect.ValueOf(desired))
+	for i := 0; i < c.NumField(); i++ {
+		name := strings.Split(c.Type().Field(i).Tag.Get("json"), ",")[0]
+		if d.Field(i).IsNil() {
+			continue
+		}
+		if !reflect.DeepEqual(c.Field(i).Interface(), d.Field(i).Interface()) {
+			changed = append(changed, name)
+		}
+	}
+
+	return changed
+}
@@ -190,6 +190,12 @@ func (r *RequestInfoFactory) NewRequestInfo(req *http.Request) (*RequestInfo, er
 		}
 	}
 
+	// URL forms of resources.captain.io: /aggregated/*, lists aggregated from clusters are resolved
+	// the same as lists of a cluster, so that they are authorized by resources and namespaces
+	if requestInfo.APIGroup == captainResourcesGroup && len(currentParts) > 1 && currentParts[0] == "aggregated" {
+		currentParts = currentParts[1:]
+	}
+
 	// URL forms: /workspaces/{workspace}/*
 	if currentParts[0] == "workspaces" {
 		if len(currentParts) > 1 {
@@ -226,6 +232,15 @@ func (r *RequestInfoFactory) NewRequestInfo(req *http.Request) (*RequestInfo, er
 		requestInfo.DevOps = metav1.NamespaceNone
 	}
 
+	// URL forms of resources.captain.io: /resources/{resource}/name/{name}, strip the fixed
+	// segments so that parts are adjusted to be relative to resource like kubernetes apis
+	if requestInfo.APIGroup == captainResourcesGroup && len(currentParts) > 1 && currentParts[0] == "resources" {
+		currentParts = currentParts[1:]
+		if len(currentParts) > 2 && currentParts[1] == "name" {
+			currentParts = append([]string{currentParts[0]}, currentParts[2:]...)
+		}
+	}
+
 	// parsing successful, so we now know the proper value for .Parts
 	requestInfo.Parts = currentParts
 
@@ -346,6 +361,8 @@ const (
 	NamespaceScope          = "Namespace"
 	DevOpsScope             = "DevOps"
 	workspaceSelectorPrefix = constants.WorkspaceLabelKey + "="
+
+	captainResourcesGroup = "resources.captain.io"
 )
 
 func (r *RequestInfoFactory) resolveResourceScope(request RequestInfo) string {
@@ -171,6 +171,49 @@ func TestRequestInfoFactory_NewRequestInfo(t *testing.T) {
 			expectedIsResourceRequest: true,
 			expectedResource:          "workspaces",
 		},
+		{
+			name:                      "list pods of resources.captain.io",
+			url:                       "/capis/resources.captain.io/alpha1/namespaces/default/resources/pods",
+			method:                    http.MethodGet,
+			expectedErr:               nil,
+			expectedVerb:              "list",
+			expectedResource:          "pods",
+			expectedIsResourceRequest: true,
+			expectedNamespace:         "default",
+			expectedKubernetesRequest: false,
+		},
+		{
+			name:                      "get node of resources.captain.io in cluster gondor",
+			url:                       "/regions/middle-earth/clusters/gondor/capis/resources.captain.io/alpha1/resources/nodes/name/node1",
+			method:                    http.MethodGet,
+			expectedErr:               nil,
+			expectedVerb:              "get",
+			expectedResource:          "nodes",
+			expectedIsResourceRequest: true,
+			expectedCluster:           "gondor",
+			expectedKubernetesRequest: false,
+		},
+		{
+			name:                      "list pods of resources.captain.io aggregated from clusters",
+			url:                       "/capis/resources.captain.io/alpha1/aggregated/resources/pods",
+			method:                    http.MethodGet,
+			expectedErr:               nil,
+			expectedVerb:              "list",
+			expectedResource:          "pods",
+			expectedIsResourceRequest: true,
+			expectedKubernetesRequest: false,
+		},
+		{
+			name:                      "list pods of namespace prod aggregated from clusters",
+			url:                       "/capis/resources.captain.io/alpha1/aggregated/namespaces/prod/resources/pods",
+			method:                    http.MethodGet,
+			expectedErr:               nil,
+			expectedVerb:              "list",
+			expectedResource:          "pods",
+			expectedIsResourceRequest: true,
+			expectedNamespace:         "prod",
+			expectedKubernetesRequest: false,
+		},
 		{
 			name:                      "captain api without clusters",
 			url:                       "/capis/foo/bar/",
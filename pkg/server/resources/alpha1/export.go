@@ -0,0 +1,108 @@
+package alpha1
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"captain/pkg/api"
+	"captain/pkg/bussiness/kube-resources/alpha1/exporters"
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog"
+)
+
+// exportChunkSize is the number of objects listed at a time by exports of whole lists, so that
+// objects are projected and encoded by chunks instead of all at once
+const exportChunkSize = 500
+
+// listFunc lists a page of objects matching q
+type listFunc func(q *query.QueryInfo) (*response.ListResult, error)
+
+func (h *Handler) lister(request *restful.Request, region, cluster, resourceType, namespace string) listFunc {
+	return func(q *query.QueryInfo) (*response.ListResult, error) {
+		return h.resourceProviderAlpha1.List(request.Request.Context(), region, cluster, resourceType, namespace, q)
+	}
+}
+
+func (h *Handler) aggregatedLister(request *restful.Request, region, resourceType, namespace string) listFunc {
+	return func(q *query.QueryInfo) (*response.ListResult, error) {
+		return h.resourceProviderAlpha1.AggregatedList(request.Request.Context(), region, resourceType, namespace, q)
+	}
+}
+
+// isWholeExport returns true if an export is not paged by the request, every object is exported
+// by chunks then
+func isWholeExport(request *restful.Request) bool {
+	for _, parameter := range []string{query.ParameterPage, query.ParameterPageSize, query.ParameterLimit, query.ParameterContinue} {
+		if len(request.QueryParameter(parameter)) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// exportChunks prepares q of exporting every object, and returns the listFunc of the following
+// chunks. Lists of informer caches, member clusters and aggregated lists are chunked by continue tokens
+func exportChunks(q *query.QueryInfo, list listFunc) listFunc {
+	q.Limit = exportChunkSize
+	return list
+}
+
+// writeExport streams result in format. The following chunks are listed by next and streamed until
+// there are no more objects if next is not nil. Errors of member clusters are sent as Warning
+// headers, and errors after the response is started end the stream, since the status is sent
+func writeExport(request *restful.Request, resp *restful.Response, resourceType string, format exporters.Format,
+	q *query.QueryInfo, result *response.ListResult, aggregated bool, next listFunc) {
+	writer, err := exporters.NewWriter(format, resp, q.Fields, aggregated)
+	if err != nil {
+		api.HandleInternalError(resp, request, err)
+		return
+	}
+
+	header := resp.Header()
+	header.Set("Content-Type", format.ContentType())
+	header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", resourceType+"."+string(format)))
+	for _, clusterError := range result.ClusterErrors {
+		cluster := clusterError.Cluster
+		if len(clusterError.Region) != 0 {
+			cluster = clusterError.Region + "/" + cluster
+		}
+		header.Add("Warning", "299 - "+strconv.Quote(fmt.Sprintf("objects of cluster %s are absent, %s", cluster, clusterError.Error)))
+	}
+	resp.WriteHeader(http.StatusOK)
+
+	flusher, _ := resp.ResponseWriter.(http.Flusher)
+	for {
+		for _, item := range result.Items {
+			obj, ok := item.(runtime.Object)
+			if !ok {
+				klog.Errorf("unexpected item %T of %s", item, resourceType)
+				return
+			}
+			if err := writer.Write(obj); err != nil {
+				klog.V(4).Info(err)
+				return
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			klog.V(4).Info(err)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if next == nil || len(result.Continue) == 0 || request.Request.Context().Err() != nil {
+			return
+		}
+		q.Continue = result.Continue
+		if result, err = next(q); err != nil {
+			klog.Error(err, resourceType)
+			return
+		}
+	}
+}
@@ -1,9 +1,15 @@
 package alpha1
 
 import (
+	"errors"
+
 	"captain/pkg/api"
+	kuberesalpha1 "captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/bussiness/kube-resources/alpha1/exporters"
+	"captain/pkg/bussiness/kube-resources/alpha1/printers"
 	"captain/pkg/bussiness/kube-resources/alpha1/resource"
 	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
 
 	"github.com/emicklei/go-restful"
 	"k8s.io/klog"
@@ -26,16 +32,40 @@ func (h *Handler) handleListResources(request *restful.Request, response *restfu
 	cluster := request.PathParameter("cluster")
 	resourceType := request.PathParameter("resources")
 	namespace := request.PathParameter("namespace")
+	if query.Watch {
+		h.handleWatchResources(request, response, query)
+		return
+	}
+	format, err := exporters.Negotiate(query.Format, request.HeaderParameter("Accept"))
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+	asTable := format == exporters.FormatJSON && printers.IsTableRequest(request.HeaderParameter("Accept"))
+	if asTable {
+		// objects are printed by columns of tables instead of projected
+		query.Fields = nil
+	}
 
-	result, err := h.resourceProviderAlpha1.List(region, cluster, resourceType, namespace, query)
+	list := h.lister(request, region, cluster, resourceType, namespace)
+	var next listFunc
+	if format != exporters.FormatJSON && isWholeExport(request) {
+		next = exportChunks(query, list)
+	}
+	result, err := list(query)
 	if err == nil {
-		response.WriteEntity(result)
+		if format != exporters.FormatJSON {
+			writeExport(request, response, resourceType, format, query, result, false, next)
+			return
+		}
+		writeList(request, response, resourceType, result, asTable, false)
 		return
 	}
 
 	if err != resource.ErrResourceNotSupported {
 		klog.Error(err, resourceType)
-		api.HandleInternalError(response, request, err)
+		// e.g. invalid or expired continue token
+		api.HandleError(response, request, err)
 		return
 	}
 
@@ -50,19 +80,85 @@ func (h *Handler) handleListResources(request *restful.Request, response *restfu
 	// 	api.HandleError(response, request, err)
 	// 	return
 	// }
-	response.WriteEntity(result)
+	api.HandleNotFound(response, request, err)
+}
+
+// handleAggregatedListResources retrieves resources from every cluster, or every cluster of region
+func (h *Handler) handleAggregatedListResources(request *restful.Request, response *restful.Response) {
+	query := query.ParseQueryParameter(request)
+	region := request.PathParameter("region")
+	resourceType := request.PathParameter("resources")
+	namespace := request.PathParameter("namespace")
+	if query.Watch {
+		api.HandleBadRequest(response, request, errors.New("watch is not supported by aggregated lists, watch clusters respectively"))
+		return
+	}
+	format, err := exporters.Negotiate(query.Format, request.HeaderParameter("Accept"))
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+	asTable := format == exporters.FormatJSON && printers.IsTableRequest(request.HeaderParameter("Accept"))
+	if asTable {
+		query.Fields = nil
+	}
+
+	list := h.aggregatedLister(request, region, resourceType, namespace)
+	var next listFunc
+	if format != exporters.FormatJSON && isWholeExport(request) {
+		next = exportChunks(query, list)
+	}
+	result, err := list(query)
+	if err != nil {
+		klog.Error(err, resourceType)
+		if err == resource.ErrResourceNotSupported {
+			api.HandleNotFound(response, request, err)
+			return
+		}
+		api.HandleError(response, request, err)
+		return
+	}
+	if format != exporters.FormatJSON {
+		writeExport(request, response, resourceType, format, query, result, true, next)
+		return
+	}
+	writeList(request, response, resourceType, result, asTable, true)
+}
+
+// writeList writes result of listing resources, as a metav1.Table if asTable, with a Cluster column
+// if objects are aggregated from clusters
+func writeList(request *restful.Request, resp *restful.Response, resourceType string, result *response.ListResult, asTable, aggregated bool) {
+	if !asTable {
+		resp.WriteEntity(result)
+		return
+	}
+	table, err := printers.Table(resourceType, result.Items, aggregated)
+	if err != nil {
+		klog.Error(err, resourceType)
+		api.HandleInternalError(resp, request, err)
+		return
+	}
+	table.Continue = result.Continue
+	table.RemainingItemCount = result.RemainingItemCount
+	resp.WriteEntity(table)
 }
 
 func (h *Handler) handleGetResource(request *restful.Request, response *restful.Response) {
 	region := request.PathParameter("region")
 	cluster := request.PathParameter("cluster")
-	resource := request.PathParameter("resources")
+	resourceType := request.PathParameter("resources")
 	namespace := request.PathParameter("namespace")
 	name := request.PathParameter("name")
-	result, err := h.resourceProviderAlpha1.Get(region, cluster, resource, namespace, name)
+	result, err := h.resourceProviderAlpha1.Get(request.Request.Context(), region, cluster, resourceType, namespace, name)
 	if err != nil {
-		response.WriteEntity(result)
+		klog.Error(err, resourceType)
+		if err == resource.ErrResourceNotSupported {
+			api.HandleNotFound(response, request, err)
+			return
+		}
+		// e.g. objects not found, or caches of member clusters not synced in time
+		api.HandleError(response, request, err)
 		return
 	}
-	response.WriteEntity(result)
+	response.WriteEntity(kuberesalpha1.ProjectFunc(query.ParseQueryParameter(request))(result))
 }
@@ -1,6 +1,7 @@
 package alpha1
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -9,6 +10,8 @@ import (
 	"captain/pkg/test/fake"
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
+	"captain/pkg/utils/clustercache"
+	"captain/pkg/utils/clusterclient"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -145,10 +148,11 @@ func TestHandleListResources(t *testing.T) {
 		t.Fatalf(err.Error())
 	}
 
-	handler := New(resource.NewResourceProcessor(factory, nil))
+	clients := clusterclient.NewClusterClients(factory.CaptainSharedInformerFactory().Cluster().V1alpha1().Clusters(), nil)
+	handler := New(resource.NewResourceProcessor(factory, nil, clients, clustercache.NewManager(clients, clustercache.DefaultIdleTimeout), nil, nil, nil))
 
 	for _, test := range tests {
-		res, err := handler.resourceProviderAlpha1.List("", "", test.resource, test.namespace, test.query)
+		res, err := handler.resourceProviderAlpha1.List(context.Background(), "", "", test.resource, test.namespace, test.query)
 		if err != nil {
 			t.Errorf("failed with %s", err.Error())
 		}
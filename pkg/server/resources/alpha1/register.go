@@ -1,18 +1,28 @@
 package alpha1
 
 import (
-	"captain/pkg/api"
+	"captain/pkg/bussiness/kube-resources/alpha1/exporters"
 	"captain/pkg/bussiness/kube-resources/alpha1/resource"
+	"captain/pkg/bussiness/kube-resources/alpha1/search"
 	"captain/pkg/informers"
+	"captain/pkg/server/authorization/authorizer"
 	"captain/pkg/server/runtime"
 	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+	"captain/pkg/utils/clustercache"
+	"captain/pkg/utils/clusterclient"
+	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/emicklei/go-restful"
 	restfulspec "github.com/emicklei/go-restful-openapi"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 
 	// "github.com/rogpeppe/go-internal/cache"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -20,6 +30,19 @@ const (
 	GroupName            = "resources.captain.io"
 	ok                   = "success"
 	tagClusteredResource = "Resources in cluster scope"
+
+	// metadataFieldSelectors is the route metadata of fields supported by field selectors of every resource
+	metadataFieldSelectors = "fieldSelectors"
+
+	mimeEventStream = "text/event-stream"
+)
+
+var (
+	// listMIMETypes are media types of lists, objects are exported in formats other than JSON
+	listMIMETypes = append([]string{restful.MIME_JSON}, exporters.MIMETypes...)
+
+	// watchableListMIMETypes are media types of lists that can be watched by server-sent events
+	watchableListMIMETypes = append([]string{restful.MIME_JSON, mimeEventStream}, exporters.MIMETypes...)
 )
 
 var GroupVersion = schema.GroupVersion{Group: GroupName, Version: "alpha1"}
@@ -28,48 +51,80 @@ func Resource(resource string) schema.GroupResource {
 	return GroupVersion.WithResource(resource).GroupResource()
 }
 
-func AddToContainer(c *restful.Container, factory informers.CapInformerFactory, cache cache.Cache) error {
+// AddToContainer installs apis of kube resources, objects of member clusters are read from caches by clients.
+// The search api is installed if searchIndexer is not nil, hits are filtered by authorizer unless it is nil.
+// Objects are written to the host cluster by hostConfig, on behalf of requesting users by impersonate,
+// writes are forbidden if it is nil.
+// Resources without typed providers, e.g. CRDs, are served by dynamic informers running until stopCh is closed
+func AddToContainer(c *restful.Container, factory informers.CapInformerFactory, cache cache.Cache, clients clusterclient.ClusterClients,
+	caches *clustercache.Manager, hostConfig *rest.Config, impersonate resource.ImpersonateFunc, searchIndexer *search.Indexer,
+	authorizer authorizer.Authorizer, stopCh <-chan struct{}) error {
 	webservice := runtime.NewWebService(GroupVersion)
-	handler := New(resource.NewResourceProcessor(factory, cache))
+	processor := resource.NewResourceProcessor(factory, cache, clients, caches, hostConfig, impersonate, stopCh)
+	handler := New(processor)
+	supportedFields := processor.SupportedFields()
 
-	webservice.Route(webservice.GET("/namespaces/{namespace}/resources/{resources}").
+	webservice.Route(listParams(webservice, webservice.GET("/namespaces/{namespace}/resources/{resources}"), supportedFields, true).
 		To(handler.handleListResources).
+		Produces(watchableListMIMETypes...).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
 		Doc("Cluster level resources").
-		Param(webservice.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services.")).
+		Param(webservice.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services. Any resource of clusters, e.g. CRDs, is supported, by resource.group or resource.version.group if ambiguous, e.g. virtualservices.networking.istio.io")).
 		Param(webservice.PathParameter("namespace", "namespace")).
-		Param(webservice.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
-		Param(webservice.QueryParameter(query.ParameterPage, "page, which is started with 1 not 0, default value is 1.").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
-		Param(webservice.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
-		Param(webservice.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
-		Param(webservice.QueryParameter(query.ParameterOrderBy, "sort parameters, e.g. orderBy=createTime")).
-		Returns(http.StatusOK, ok, api.ListResult{}))
-	webservice.Route(webservice.GET("resources/{resources}").
+		Returns(http.StatusOK, ok, response.ListResult{}))
+	webservice.Route(listParams(webservice, webservice.GET("resources/{resources}"), supportedFields, true).
 		To(handler.handleListResources).
+		Produces(watchableListMIMETypes...).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
 		Doc("core level resources").
-		Param(webservice.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes.")).
-		Param(webservice.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
-		Param(webservice.QueryParameter(query.ParameterPage, "page, which is started with 1 not 0, default value is 1.").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
-		Param(webservice.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
-		Param(webservice.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
-		Param(webservice.QueryParameter(query.ParameterOrderBy, "sort parameters, e.g. orderBy=createTime")).
-		Returns(http.StatusOK, ok, api.ListResult{}))
-	webservice.Route(webservice.GET("/namespaces/{namespace}/resources/{resources}/name/{name}").
+		Param(webservice.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes. Any resource of clusters, e.g. CRDs, is supported, by resource.group or resource.version.group if ambiguous, e.g. clusters.cluster.karmada.io")).
+		Returns(http.StatusOK, ok, response.ListResult{}))
+	// objects aggregated from every cluster
+	webservice.Route(listParams(webservice, webservice.GET("/aggregated/namespaces/{namespace}/resources/{resources}"), supportedFields, false).
+		To(handler.handleAggregatedListResources).
+		Produces(listMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+		Doc("Namespace level resources aggregated from clusters").
+		Param(webservice.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services. Any resource of clusters, e.g. CRDs, is supported, by resource.group or resource.version.group if ambiguous, e.g. virtualservices.networking.istio.io")).
+		Param(webservice.PathParameter("namespace", "namespace")).
+		Returns(http.StatusOK, ok, response.ListResult{}))
+	webservice.Route(listParams(webservice, webservice.GET("/aggregated/resources/{resources}"), supportedFields, false).
+		To(handler.handleAggregatedListResources).
+		Produces(listMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+		Doc("Cluster level resources aggregated from clusters").
+		Param(webservice.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes. Any resource of clusters, e.g. CRDs, is supported, by resource.group or resource.version.group if ambiguous, e.g. clusters.cluster.karmada.io")).
+		Returns(http.StatusOK, ok, response.ListResult{}))
+	webservice.Route(objectParams(webservice, webservice.GET("/namespaces/{namespace}/resources/{resources}/name/{name}")).
 		To(handler.handleGetResource).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
 		Doc("Cluster level resources").
-		Param(webservice.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services.")).
+		Param(webservice.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services. Any resource of clusters, e.g. CRDs, is supported, by resource.group or resource.version.group if ambiguous, e.g. virtualservices.networking.istio.io")).
 		Param(webservice.PathParameter("namespace", "namespace of resources")).
 		Param(webservice.PathParameter("name", "name of resources")).
-		Returns(http.StatusOK, ok, api.ListResult{}))
-	webservice.Route(webservice.GET("resources/{resources}/name/{name}").
+		Returns(http.StatusOK, ok, map[string]interface{}{}))
+	webservice.Route(objectParams(webservice, webservice.GET("resources/{resources}/name/{name}")).
 		To(handler.handleGetResource).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
 		Doc("Cluster level resources").
-		Param(webservice.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes.")).
+		Param(webservice.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes. Any resource of clusters, e.g. CRDs, is supported, by resource.group or resource.version.group if ambiguous, e.g. clusters.cluster.karmada.io")).
 		Param(webservice.PathParameter("name", "name of resources")).
-		Returns(http.StatusOK, ok, api.ListResult{}))
+		Returns(http.StatusOK, ok, map[string]interface{}{}))
+	addWriteRoutes(webservice, "", handler)
+	if searchIndexer != nil {
+		searchHandler := &searchHandler{indexer: searchIndexer, authorizer: authorizer}
+		webservice.Route(webservice.GET("/search").
+			To(searchHandler.handleSearch).
+			Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+			Doc("Search resources of every cluster by partial names, labels and annotations, only objects the user is authorized to list are returned").
+			Param(webservice.QueryParameter(search.ParameterQuery, "words searched, objects with names, namespaces, labels or annotations matching every word are returned, e.g. q=nginx app=web. Words match exactly, by prefix or by substring if they have 3 characters at least, and objects are ranked by matches").Required(false)).
+			Param(webservice.QueryParameter(search.ParameterKind, "kind or resource of objects, e.g. Deployment or deployments").Required(false)).
+			Param(webservice.QueryParameter(search.ParameterCluster, "cluster of objects, e.g. region/cluster").Required(false)).
+			Param(webservice.QueryParameter(search.ParameterNamespace, "namespace of objects").Required(false)).
+			Param(webservice.QueryParameter(query.ParameterPage, "page, which is started with 1 not 0, default value is 1.").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
+			Param(webservice.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
+			Returns(http.StatusOK, ok, search.Result{}))
+	}
 
 	c.Add(webservice)
 
@@ -77,55 +132,176 @@ func AddToContainer(c *restful.Container, factory informers.CapInformerFactory,
 	webservice2 := &restful.WebService{}
 	webservice2.Path("/regions").Produces(restful.MIME_JSON)
 	urlPrefix := "{region}/clusters/{cluster}/capis/" + GroupVersion.String()
+	regionPrefix := "{region}/capis/" + GroupVersion.String()
 
-	webservice2.Route(webservice2.GET(urlPrefix+"/namespaces/{namespace}/resources/{resources}").
+	webservice2.Route(listParams(webservice2, webservice2.GET(urlPrefix+"/namespaces/{namespace}/resources/{resources}"), supportedFields, true).
 		To(handler.handleListResources).
+		Produces(watchableListMIMETypes...).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
 		Doc("Cluster level resources").
 		Param(webservice2.PathParameter("region", "region id of cluster")).
 		Param(webservice2.PathParameter("cluster", "name of cluster")).
-		Param(webservice2.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services.")).
+		Param(webservice2.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services. Any resource of clusters, e.g. CRDs, is supported, by resource.group or resource.version.group if ambiguous, e.g. virtualservices.networking.istio.io")).
 		Param(webservice2.PathParameter("namespace", "namespace")).
-		Param(webservice2.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
-		Param(webservice2.QueryParameter(query.ParameterPage, "page, which is started with 1 not 0, default value is 1.").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
-		Param(webservice2.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
-		Param(webservice2.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
-		Param(webservice2.QueryParameter(query.ParameterOrderBy, "sort parameters, e.g. orderBy=createTime")).
-		Returns(http.StatusOK, ok, api.ListResult{}))
-	webservice2.Route(webservice2.GET(urlPrefix+"/resources/{resources}").
+		Returns(http.StatusOK, ok, response.ListResult{}))
+	webservice2.Route(listParams(webservice2, webservice2.GET(urlPrefix+"/resources/{resources}"), supportedFields, true).
 		To(handler.handleListResources).
+		Produces(watchableListMIMETypes...).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
 		Doc("core level resources").
 		Param(webservice2.PathParameter("region", "region id of cluster")).
 		Param(webservice2.PathParameter("cluster", "name of cluster")).
-		Param(webservice2.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes.")).
-		Param(webservice2.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
-		Param(webservice2.QueryParameter(query.ParameterPage, "page, which is started with 1 not 0, default value is 1.").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
-		Param(webservice2.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
-		Param(webservice2.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
-		Param(webservice2.QueryParameter(query.ParameterOrderBy, "sort parameters, e.g. orderBy=createTime")).
-		Returns(http.StatusOK, ok, api.ListResult{}))
-	webservice2.Route(webservice2.GET(urlPrefix+"/namespaces/{namespace}/resources/{resources}/name/{name}").
+		Param(webservice2.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes. Any resource of clusters, e.g. CRDs, is supported, by resource.group or resource.version.group if ambiguous, e.g. clusters.cluster.karmada.io")).
+		Returns(http.StatusOK, ok, response.ListResult{}))
+	webservice2.Route(objectParams(webservice2, webservice2.GET(urlPrefix+"/namespaces/{namespace}/resources/{resources}/name/{name}")).
 		To(handler.handleGetResource).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
 		Doc("Cluster level resources").
 		Param(webservice2.PathParameter("region", "region id of cluster")).
 		Param(webservice2.PathParameter("cluster", "name of cluster")).
-		Param(webservice2.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services.")).
+		Param(webservice2.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services. Any resource of clusters, e.g. CRDs, is supported, by resource.group or resource.version.group if ambiguous, e.g. virtualservices.networking.istio.io")).
 		Param(webservice2.PathParameter("namespace", "namespace of resources")).
 		Param(webservice2.PathParameter("name", "name of resources")).
-		Returns(http.StatusOK, ok, api.ListResult{}))
-	webservice2.Route(webservice2.GET(urlPrefix+"/resources/{resources}/name/{name}").
+		Returns(http.StatusOK, ok, map[string]interface{}{}))
+	webservice2.Route(objectParams(webservice2, webservice2.GET(urlPrefix+"/resources/{resources}/name/{name}")).
 		To(handler.handleGetResource).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
 		Doc("Cluster level resources").
 		Param(webservice2.PathParameter("region", "region id of cluster")).
 		Param(webservice2.PathParameter("cluster", "name of cluster")).
-		Param(webservice2.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes.")).
+		Param(webservice2.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes. Any resource of clusters, e.g. CRDs, is supported, by resource.group or resource.version.group if ambiguous, e.g. clusters.cluster.karmada.io")).
 		Param(webservice2.PathParameter("name", "name of resources")).
-		Returns(http.StatusOK, ok, api.ListResult{}))
+		Returns(http.StatusOK, ok, map[string]interface{}{}))
+
+	// objects aggregated from every cluster of region
+	webservice2.Route(listParams(webservice2, webservice2.GET(regionPrefix+"/namespaces/{namespace}/resources/{resources}"), supportedFields, false).
+		To(handler.handleAggregatedListResources).
+		Produces(listMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+		Doc("Namespace level resources aggregated from clusters").
+		Param(webservice2.PathParameter("region", "region id of clusters")).
+		Param(webservice2.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services. Any resource of clusters, e.g. CRDs, is supported, by resource.group or resource.version.group if ambiguous, e.g. virtualservices.networking.istio.io")).
+		Param(webservice2.PathParameter("namespace", "namespace")).
+		Returns(http.StatusOK, ok, response.ListResult{}))
+	webservice2.Route(listParams(webservice2, webservice2.GET(regionPrefix+"/resources/{resources}"), supportedFields, false).
+		To(handler.handleAggregatedListResources).
+		Produces(listMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+		Doc("Cluster level resources aggregated from clusters").
+		Param(webservice2.PathParameter("region", "region id of clusters")).
+		Param(webservice2.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes. Any resource of clusters, e.g. CRDs, is supported, by resource.group or resource.version.group if ambiguous, e.g. clusters.cluster.karmada.io")).
+		Returns(http.StatusOK, ok, response.ListResult{}))
+
+	addWriteRoutes(webservice2, urlPrefix, handler)
 
 	c.Add(webservice2)
 
 	return nil
 }
+
+// addWriteRoutes installs routes of creating, updating, patching and deleting resources of namespaces
+// and clusters under prefix, region and cluster are path parameters of prefix if it is not empty
+func addWriteRoutes(ws *restful.WebService, prefix string, handler *Handler) {
+	for _, scope := range []struct {
+		path, doc, resources string
+		namespaced           bool
+	}{
+		{"/namespaces/{namespace}/resources/{resources}", "namespace level resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services.", true},
+		{"/resources/{resources}", "cluster level resources", "cluster scope resource type, e.g: namespaces,nodes.", false},
+	} {
+		params := func(builder *restful.RouteBuilder, named bool) *restful.RouteBuilder {
+			if len(prefix) != 0 {
+				builder.Param(ws.PathParameter("region", "region id of cluster")).
+					Param(ws.PathParameter("cluster", "name of cluster"))
+			}
+			builder.Param(ws.PathParameter("resources", scope.resources))
+			if scope.namespaced {
+				builder.Param(ws.PathParameter("namespace", "namespace of resources"))
+			}
+			if named {
+				builder.Param(ws.PathParameter("name", "name of resources"))
+			}
+			return builder.Param(ws.QueryParameter(resource.ParameterDryRun, "dryRun=All processes the request without persisting objects, the same as kubernetes").Required(false))
+		}
+
+		ws.Route(params(ws.POST(prefix+scope.path), false).
+			To(handler.handleCreateResource).
+			Consumes(restful.MIME_JSON, mimeYAML).
+			Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+			Doc("Create "+scope.doc+", apiVersion, kind and namespace of objects are set by the path if they are empty").
+			Param(ws.QueryParameter(resource.ParameterFieldManager, "name of the manager of fields written").Required(false)).
+			Reads(map[string]interface{}{}).
+			Returns(http.StatusCreated, ok, map[string]interface{}{}))
+		ws.Route(params(ws.PUT(prefix+scope.path+"/name/{name}"), true).
+			To(handler.handleUpdateResource).
+			Consumes(restful.MIME_JSON, mimeYAML).
+			Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+			Doc("Update "+scope.doc+", metadata.resourceVersion of objects is checked if it is set").
+			Param(ws.QueryParameter(resource.ParameterFieldManager, "name of the manager of fields written").Required(false)).
+			Reads(map[string]interface{}{}).
+			Returns(http.StatusOK, ok, map[string]interface{}{}))
+		ws.Route(params(ws.PATCH(prefix+scope.path+"/name/{name}"), true).
+			To(handler.handlePatchResource).
+			Consumes(patchMIMETypes...).
+			Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+			Doc("Patch "+scope.doc+" by JSON merge patches, JSON patches, strategic merge patches or server-side apply, by Content-Type").
+			Param(ws.HeaderParameter("Content-Type", strings.Join(patchMIMETypes, ", ")).Required(true)).
+			Param(ws.QueryParameter(resource.ParameterFieldManager, "name of the manager of fields written, it is required by server-side apply").Required(false)).
+			Param(ws.QueryParameter(resource.ParameterForce, "force=true takes fields owned by other managers by server-side apply").Required(false).DataType("boolean")).
+			Reads(map[string]interface{}{}).
+			Returns(http.StatusOK, ok, map[string]interface{}{}))
+		ws.Route(params(ws.DELETE(prefix+scope.path+"/name/{name}"), true).
+			To(handler.handleDeleteResource).
+			Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+			Doc("Delete "+scope.doc).
+			Param(ws.QueryParameter(resource.ParameterPropagationPolicy, "Orphan, Background or Foreground, the same as kubernetes").Required(false)).
+			Param(ws.QueryParameter(resource.ParameterGracePeriodSeconds, "seconds before objects are deleted, 0 deletes objects immediately").Required(false).DataType("integer")).
+			Returns(http.StatusOK, ok, metav1.Status{}))
+	}
+}
+
+// listParams adds query parameters of listing resources to rb, and parameters of watching them if watchable.
+// Fields supported by field selectors are documented by supportedFields and kept as route metadata
+func listParams(ws *restful.WebService, rb *restful.RouteBuilder, supportedFields map[string][]string, watchable bool) *restful.RouteBuilder {
+	objectParams(ws, rb).
+		Param(ws.QueryParameter(query.ParameterName, "name used to do filtering, names containing it are matched, use prefix(name) or exact(name) to match by prefix or exactly. Any field can be filtered by field=value, repeated keys are ORed, and field!=value, field=in(a,b), field=notin(a,b) are supported").Required(false)).
+		Param(ws.QueryParameter(query.ParameterPage, "page, which is started with 1 not 0, default value is 1.").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
+		Param(ws.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
+		Param(ws.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
+		Param(ws.QueryParameter(query.ParameterOrderBy, "sort parameters, e.g. orderBy=createTime, or a JSONPath, e.g. sortBy=.status.readyReplicas")).
+		Param(ws.QueryParameter(query.ParameterFilter, "JSONPath filter <jsonpath><operator><value>, operator is one of == != > >= < <=, e.g. filter=.status.readyReplicas>=2. Numbers, quantities and timestamps are compared by value, repeated filters are ANDed").Required(false)).
+		Param(ws.QueryParameter(query.ParameterLimit, "maximum number of items returned, page and pageSize are ignored if set. A continue token is returned if there are more items. Objects of member clusters are paged from caches of captain as well").Required(false).DataType("integer")).
+		Param(ws.QueryParameter(query.ParameterContinue, "continue token returned by the previous page").Required(false)).
+		Param(ws.QueryParameter(query.ParameterFieldSelector, fieldSelectorDoc(supportedFields)).Required(false)).
+		Param(ws.HeaderParameter("Accept", "application/json;as=Table;g=meta.k8s.io;v=v1 returns a metav1.Table with the same columns as kubectl").Required(false)).
+		Param(ws.QueryParameter(query.ParameterFormat, "export objects as csv, yaml or ndjson, or by Accept of text/csv, application/yaml or application/x-ndjson. Every object is exported by chunks unless page, pageSize or limit is set. Columns of csv are fields, or namespaces, names and creation timestamps by default, yaml can be applied by kubectl unless fields are set").Required(false)).
+		Metadata(metadataFieldSelectors, supportedFields)
+	if watchable {
+		rb.Param(ws.QueryParameter(query.ParameterWatch, "stream ADDED, MODIFIED, DELETED events of resources instead of listing them, as server-sent events, or websocket messages if the connection is upgraded").Required(false).DataType("boolean").DefaultValue("false")).
+			Param(ws.QueryParameter(query.ParameterResourceVersion, "resourceVersion watches start after, current resources are sent as ADDED events first if not set. 410 Gone is sent if it is too old, Last-Event-ID of server-sent events is used if not set").Required(false)).
+			Param(ws.QueryParameter(query.ParameterAllowWatchBookmarks, "send BOOKMARK events of the latest resourceVersion periodically").Required(false).DataType("boolean").DefaultValue("false"))
+	}
+	return rb
+}
+
+// objectParams adds query parameters of fields of objects returned to rb
+func objectParams(ws *restful.WebService, rb *restful.RouteBuilder) *restful.RouteBuilder {
+	return rb.Param(ws.QueryParameter(query.ParameterFields, "fields returned, paths separated by dots, e.g. fields=metadata.name,metadata.namespace,status.phase. Whole objects are returned by default").Required(false)).
+		Param(ws.QueryParameter(query.ParameterShowManaged, "return managedFields and kubectl.kubernetes.io/last-applied-configuration annotation, they are stripped by default").Required(false).DataType("boolean").DefaultValue("false"))
+}
+
+// fieldSelectorDoc documents fields supported by field selectors of every resource
+func fieldSelectorDoc(supportedFields map[string][]string) string {
+	var resources []string
+	for resource := range supportedFields {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	var doc strings.Builder
+	doc.WriteString("field selector the same as kubernetes, e.g. spec.nodeName=n1,status.phase!=Running. Objects of every cluster are selected by captain, supported fields:")
+	for _, resource := range resources {
+		fmt.Fprintf(&doc, " %s: %s;", resource, strings.Join(supportedFields[resource], ","))
+	}
+	return doc.String()
+}
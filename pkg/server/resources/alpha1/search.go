@@ -0,0 +1,73 @@
+package alpha1
+
+import (
+	"captain/pkg/bussiness/kube-resources/alpha1/search"
+	"captain/pkg/server/authorization/authorizer"
+	"captain/pkg/server/request"
+	"captain/pkg/unify/query"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog"
+)
+
+type searchHandler struct {
+	indexer *search.Indexer
+
+	// authorizer filters hits by whether the requesting user may list them, nil means all hits are returned
+	authorizer authorizer.Authorizer
+}
+
+// handleSearch searches resources of every cluster from the index, only objects the user is authorized
+// to list are returned
+func (h *searchHandler) handleSearch(request *restful.Request, response *restful.Response) {
+	q := &search.Query{
+		Text:       request.QueryParameter(search.ParameterQuery),
+		Kind:       request.QueryParameter(search.ParameterKind),
+		Cluster:    request.QueryParameter(search.ParameterCluster),
+		Namespace:  request.QueryParameter(search.ParameterNamespace),
+		Pagination: query.ParseQueryParameter(request).Pagination,
+	}
+	if h.authorizer != nil {
+		q.Authorized = h.authorized(request)
+	}
+	response.WriteEntity(h.indexer.Search(q))
+}
+
+// listScope is the scope objects are authorized to be listed in
+type listScope struct {
+	region    string
+	cluster   string
+	namespace string
+	resource  schema.GroupResource
+}
+
+// authorized returns whether the user of req may list objects of a resource in a namespace of a
+// cluster, decisions are cached for the request, since hits of a scope share the same decision
+func (h *searchHandler) authorized(req *restful.Request) func(region, cluster, namespace string, resource schema.GroupResource) bool {
+	ctx := req.Request.Context()
+	u, _ := request.UserFrom(ctx)
+	decisions := make(map[listScope]bool)
+	return func(region, cluster, namespace string, resource schema.GroupResource) bool {
+		scope := listScope{region: region, cluster: cluster, namespace: namespace, resource: resource}
+		if allowed, ok := decisions[scope]; ok {
+			return allowed
+		}
+		attributes := authorizer.AttributesRecord{
+			User:            u,
+			Verb:            "list",
+			Region:          region,
+			Cluster:         cluster,
+			Namespace:       namespace,
+			APIGroup:        resource.Group,
+			Resource:        resource.Resource,
+			ResourceRequest: true,
+		}
+		decision, _, err := h.authorizer.Authorize(ctx, attributes)
+		if err != nil {
+			klog.V(4).Infof("authorize search hits of %v failed, %v", scope, err)
+		}
+		decisions[scope] = decision == authorizer.DecisionAllow
+		return decisions[scope]
+	}
+}
@@ -0,0 +1,161 @@
+package alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"captain/pkg/api"
+	"captain/pkg/bussiness/kube-resources/alpha1/resource"
+	"captain/pkg/unify/query"
+
+	"github.com/emicklei/go-restful"
+	"golang.org/x/net/websocket"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog"
+)
+
+// heartbeatInterval is the interval of comments written to server-sent events streams, so that
+// idle streams are not closed by proxies
+const heartbeatInterval = 30 * time.Second
+
+// watchEvent is an event written to clients, the same as watch events of kube-apiserver
+type watchEvent struct {
+	Type   watch.EventType `json:"type"`
+	Object runtime.Object  `json:"object"`
+}
+
+// handleWatchResources streams changes of resources, over websocket if the request asks for an
+// upgrade, or as server-sent events otherwise. Server-sent events resume from Last-Event-ID if
+// resourceVersion is not specified
+func (h *Handler) handleWatchResources(request *restful.Request, response *restful.Response, q *query.QueryInfo) {
+	region := request.PathParameter("region")
+	cluster := request.PathParameter("cluster")
+	resourceType := request.PathParameter("resources")
+	namespace := request.PathParameter("namespace")
+	if len(q.ResourceVersion) == 0 {
+		q.ResourceVersion = request.HeaderParameter("Last-Event-ID")
+	}
+
+	w, err := h.resourceProviderAlpha1.Watch(request.Request.Context(), region, cluster, resourceType, namespace, q)
+	if err != nil {
+		klog.Error(err, resourceType)
+		if err == resource.ErrResourceNotSupported {
+			api.HandleNotFound(response, request, err)
+			return
+		}
+		api.HandleError(response, request, err)
+		return
+	}
+	defer w.Stop()
+
+	if strings.EqualFold(request.HeaderParameter("Upgrade"), "websocket") {
+		websocket.Server{
+			Handshake: checkOrigin,
+			Handler: func(conn *websocket.Conn) {
+				serveWebsocket(conn, w)
+			},
+		}.ServeHTTP(response.ResponseWriter, request.Request)
+		return
+	}
+	serveEventStream(request, response, w)
+}
+
+// checkOrigin rejects websocket handshakes from pages of other origins, since browsers send credentials
+// of the server with cross-origin websocket requests. Clients other than browsers do not send Origin
+func checkOrigin(config *websocket.Config, req *http.Request) error {
+	origin, err := websocket.Origin(config, req)
+	if err != nil {
+		return err
+	}
+	if origin != nil && !strings.EqualFold(origin.Host, req.Host) {
+		return fmt.Errorf("origin %s is not allowed", origin)
+	}
+	config.Origin = origin
+	return nil
+}
+
+// serveWebsocket writes events as JSON messages until the watch ends or the client goes away
+func serveWebsocket(conn *websocket.Conn, w watch.Interface) {
+	closed := make(chan struct{})
+	go func() {
+		// messages from clients are ignored, reading fails once the connection is closed
+		io.Copy(ioutil.Discard, conn)
+		close(closed)
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			if err := websocket.JSON.Send(conn, watchEvent{Type: event.Type, Object: event.Object}); err != nil {
+				klog.V(4).Info(err)
+				return
+			}
+		}
+	}
+}
+
+// serveEventStream writes events as server-sent events, ids of events are resourceVersions of objects,
+// so that EventSource of browsers resume from them when reconnecting
+func serveEventStream(request *restful.Request, response *restful.Response, w watch.Interface) {
+	header := response.Header()
+	header.Set("Content-Type", mimeEventStream)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	response.WriteHeader(http.StatusOK)
+
+	flusher, _ := response.ResponseWriter.(http.Flusher)
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-request.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(response, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flush()
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			if err := writeEvent(response, event); err != nil {
+				klog.V(4).Info(err)
+				return
+			}
+			flush()
+		}
+	}
+}
+
+func writeEvent(writer io.Writer, event watch.Event) error {
+	data, err := json.Marshal(event.Object)
+	if err != nil {
+		return err
+	}
+	var id string
+	if o, err := meta.Accessor(event.Object); err == nil && len(o.GetResourceVersion()) != 0 {
+		id = fmt.Sprintf("id: %s\n", o.GetResourceVersion())
+	}
+	_, err = fmt.Fprintf(writer, "%sevent: %s\ndata: %s\n\n", id, event.Type, data)
+	return err
+}
@@ -0,0 +1,174 @@
+package alpha1
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"captain/pkg/api"
+	kuberesalpha1 "captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/bussiness/kube-resources/alpha1/resource"
+	"captain/pkg/unify/query"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog"
+)
+
+// maxRequestBodyBytes is the maximum size of objects written, the same as kube-apiserver
+const maxRequestBodyBytes = 3 * 1024 * 1024
+
+const (
+	mimeStrategicMergePatch = "application/strategic-merge-patch+json"
+	mimeApplyPatch          = "application/apply-patch+yaml"
+	mimeYAML                = "application/yaml"
+)
+
+// patchMIMETypes are media types of patches, they are the same as the patch types of kubernetes
+var patchMIMETypes = []string{string(types.MergePatchType), string(types.JSONPatchType), mimeStrategicMergePatch, mimeApplyPatch}
+
+func (h *Handler) handleCreateResource(request *restful.Request, response *restful.Response) {
+	region := request.PathParameter("region")
+	cluster := request.PathParameter("cluster")
+	resourceType := request.PathParameter("resources")
+	namespace := request.PathParameter("namespace")
+
+	obj, err := readObject(request)
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	opts := metav1.CreateOptions{
+		DryRun:       request.Request.URL.Query()[resource.ParameterDryRun],
+		FieldManager: request.QueryParameter(resource.ParameterFieldManager),
+	}
+	result, err := h.resourceProviderAlpha1.Create(request.Request.Context(), region, cluster, resourceType, namespace, obj, opts)
+	writeObject(request, response, resourceType, http.StatusCreated, result, err)
+}
+
+func (h *Handler) handleUpdateResource(request *restful.Request, response *restful.Response) {
+	region := request.PathParameter("region")
+	cluster := request.PathParameter("cluster")
+	resourceType := request.PathParameter("resources")
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	obj, err := readObject(request)
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	opts := metav1.UpdateOptions{
+		DryRun:       request.Request.URL.Query()[resource.ParameterDryRun],
+		FieldManager: request.QueryParameter(resource.ParameterFieldManager),
+	}
+	result, err := h.resourceProviderAlpha1.Update(request.Request.Context(), region, cluster, resourceType, namespace, name, obj, opts)
+	writeObject(request, response, resourceType, http.StatusOK, result, err)
+}
+
+// handlePatchResource patches resources by patch types of Content-Type, e.g. application/merge-patch+json
+func (h *Handler) handlePatchResource(request *restful.Request, response *restful.Response) {
+	region := request.PathParameter("region")
+	cluster := request.PathParameter("cluster")
+	resourceType := request.PathParameter("resources")
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	data, err := readBody(request)
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	opts := metav1.PatchOptions{
+		DryRun:       request.Request.URL.Query()[resource.ParameterDryRun],
+		FieldManager: request.QueryParameter(resource.ParameterFieldManager),
+	}
+	if force, err := strconv.ParseBool(request.QueryParameter(resource.ParameterForce)); err == nil {
+		opts.Force = &force
+	}
+	patchType := types.PatchType(contentType(request))
+	result, err := h.resourceProviderAlpha1.Patch(request.Request.Context(), region, cluster, resourceType, namespace, name, patchType, data, opts)
+	writeObject(request, response, resourceType, http.StatusOK, result, err)
+}
+
+func (h *Handler) handleDeleteResource(request *restful.Request, response *restful.Response) {
+	region := request.PathParameter("region")
+	cluster := request.PathParameter("cluster")
+	resourceType := request.PathParameter("resources")
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	opts := metav1.DeleteOptions{DryRun: request.Request.URL.Query()[resource.ParameterDryRun]}
+	if policy := request.QueryParameter(resource.ParameterPropagationPolicy); len(policy) != 0 {
+		propagation := metav1.DeletionPropagation(policy)
+		opts.PropagationPolicy = &propagation
+	}
+	if seconds, err := strconv.ParseInt(request.QueryParameter(resource.ParameterGracePeriodSeconds), 10, 64); err == nil {
+		opts.GracePeriodSeconds = &seconds
+	}
+	status, err := h.resourceProviderAlpha1.Delete(request.Request.Context(), region, cluster, resourceType, namespace, name, opts)
+	if err != nil {
+		writeObject(request, response, resourceType, http.StatusOK, nil, err)
+		return
+	}
+	response.WriteEntity(status)
+}
+
+// readObject reads the object of a request in JSON or YAML
+func readObject(request *restful.Request) (*unstructured.Unstructured, error) {
+	data, err := readBody(request)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(data, &obj.Object); err != nil {
+		return nil, errors.NewBadRequest(err.Error())
+	}
+	if len(obj.Object) == 0 {
+		return nil, errors.NewBadRequest("the object is empty")
+	}
+	return obj, nil
+}
+
+func readBody(request *restful.Request) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(request.Request.Body, maxRequestBodyBytes+1))
+	if err != nil {
+		return nil, errors.NewBadRequest(err.Error())
+	}
+	if len(data) > maxRequestBodyBytes {
+		return nil, errors.NewRequestEntityTooLargeError(fmt.Sprintf("limit is %d bytes", maxRequestBodyBytes))
+	}
+	return data, nil
+}
+
+// contentType returns the media type of Content-Type without parameters, e.g. charset
+func contentType(request *restful.Request) string {
+	value := request.HeaderParameter("Content-Type")
+	if i := strings.Index(value, ";"); i != -1 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
+
+// writeObject writes the object written to clusters with status, managedFields are stripped the same as
+// gets. Errors of clusters are written with their status codes, e.g. 409 of conflicts
+func writeObject(request *restful.Request, response *restful.Response, resourceType string, status int, obj runtime.Object, err error) {
+	if err != nil {
+		klog.Error(err, resourceType)
+		if err == resource.ErrResourceNotSupported {
+			api.HandleNotFound(response, request, err)
+			return
+		}
+		api.HandleError(response, request, err)
+		return
+	}
+	response.WriteHeaderAndEntity(status, kuberesalpha1.ProjectFunc(query.ParseQueryParameter(request))(obj))
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	clusterv1alpha1 "captain/apis/cluster/v1alpha1"
 	"captain/pkg/api"
 	"captain/pkg/bussiness/captain-resources/v1alpha1/resource"
 	"captain/pkg/informers"
@@ -23,6 +24,20 @@ type CaptainResource struct {
 	Name         string
 	Resources    []string
 	Namespaced   bool // defult false
+
+	// Object and List are samples of the resource and list response, used to document apis
+	Object interface{}
+	List   interface{}
+}
+
+// ClusterList documents the response of listing clusters, it's the same as response.ListResult
+// except that items are typed
+type ClusterList struct {
+	Items       []clusterv1alpha1.Cluster `json:"items"`
+	Total       int                       `json:"totalItems"`
+	PageSize    int                       `json:"pageSize"`
+	TotalPages  int                       `json:"totalPages"`
+	CurrentPage int                       `json:"currentPage"`
 }
 
 var resoureces = []CaptainResource{
@@ -30,6 +45,8 @@ var resoureces = []CaptainResource{
 		GroupVersion: schema.GroupVersion{Group: "cluster.captain.io", Version: "v1alpha1"},
 		Name:         "Cluster",
 		Resources:    []string{"clusters"},
+		Object:       clusterv1alpha1.Cluster{},
+		List:         ClusterList{},
 	},
 }
 
@@ -47,12 +64,13 @@ func AddToContainer(c *restful.Container, factory informers.CapInformerFactory,
 			Metadata(restfulspec.KeyOpenAPITags, []string{resource.Name}).
 			Doc("list "+strings.Join(resource.Resources, ", ")).
 			Param(webservice.PathParameter("resources", "known values include "+strings.Join(resource.Resources, ", "))).
-			Param(webservice.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
+			Param(webservice.QueryParameter(query.ParameterName, "name used to do filtering, names containing it are matched, use prefix(name) or exact(name) to match by prefix or exactly. Any field can be filtered by field=value, repeated keys are ORed, and field!=value, field=in(a,b), field=notin(a,b) are supported").Required(false)).
 			Param(webservice.QueryParameter(query.ParameterPage, "page, which is started with 1 not 0, default value is 1.").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
 			Param(webservice.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
 			Param(webservice.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
 			Param(webservice.QueryParameter(query.ParameterOrderBy, "sort parameters, e.g. orderBy=createTime")).
-			Returns(http.StatusOK, api.StatusOK, api.ListResult{Items: []interface{}{}}))
+			Param(webservice.QueryParameter(query.ParameterFieldSelector, "field selector the same as kubernetes, e.g. metadata.name=host. Supported fields: metadata.name").Required(false)).
+			Returns(http.StatusOK, api.StatusOK, resource.List))
 
 		webservice.Route(webservice.GET("/{resources}/{name}").
 			To(handler.handleGetResource).
@@ -65,14 +83,15 @@ func AddToContainer(c *restful.Container, factory informers.CapInformerFactory,
 			Param(webservice.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
 			Param(webservice.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
 			Param(webservice.QueryParameter(query.ParameterOrderBy, "sort parameters, e.g. orderBy=createTime")).
-			Returns(http.StatusOK, api.StatusOK, nil))
+			Returns(http.StatusOK, api.StatusOK, resource.Object))
 
 		webservice.Route(webservice.POST("/{resources}").
 			To(handler.handleCreateResource).
 			Metadata(restfulspec.KeyOpenAPITags, []string{resource.Name}).
 			Doc("create "+strings.Join(resource.Resources, ", ")).
 			Param(webservice.PathParameter("resources", "known values include "+strings.Join(resource.Resources, ", "))).
-			Returns(http.StatusOK, api.StatusOK, nil))
+			Reads(resource.Object).
+			Returns(http.StatusOK, api.StatusOK, resource.Object))
 
 		webservice.Route(webservice.DELETE("/{resources}/{name}").
 			To(handler.handleDeleteResource).
@@ -87,7 +106,9 @@ func AddToContainer(c *restful.Container, factory informers.CapInformerFactory,
 			Metadata(restfulspec.KeyOpenAPITags, []string{resource.Name}).
 			Doc("update "+strings.Join(resource.Resources, ", ")).
 			Param(webservice.PathParameter("resources", "known values include "+strings.Join(resource.Resources, ", "))).
-			Returns(http.StatusOK, api.StatusOK, nil))
+			Param(webservice.PathParameter("name", "name of resources")).
+			Reads(resource.Object).
+			Returns(http.StatusOK, api.StatusOK, resource.Object))
 
 		c.Add(webservice)
 	}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+
+	"k8s.io/apiserver/pkg/server/dynamiccertificates"
+	"k8s.io/klog"
+)
+
+// tlsConfig returns tls config of secure server, serving certificate and client ca are
+// reloaded from disk when they are rotated, until stopCh is closed.
+func (s *CaptainAPIServer) tlsConfig(stopCh <-chan struct{}) *tls.Config {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+
+	if s.ClientCA != nil {
+		// Populate PeerCertificates in requests, but don't reject connections without certificates,
+		// client certificates are verified by authenticator, while still allowing other auth types
+		tlsConfig.ClientAuth = tls.RequestClientCert
+	}
+
+	controller := dynamiccertificates.NewDynamicServingCertificateController(tlsConfig, s.ClientCA, s.ServingCert, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	if s.ClientCA != nil {
+		s.ClientCA.AddListener(controller)
+		runContentController(ctx, s.ClientCA)
+	}
+	if s.ServingCert != nil {
+		s.ServingCert.AddListener(controller)
+		runContentController(ctx, s.ServingCert)
+	}
+
+	if err := controller.RunOnce(); err != nil {
+		klog.Warningf("Initial population of dynamic certificates failed: %v", err)
+	}
+	go controller.Run(1, stopCh)
+
+	tlsConfig.GetConfigForClient = controller.GetConfigForClient
+	return tlsConfig
+}
+
+// runContentController starts watching certificate files if the provider is backed by files
+func runContentController(ctx context.Context, provider interface{ Name() string }) {
+	runner, ok := provider.(dynamiccertificates.ControllerRunner)
+	if !ok {
+		return
+	}
+	// files are already loaded when the provider is created, failure here only delays reloading
+	if err := runner.RunOnce(ctx); err != nil {
+		klog.Warningf("Initial population of %s failed: %v", provider.Name(), err)
+	}
+	go runner.Run(ctx, 1)
+}
@@ -0,0 +1,31 @@
+package tracing
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const tracesPath = "/v1/traces"
+
+// newOTLPExporter creates the OTLP/HTTP exporter of collector of o, spans are posted to path /v1/traces of Endpoint
+func newOTLPExporter(o *Options) (sdktrace.SpanExporter, error) {
+	u, err := url.Parse(o.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(u.Host),
+		otlptracehttp.WithURLPath(strings.TrimSuffix(u.Path, "/") + tracesPath),
+		otlptracehttp.WithHeaders(o.Headers),
+		otlptracehttp.WithTimeout(o.ExportTimeout),
+	}
+	if u.Scheme == "http" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	return otlptracehttp.New(context.Background(), opts...)
+}
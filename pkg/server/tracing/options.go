@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type Options struct {
+	// Enable traces requests and exports spans to Endpoint
+	Enable bool `json:"enable" yaml:"enable" mapstructure:"enable"`
+
+	// Endpoint is the OTLP/HTTP endpoint of collector spans are exported to, e.g. http://otel-collector:4318,
+	// spans are posted to path /v1/traces of it
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty" mapstructure:"endpoint"`
+
+	// Headers are sent with every export request, e.g. credentials of collector
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" mapstructure:"headers"`
+
+	// SamplingRatio is the ratio of traces started by captain are sampled, traces started by
+	// callers follow the sampling decision of callers
+	SamplingRatio float64 `json:"samplingRatio" yaml:"samplingRatio" mapstructure:"samplingRatio"`
+
+	// ExportTimeout is the timeout of every export request
+	ExportTimeout time.Duration `json:"exportTimeout,omitempty" yaml:"exportTimeout,omitempty" mapstructure:"exportTimeout"`
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Enable:        false,
+		Endpoint:      "http://localhost:4318",
+		Headers:       map[string]string{},
+		SamplingRatio: 1,
+		ExportTimeout: 10 * time.Second,
+	}
+}
+
+func (o *Options) Validate() []error {
+	var errs []error
+	if !o.Enable {
+		return errs
+	}
+
+	if u, err := url.Parse(o.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("invalid tracing endpoint %q, an http or https url is required", o.Endpoint))
+	}
+	if o.SamplingRatio < 0 || o.SamplingRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing sampling ratio %v must be in [0, 1]", o.SamplingRatio))
+	}
+	if o.ExportTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("tracing export timeout must be positive"))
+	}
+	return errs
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet, s *Options) {
+	fs.BoolVar(&o.Enable, "tracing-enable", s.Enable, ""+
+		"Trace requests with OpenTelemetry, trace context is propagated by W3C traceparent header.")
+	fs.StringVar(&o.Endpoint, "tracing-endpoint", s.Endpoint, ""+
+		"OTLP/HTTP endpoint of the collector spans are exported to.")
+	fs.Float64Var(&o.SamplingRatio, "tracing-sampling-ratio", s.SamplingRatio, ""+
+		"Ratio of traces started by captain are sampled, traces started by callers follow the decision of callers.")
+}
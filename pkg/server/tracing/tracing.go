@@ -0,0 +1,159 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"captain/pkg/version"
+)
+
+// InstrumentationName is the name of tracer creating spans of captain
+const InstrumentationName = "captain"
+
+var (
+	// RegionKey and ClusterKey are attributes of spans about member clusters
+	RegionKey  = attribute.Key("captain.region")
+	ClusterKey = attribute.Key("captain.cluster")
+)
+
+// propagator propagates trace context by W3C traceparent and tracestate headers
+var propagator = propagation.TraceContext{}
+
+// NewTracerProvider creates a tracer provider exporting spans to the collector of o, nil if tracing is disabled.
+// The provider is registered globally, so that spans deep in the call stack, e.g. dispatching to member
+// clusters, are created without passing the provider around
+func NewTracerProvider(o *Options, serviceName string) (*sdktrace.TracerProvider, error) {
+	if o == nil || !o.Enable {
+		return nil, nil
+	}
+	exporter, err := newOTLPExporter(o)
+	if err != nil {
+		return nil, err
+	}
+	return newTracerProvider(exporter, o.SamplingRatio, serviceName), nil
+}
+
+func newTracerProvider(exporter sdktrace.SpanExporter, samplingRatio float64, serviceName string) *sdktrace.TracerProvider {
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName),
+			semconv.ServiceVersionKey.String(version.Get().GitVersion),
+		)),
+	)
+	otel.SetTracerProvider(tp)
+	return tp
+}
+
+// Tracer returns the tracer of captain, spans are dropped if tracing is disabled
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// Extract returns ctx with the remote trace context carried by header
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Inject sets trace context of ctx to header, so that the receiver continues the trace
+func Inject(ctx context.Context, header http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// SetHTTPStatus records status code of http response to span of kind, 4xx responses are
+// failures of client spans but not of server spans
+func SetHTTPStatus(span trace.Span, code int, kind trace.SpanKind) {
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(code))
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(code, kind))
+}
+
+// SetError marks span failed by err
+func SetError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+type roundTripper struct {
+	delegate   http.RoundTripper
+	attributes []attribute.KeyValue
+}
+
+// NewRoundTripper creates a client span with attributes for every request sent by rt, and propagates
+// trace context by request headers. Spans end when response bodies are read or closed, so that
+// streaming responses, e.g. watches of member clusters, are traced until they are closed
+func NewRoundTripper(rt http.RoundTripper, attributes ...attribute.KeyValue) http.RoundTripper {
+	return &roundTripper{delegate: rt, attributes: attributes}
+}
+
+// WrapTransport wraps rt of clients of requests to a member cluster, requests are traced as children of
+// spans in request contexts
+func WrapTransport(region, cluster string, rt http.RoundTripper) http.RoundTripper {
+	return NewRoundTripper(rt, RegionKey.String(region), ClusterKey.String(cluster))
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := Tracer().Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(rt.attributes...),
+		trace.WithAttributes(semconv.HTTPClientAttributesFromHTTPRequest(req)...))
+
+	// requests must not be modified by round trippers
+	req = req.Clone(ctx)
+	Inject(ctx, req.Header)
+
+	resp, err := rt.delegate.RoundTrip(req)
+	if err != nil {
+		SetError(span, err)
+		span.End()
+		return nil, err
+	}
+	SetHTTPStatus(span, resp.StatusCode, trace.SpanKindClient)
+	if resp.Body == nil || resp.Body == http.NoBody {
+		span.End()
+		return resp, nil
+	}
+	resp.Body = &spanBody{ReadCloser: resp.Body, span: span}
+	return resp, nil
+}
+
+// spanBody ends span when the body is read to the end, fails or is closed
+type spanBody struct {
+	io.ReadCloser
+	span trace.Span
+	once sync.Once
+}
+
+func (b *spanBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	switch {
+	case err == io.EOF:
+		b.end()
+	case err != nil:
+		SetError(b.span, err)
+		b.end()
+	}
+	return n, err
+}
+
+func (b *spanBody) Close() error {
+	defer b.end()
+	return b.ReadCloser.Close()
+}
+
+func (b *spanBody) end() {
+	b.once.Do(func() {
+		b.span.End()
+	})
+}
@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTracePropagatedAndExported(t *testing.T) {
+	received := make(chan *coltracepb.ExportTraceServiceRequest, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != tracesPath {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read spans, %v", err)
+		}
+		req := &coltracepb.ExportTraceServiceRequest{}
+		if err := proto.Unmarshal(body, req); err != nil {
+			t.Errorf("failed to decode spans, %v", err)
+		}
+		received <- req
+	}))
+	defer collector.Close()
+
+	var traceparent string
+	member := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer member.Close()
+
+	o := NewOptions()
+	o.Enable = true
+	o.Endpoint = collector.URL
+	tp, err := NewTracerProvider(o, "captain-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the caller started the trace
+	const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	header := http.Header{}
+	header.Set("traceparent", parent)
+	ctx := Extract(context.Background(), header)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, member.URL, nil)
+	client := &http.Client{Transport: NewRoundTripper(http.DefaultTransport, ClusterKey.String("member"))}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if len(traceparent) == 0 || traceparent[3:35] != parent[3:35] || traceparent == parent {
+		t.Errorf("expected trace continued by member cluster, got traceparent %q", traceparent)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tp.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case req := <-received:
+		spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+		if len(spans) != 1 {
+			t.Fatalf("expected 1 span exported, got %d", len(spans))
+		}
+		if hex.EncodeToString(spans[0].TraceId) != parent[3:35] || hex.EncodeToString(spans[0].ParentSpanId) != parent[36:52] {
+			t.Errorf("unexpected span %+v", spans[0])
+		}
+	default:
+		t.Errorf("expected spans exported to collector")
+	}
+}
+
+func TestRoundTripperTracesStreams(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, parent := tp.Tracer(InstrumentationName).Start(context.Background(), "Watch")
+	defer parent.End()
+
+	member := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"type":"ADDED"}`))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer member.Close()
+
+	global := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(global)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, member.URL+"?watch=true", nil)
+	client := &http.Client{Transport: WrapTransport("middle-earth", "gondor", http.DefaultTransport)}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resp.Body.Read(make([]byte, 64)); err != nil {
+		t.Fatal(err)
+	}
+	if spans := recorder.Ended(); len(spans) != 0 {
+		t.Errorf("expected the span of the watch not ended before the stream is closed, got %d ended", len(spans))
+	}
+
+	resp.Body.Close()
+	if spans := recorder.Ended(); len(spans) != 1 || spans[0].Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("expected the span of the watch ended as a child of the caller, got %v", spans)
+	}
+}
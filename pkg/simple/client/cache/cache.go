@@ -23,4 +23,7 @@ type Interface interface {
 
 	// Expires updates object's expiration time, return err if key doesn't exist
 	Expire(key string, duration time.Duration) error
+
+	// Ping checks the cache service is reachable
+	Ping() error
 }
@@ -3,6 +3,7 @@ package cache
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis"
@@ -10,7 +11,8 @@ import (
 )
 
 type Client struct {
-	client *redis.Client
+	client    *redis.Client
+	closeOnce sync.Once
 }
 
 func NewRedisClient(option *Options, stopCh <-chan struct{}) (Interface, error) {
@@ -37,7 +39,7 @@ func NewRedisClient(option *Options, stopCh <-chan struct{}) (Interface, error)
 	if stopCh != nil {
 		go func() {
 			<-stopCh
-			if err := r.client.Close(); err != nil {
+			if err := r.Close(); err != nil {
 				klog.Error(err)
 			}
 		}()
@@ -46,6 +48,15 @@ func NewRedisClient(option *Options, stopCh <-chan struct{}) (Interface, error)
 	return &r, nil
 }
 
+// Close closes connections to redis, it's safe to be called more than once
+func (r *Client) Close() error {
+	var err error
+	r.closeOnce.Do(func() {
+		err = r.client.Close()
+	})
+	return err
+}
+
 func (r *Client) Get(key string) (string, error) {
 	return r.client.Get(key).Result()
 }
@@ -74,3 +85,7 @@ func (r *Client) Exists(keys ...string) (bool, error) {
 func (r *Client) Expire(key string, duration time.Duration) error {
 	return r.client.Expire(key, duration).Err()
 }
+
+func (r *Client) Ping() error {
+	return r.client.Ping().Err()
+}
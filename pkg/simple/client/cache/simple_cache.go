@@ -106,3 +106,7 @@ func (s *simpleCache) Expire(key string, duration time.Duration) error {
 	s.store[key] = sobject
 	return nil
 }
+
+func (s *simpleCache) Ping() error {
+	return nil
+}
@@ -24,6 +24,10 @@ type Options struct {
 	HostClusterName string `json:"hostClusterName,omitempty" yaml:"hostClusterName"`
 
 	Karmada KarmadaConfig `json:"karmada,omitempty" yaml:"karmada"`
+
+	// MemberClusterHealthCheck reports connectivity of every member cluster in verbose output
+	// of /healthz and /readyz, failures of member clusters never fail the health endpoints
+	MemberClusterHealthCheck bool `json:"memberClusterHealthCheck,omitempty" yaml:"memberClusterHealthCheck"`
 }
 
 type KarmadaConfig struct {
@@ -68,4 +72,7 @@ func (o *Options) AddFlags(fs *pflag.FlagSet, s *Options) {
 
 	fs.StringVar(&o.HostClusterName, "host-cluster-name", s.HostClusterName, "the name of the control plane"+
 		" cluster, default set to host")
+
+	fs.BoolVar(&o.MemberClusterHealthCheck, "member-cluster-health-check", s.MemberClusterHealthCheck, ""+
+		"Report connectivity of every member cluster in verbose output of /healthz and /readyz.")
 }
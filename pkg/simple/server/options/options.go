@@ -4,6 +4,7 @@ package options
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/pflag"
 
@@ -17,14 +18,22 @@ type ServerRunOptions struct {
 	// insecure port number
 	InsecurePort int
 
-	// secure port number
+	// secure port number, 0 means https is disabled
 	SecurePort int
 
-	// tls cert file
+	// tls cert file, reloaded when it changes on disk
 	TlsCertFile string
 
-	// tls private key file
+	// tls private key file, reloaded when it changes on disk
 	TlsPrivateKey string
+
+	// ShutdownDelayDuration is the time server keeps serving after readiness fails on shutdown,
+	// giving load balancers time to stop sending new requests
+	ShutdownDelayDuration time.Duration
+
+	// ShutdownGracePeriod is the time in-flight requests are allowed to finish on shutdown,
+	// watches and upgraded connections still open after it are closed
+	ShutdownGracePeriod time.Duration
 }
 
 func NewServerRunOptions() *ServerRunOptions {
@@ -35,6 +44,9 @@ func NewServerRunOptions() *ServerRunOptions {
 		SecurePort:    0,
 		TlsCertFile:   "",
 		TlsPrivateKey: "",
+
+		ShutdownDelayDuration: 5 * time.Second,
+		ShutdownGracePeriod:   60 * time.Second,
 	}
 
 	return &s
@@ -47,6 +59,14 @@ func (s *ServerRunOptions) Validate() []error {
 		errs = append(errs, fmt.Errorf("insecure and secure port can not be disabled at the same time"))
 	}
 
+	if s.InsecurePort != 0 && !net.IsValidPort(s.InsecurePort) {
+		errs = append(errs, fmt.Errorf("insecure port %d is invalid", s.InsecurePort))
+	}
+
+	if s.SecurePort != 0 && !net.IsValidPort(s.SecurePort) {
+		errs = append(errs, fmt.Errorf("secure port %d is invalid", s.SecurePort))
+	}
+
 	if net.IsValidPort(s.SecurePort) {
 		if s.TlsCertFile == "" {
 			errs = append(errs, fmt.Errorf("tls cert file is empty while secure serving"))
@@ -65,14 +85,27 @@ func (s *ServerRunOptions) Validate() []error {
 		}
 	}
 
+	if s.ShutdownDelayDuration < 0 {
+		errs = append(errs, fmt.Errorf("shutdown delay duration can not be negative"))
+	}
+
+	if s.ShutdownGracePeriod < 0 {
+		errs = append(errs, fmt.Errorf("shutdown grace period can not be negative"))
+	}
+
 	return errs
 }
 
 func (s *ServerRunOptions) AddFlags(fs *pflag.FlagSet, c *ServerRunOptions) {
 
 	fs.StringVar(&s.BindAddress, "bind-address", c.BindAddress, "server bind address")
-	fs.IntVar(&s.InsecurePort, "insecure-port", c.InsecurePort, "insecure port number")
-	fs.IntVar(&s.SecurePort, "secure-port", s.SecurePort, "secure port number")
-	fs.StringVar(&s.TlsCertFile, "tls-cert-file", c.TlsCertFile, "tls cert file")
-	fs.StringVar(&s.TlsPrivateKey, "tls-private-key", c.TlsPrivateKey, "tls private key")
+	fs.IntVar(&s.InsecurePort, "insecure-port", c.InsecurePort, "insecure port number, 0 to disable plain http")
+	fs.IntVar(&s.SecurePort, "secure-port", c.SecurePort, "secure port number, 0 to disable https. "+
+		"Both ports can be served at the same time, e.g. when migrating clients to https")
+	fs.StringVar(&s.TlsCertFile, "tls-cert-file", c.TlsCertFile, "tls cert file, reloaded when it changes on disk")
+	fs.StringVar(&s.TlsPrivateKey, "tls-private-key", c.TlsPrivateKey, "tls private key, reloaded when it changes on disk")
+	fs.DurationVar(&s.ShutdownDelayDuration, "shutdown-delay-duration", c.ShutdownDelayDuration, "time to keep serving after "+
+		"readyz fails on shutdown, so that load balancers stop sending new requests before the server stops listening")
+	fs.DurationVar(&s.ShutdownGracePeriod, "shutdown-grace-period", c.ShutdownGracePeriod, "time in-flight requests are "+
+		"allowed to finish on shutdown, watches and upgraded connections still open after it are closed")
 }
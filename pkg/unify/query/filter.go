@@ -0,0 +1,128 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MatchMode is how names are matched by the value of a filter
+type MatchMode string
+
+const (
+	// MatchContains matches names containing the value, e.g. name=nginx
+	MatchContains MatchMode = ""
+	// MatchPrefix matches names starting with the value, e.g. name=prefix(nginx-)
+	MatchPrefix MatchMode = "prefix"
+	// MatchExact matches names equal to the value, e.g. name=exact(nginx)
+	MatchExact MatchMode = "exact"
+)
+
+const (
+	// OperatorIn selects objects matching any of the values, e.g. status=in(Running,Pending)
+	OperatorIn = "in"
+	// OperatorNotIn selects objects matching none of the values, e.g. status=notin(Succeeded,Failed)
+	OperatorNotIn = "notin"
+)
+
+// Requirement selects objects by filters on a field. Objects matching any filter of In and none
+// of NotIn are selected, e.g. status=Running&status=Pending&name!=test
+type Requirement struct {
+	Field Field
+	In    []Filter
+	NotIn []Filter
+}
+
+// Matches returns true if the object matched by match meets the requirement
+func (r *Requirement) Matches(match func(Filter) bool) bool {
+	if len(r.In) != 0 {
+		matched := false
+		for _, filter := range r.In {
+			if match(filter) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for _, filter := range r.NotIn {
+		if match(filter) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Requirement) String() string {
+	values := func(filters []Filter) []string {
+		var s []string
+		for _, f := range filters {
+			if f.Match != MatchContains {
+				s = append(s, fmt.Sprintf("%s(%s)", f.Match, f.Value))
+			} else {
+				s = append(s, string(f.Value))
+			}
+		}
+		return s
+	}
+	return fmt.Sprintf("%s in (%s) notin (%s)", r.Field, strings.Join(values(r.In), ","), strings.Join(values(r.NotIn), ","))
+}
+
+// AddFilter adds the filter of query parameter key=value to q. Key may end with "!" to negate
+// the filter, e.g. status!=Succeeded, and value may be one of
+//
+//	Running                     the field matches the value
+//	in(Running,Pending)         the field matches any of the values
+//	notin(Succeeded,Failed)     the field matches none of the values
+//	prefix(nginx-)              the name starts with the value
+//	exact(nginx)                the name equals the value
+//
+// Repeated keys are ORed, and different keys are ANDed
+func (q *QueryInfo) AddFilter(key, value string) {
+	negated := strings.HasSuffix(key, "!")
+	field := Field(strings.TrimSuffix(key, "!"))
+
+	r, ok := q.Filters[field]
+	if !ok {
+		r = &Requirement{Field: field}
+		q.Filters[field] = r
+	}
+
+	var filters []Filter
+	if operator, args, ok := parseFunction(value); ok {
+		switch operator {
+		case OperatorIn, OperatorNotIn:
+			for _, arg := range strings.Split(args, ",") {
+				filters = append(filters, Filter{Field: field, Value: Value(strings.TrimSpace(arg))})
+			}
+			if operator == OperatorNotIn {
+				negated = !negated
+			}
+		default:
+			filters = append(filters, Filter{Field: field, Value: Value(args), Match: MatchMode(operator)})
+		}
+	} else {
+		filters = append(filters, Filter{Field: field, Value: Value(value)})
+	}
+
+	if negated {
+		r.NotIn = append(r.NotIn, filters...)
+	} else {
+		r.In = append(r.In, filters...)
+	}
+}
+
+// parseFunction parses values like in(a,b), values in other forms are plain values
+func parseFunction(value string) (operator, args string, ok bool) {
+	i := strings.Index(value, "(")
+	if i <= 0 || !strings.HasSuffix(value, ")") {
+		return "", "", false
+	}
+	operator = value[:i]
+	switch operator {
+	case OperatorIn, OperatorNotIn, string(MatchPrefix), string(MatchExact):
+		return operator, value[i+1 : len(value)-1], true
+	}
+	return "", "", false
+}
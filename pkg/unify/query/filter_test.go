@@ -0,0 +1,43 @@
+package query
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+)
+
+func TestParseFilters(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/pods?status=Running&status=Pending&status!=Unknown"+
+		"&ownerKind=notin(Job,CronJob)&name=prefix(nginx-)&label=app%3Dnginx&page=2", nil)
+	q := ParseQueryParameter(restful.NewRequest(req))
+
+	if len(q.Filters) != 4 {
+		t.Fatalf("expected 4 requirements, got %v", q.filterStrings())
+	}
+
+	status := q.Filters[FieldStatus]
+	if len(status.In) != 2 || len(status.NotIn) != 1 || status.NotIn[0].Value != "Unknown" {
+		t.Errorf("unexpected status requirement %s", status)
+	}
+	if owner := q.Filters[FieldOwnerKind]; len(owner.In) != 0 || len(owner.NotIn) != 2 {
+		t.Errorf("unexpected ownerKind requirement %s", owner)
+	}
+	if name := q.Filters[FieldName]; len(name.In) != 1 || name.In[0].Match != MatchPrefix || name.In[0].Value != "nginx-" {
+		t.Errorf("unexpected name requirement %s", name)
+	}
+	if label := q.Filters[FieldLabel]; label.In[0].Value != "app=nginx" {
+		t.Errorf("unexpected label requirement %s", label)
+	}
+
+	phase := "Pending"
+	match := func(f Filter) bool { return string(f.Value) == phase }
+	if !status.Matches(match) {
+		t.Errorf("expected %s matched by %s", phase, status)
+	}
+	for _, phase = range []string{"Unknown", "Succeeded"} {
+		if status.Matches(match) {
+			t.Errorf("expected %s not matched by %s", phase, status)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 
 	"captain/pkg/utils/base"
 
@@ -37,35 +38,91 @@ const (
 )
 
 const (
-	ParameterName          = "name"
-	ParameterLabelSelector = "labelSelector"
-	ParameterFieldSelector = "fieldSelector"
-	ParameterPage          = "page"
-	// ParameterLimit         = "limit"
-	ParameterPageSize  = "pageSize"
-	ParameterOrderBy   = "sortBy"
-	ParameterAscending = "ascending"
+	ParameterName                = "name"
+	ParameterLabelSelector       = "labelSelector"
+	ParameterFieldSelector       = "fieldSelector"
+	ParameterPage                = "page"
+	ParameterLimit               = "limit"
+	ParameterContinue            = "continue"
+	ParameterFilter              = "filter"
+	ParameterFields              = "fields"
+	ParameterShowManaged         = "showManagedFields"
+	ParameterWatch               = "watch"
+	ParameterResourceVersion     = "resourceVersion"
+	ParameterAllowWatchBookmarks = "allowWatchBookmarks"
+	ParameterFormat              = "format"
+	ParameterPageSize            = "pageSize"
+	ParameterOrderBy             = "sortBy"
+	ParameterAscending           = "ascending"
 )
 
 // Query represents api search terms
 type QueryInfo struct {
 	Pagination *Pagination
 
-	// sort result in which field, default to FieldCreationTimeStamp
+	// sort result in which field, default to FieldCreationTimeStamp. It may be a JSONPath,
+	// e.g. .status.readyReplicas
 	SortBy Field
 
 	// sort result in ascending or descending order, default to descending
 	Ascending bool
 
-	//
-	Filters map[Field]Value
+	// Filters select objects by fields, objects meeting all requirements are selected
+	Filters map[Field]*Requirement
 
 	LabelSelector string
+
+	// JSONPathFilters select objects by values of JSONPath, e.g. .status.readyReplicas>=2, objects
+	// meeting all of them are selected
+	JSONPathFilters []string
+
+	// FieldSelector selects objects by fields the same way as kubernetes, e.g. spec.nodeName=n1,status.phase!=Running
+	FieldSelector string
+
+	// Limit enables cursor pagination, at most Limit items are returned, with a continue token if
+	// there are more. Page and PageSize are ignored then. Objects of every cluster are paged from
+	// informer caches, limits and continue tokens are never sent to kube-apiservers
+	Limit int64
+
+	// Continue is the continue token returned by the previous page, the next page starts there
+	Continue string
+
+	// Fields are paths of fields returned, e.g. metadata.name,status.phase, whole objects are returned if empty
+	Fields []string
+
+	// ShowManagedFields returns managedFields and last-applied-configuration annotation of objects,
+	// they are stripped by default
+	ShowManagedFields bool
+
+	// Watch streams changes of objects instead of listing them
+	Watch bool
+
+	// ResourceVersion is the resourceVersion watches start after, current objects are sent first if empty
+	ResourceVersion string
+
+	// AllowWatchBookmarks requests BOOKMARK events of watches
+	AllowWatchBookmarks bool
+
+	// Format is the format lists are exported in, e.g. csv, yaml or ndjson, JSON is returned if empty
+	Format string
+}
+
+// IsCursorPagination returns true if items are paged by continue token instead of page number
+func (q *QueryInfo) IsCursorPagination() bool {
+	return q.Limit > 0 || len(q.Continue) != 0
 }
 
 func (q *QueryInfo) String() string {
 	return fmt.Sprintf("Incoming Query info %T \n Pagination: { Page: %d, PageSize: %d} \n SortBy: %s, Ascending: %v, \n Filters: %+v \n ",
-		q, q.Pagination.Page, q.Pagination.PageSize, q.SortBy, q.Ascending, q.Filters)
+		q, q.Pagination.Page, q.Pagination.PageSize, q.SortBy, q.Ascending, q.filterStrings())
+}
+
+func (q *QueryInfo) filterStrings() []string {
+	var filters []string
+	for _, r := range q.Filters {
+		filters = append(filters, r.String())
+	}
+	return filters
 }
 
 type Pagination struct {
@@ -79,6 +136,9 @@ type Pagination struct {
 type Filter struct {
 	Field Field
 	Value Value
+
+	// Match is how names are matched by Value, names containing Value are matched by default
+	Match MatchMode
 }
 
 func New() *QueryInfo {
@@ -86,7 +146,7 @@ func New() *QueryInfo {
 		Pagination: DefaultPagination,
 		SortBy:     "",
 		Ascending:  false,
-		Filters:    map[Field]Value{},
+		Filters:    map[Field]*Requirement{},
 	}
 }
 
@@ -100,6 +160,9 @@ func (q *QueryInfo) GetSelector() labels.Selector {
 
 var DefaultPagination = newPagination(1, 10)
 
+// NoPagination returns all items in a single page
+var NoPagination = &Pagination{Page: 1, PageSize: math.MaxInt32}
+
 func newPagination(page, pageSize int) *Pagination {
 	// handling invalid number
 	if page <= 0 {
@@ -162,12 +225,39 @@ func ParseQueryParameter(request *restful.Request) *QueryInfo {
 	}
 
 	query.LabelSelector = request.QueryParameter(ParameterLabelSelector)
+	query.FieldSelector = request.QueryParameter(ParameterFieldSelector)
+	query.JSONPathFilters = request.Request.URL.Query()[ParameterFilter]
+
+	// invalid limit is equivalent to undefined, page number pagination is used
+	if limit, err := strconv.ParseInt(request.QueryParameter(ParameterLimit), 10, 64); err == nil && limit > 0 {
+		query.Limit = limit
+	}
+	query.Continue = request.QueryParameter(ParameterContinue)
+	if len(query.Continue) != 0 && query.Limit == 0 {
+		query.Limit = int64(query.Pagination.PageSize)
+	}
+
+	// fields=metadata.name,status.phase and repeated fields are equivalent
+	for _, value := range request.Request.URL.Query()[ParameterFields] {
+		for _, field := range strings.Split(value, ",") {
+			if field = strings.TrimSpace(field); len(field) != 0 {
+				query.Fields = append(query.Fields, field)
+			}
+		}
+	}
+	query.ShowManagedFields, _ = strconv.ParseBool(request.QueryParameter(ParameterShowManaged))
+
+	query.Watch, _ = strconv.ParseBool(request.QueryParameter(ParameterWatch))
+	query.ResourceVersion = request.QueryParameter(ParameterResourceVersion)
+	query.AllowWatchBookmarks, _ = strconv.ParseBool(request.QueryParameter(ParameterAllowWatchBookmarks))
+	query.Format = request.QueryParameter(ParameterFormat)
 
 	for key, values := range request.Request.URL.Query() {
-		if !base.HasString([]string{ParameterPage, ParameterPageSize, ParameterOrderBy, ParameterAscending, ParameterLabelSelector}, key) {
+		if !base.HasString([]string{ParameterPage, ParameterPageSize, ParameterOrderBy, ParameterAscending, ParameterLabelSelector, ParameterFieldSelector, ParameterLimit, ParameterContinue, ParameterFilter, ParameterFields, ParameterShowManaged,
+			ParameterWatch, ParameterResourceVersion, ParameterAllowWatchBookmarks, ParameterFormat}, key) {
 			// support multiple query condition
 			for _, value := range values {
-				query.Filters[Field(key)] = Value(value)
+				query.AddFilter(key, value)
 			}
 		}
 	}
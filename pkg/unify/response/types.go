@@ -1,10 +1,33 @@
 package response
 
-//ListResult ... data format in listing requst
+// ListResult is a page of objects listed from informer caches, of the host cluster or member clusters.
+// Items are paged by limit and continue tokens if limit is set, or by page and pageSize otherwise
 type ListResult struct {
-	Items       []interface{} `json:"items"`
-	Total       int           `json:"totalItems"`
-	PageSize    int           `json:"pageSize"`
-	TotalPages  int           `json:"totalPages"`
-	CurrentPage int           `json:"currentPage"`
+	Items []interface{} `json:"items"`
+
+	// Total is the number of items of every page, PageSize is limit if items are paged by limit
+	Total    int `json:"totalItems"`
+	PageSize int `json:"pageSize"`
+
+	// TotalPages and CurrentPage are set only if items are paged by page and pageSize
+	TotalPages  int `json:"totalPages"`
+	CurrentPage int `json:"currentPage"`
+
+	// Continue is set if items are paged by limit and there are more items, pass it as the
+	// continue parameter to get the next page
+	Continue string `json:"continue,omitempty"`
+
+	// RemainingItemCount is the number of items after this page, it is absent on the last page
+	RemainingItemCount *int64 `json:"remainingItemCount,omitempty"`
+
+	// ClusterErrors are set by lists aggregated from member clusters, objects of these clusters
+	// are absent from items
+	ClusterErrors []ClusterError `json:"clusterErrors,omitempty"`
+}
+
+// ClusterError is the error of listing objects from a member cluster
+type ClusterError struct {
+	Region  string `json:"region,omitempty"`
+	Cluster string `json:"cluster"`
+	Error   string `json:"error"`
 }
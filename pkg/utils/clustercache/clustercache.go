@@ -0,0 +1,345 @@
+package clustercache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	snapshotclient "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned"
+	snapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"captain/pkg/utils/clusterclient"
+)
+
+const (
+	// DefaultIdleTimeout is the time caches of member clusters are kept without being accessed
+	DefaultIdleTimeout = 10 * time.Minute
+
+	// syncTimeout is the timeout of waiting for informers started on demand to be synced
+	syncTimeout = 30 * time.Second
+)
+
+// volumeSnapshotClassGVR is the resource of volume snapshot classes, which is served by clusters with
+// the snapshot CRDs only
+var volumeSnapshotClassGVR = snapshotv1.SchemeGroupVersion.WithResource("volumesnapshotclasses")
+
+// evictionInterval is the interval of evicting caches idle or of clusters removed or changed
+var evictionInterval = time.Minute
+
+// Cache caches objects of a cluster by informers, which are started on demand the first time they
+// are accessed, and run until the cache is stopped
+type Cache struct {
+	kubeconfig string
+
+	informers         informers.SharedInformerFactory
+	dynamicInformers  dynamicinformer.DynamicSharedInformerFactory
+	snapshotInformers snapshotinformers.SharedInformerFactory
+
+	discovery discovery.DiscoveryInterface
+	// snapshotClassesServed is whether the cluster serves volumesnapshotclasses, nil if not checked yet
+	snapshotClassesServed *bool
+	snapshotLock          sync.Mutex
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+
+	// lastAccess is the unix nano time the cache is accessed lastly
+	lastAccess int64
+	// holds is the number of holders keeping the cache from being evicted for idleness
+	holds int32
+}
+
+// NewCache returns the cache of the cluster of config
+func NewCache(config *rest.Config) (*Cache, error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	snapshotClient, err := snapshotclient.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{
+		informers:         informers.NewSharedInformerFactory(client, 0),
+		dynamicInformers:  dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0),
+		snapshotInformers: snapshotinformers.NewSharedInformerFactory(snapshotClient, 0),
+		discovery:         client.Discovery(),
+		stopCh:            make(chan struct{}),
+		lastAccess:        time.Now().UnixNano(),
+	}, nil
+}
+
+// KubernetesSharedInformerFactory returns the factory of informers of kubernetes resources, informers
+// of resources are started and waited until synced. Listers of the other resources are not synced
+func (c *Cache) KubernetesSharedInformerFactory(ctx context.Context, resources ...schema.GroupVersionResource) (informers.SharedInformerFactory, error) {
+	c.touch()
+	var synced []cache.InformerSynced
+	for _, gvr := range resources {
+		informer, err := c.informers.ForResource(gvr)
+		if err != nil {
+			return nil, err
+		}
+		synced = append(synced, informer.Informer().HasSynced)
+	}
+	c.informers.Start(c.stopCh)
+	if err := c.waitForSync(ctx, resources, synced...); err != nil {
+		return nil, err
+	}
+	return c.informers, nil
+}
+
+// DynamicInformer returns the dynamic informer of gvr, it is started and waited until synced
+func (c *Cache) DynamicInformer(ctx context.Context, gvr schema.GroupVersionResource) (informers.GenericInformer, error) {
+	c.touch()
+	informer := c.dynamicInformers.ForResource(gvr)
+	c.dynamicInformers.Start(c.stopCh)
+	if err := c.waitForSync(ctx, []schema.GroupVersionResource{gvr}, informer.Informer().HasSynced); err != nil {
+		return nil, err
+	}
+	return informer, nil
+}
+
+// SnapshotSharedInformerFactory returns the factory of informers of volume snapshots, the informer of
+// volumesnapshotclasses is started and waited until synced. nil is returned if the cluster does not
+// serve volumesnapshotclasses, which is checked once for the cache
+func (c *Cache) SnapshotSharedInformerFactory(ctx context.Context) (snapshotinformers.SharedInformerFactory, error) {
+	c.touch()
+	served, err := c.servesSnapshotClasses()
+	if err != nil || !served {
+		return nil, err
+	}
+	informer := c.snapshotInformers.Snapshot().V1().VolumeSnapshotClasses().Informer()
+	c.snapshotInformers.Start(c.stopCh)
+	if err := c.waitForSync(ctx, []schema.GroupVersionResource{volumeSnapshotClassGVR}, informer.HasSynced); err != nil {
+		return nil, err
+	}
+	return c.snapshotInformers, nil
+}
+
+// servesSnapshotClasses returns whether the cluster serves volumesnapshotclasses, failures of discovery
+// are not kept, so that they are checked again
+func (c *Cache) servesSnapshotClasses() (bool, error) {
+	c.snapshotLock.Lock()
+	defer c.snapshotLock.Unlock()
+	if c.snapshotClassesServed != nil {
+		return *c.snapshotClassesServed, nil
+	}
+
+	served := false
+	resources, err := c.discovery.ServerResourcesForGroupVersion(volumeSnapshotClassGVR.GroupVersion().String())
+	switch {
+	case errors.IsNotFound(err):
+	case err != nil:
+		return false, err
+	default:
+		for _, resource := range resources.APIResources {
+			if resource.Name == volumeSnapshotClassGVR.Resource {
+				served = true
+				break
+			}
+		}
+	}
+	c.snapshotClassesServed = &served
+	return served, nil
+}
+
+// waitForSync waits until informers are synced, a Timeout error is returned if they are not synced in
+// syncTimeout, e.g. objects are not permitted to be listed, or ctx is done before
+func (c *Cache) waitForSync(ctx context.Context, resources []schema.GroupVersionResource, synced ...cache.InformerSynced) error {
+	allSynced := true
+	for _, s := range synced {
+		if !s() {
+			allSynced = false
+			break
+		}
+	}
+	if allSynced {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, syncTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-c.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return errors.NewTimeoutError(fmt.Sprintf("objects of %v are not cached yet", resources), 1)
+	}
+	return nil
+}
+
+func (c *Cache) touch() {
+	atomic.StoreInt64(&c.lastAccess, time.Now().UnixNano())
+}
+
+// Hold keeps the cache from being evicted for idleness until it is released, e.g. by informers
+// handled continuously instead of accessed by requests. It is still evicted if the cluster is changed
+func (c *Cache) Hold() {
+	atomic.AddInt32(&c.holds, 1)
+}
+
+// Release releases a hold of the cache, it is evicted once it is idle since then
+func (c *Cache) Release() {
+	c.touch()
+	atomic.AddInt32(&c.holds, -1)
+}
+
+// Done returns a channel closed once the cache is stopped
+func (c *Cache) Done() <-chan struct{} {
+	return c.stopCh
+}
+
+func (c *Cache) idle(timeout time.Duration) bool {
+	return atomic.LoadInt32(&c.holds) == 0 && time.Since(time.Unix(0, atomic.LoadInt64(&c.lastAccess))) > timeout
+}
+
+// Stop stops informers of the cache, objects cached are kept for requests still reading them
+func (c *Cache) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
+	})
+}
+
+// Manager manages caches of member clusters. Caches are created the first time clusters are accessed,
+// evicted after they are idle for idleTimeout, and rebuilt once kubeconfigs of clusters are changed
+type Manager struct {
+	clients     clusterclient.ClusterClients
+	idleTimeout time.Duration
+
+	sync.Mutex
+	caches map[string]*Cache
+
+	// newCache creates caches of clusters, it is replaced by tests
+	newCache func(region, name string) (*Cache, error)
+}
+
+func NewManager(clients clusterclient.ClusterClients, idleTimeout time.Duration) *Manager {
+	m := &Manager{
+		clients:     clients,
+		idleTimeout: idleTimeout,
+		caches:      make(map[string]*Cache),
+	}
+	m.newCache = func(region, name string) (*Cache, error) {
+		config, err := clients.GetRESTConfig(region, name)
+		if err != nil {
+			return nil, err
+		}
+		return NewCache(config)
+	}
+	return m
+}
+
+// Get returns the cache of a member cluster, it is created if there is none, or the kubeconfig of the
+// cluster is changed
+func (m *Manager) Get(region, name string) (*Cache, error) {
+	cluster, err := m.clients.Get(region, name)
+	if err != nil {
+		return nil, err
+	}
+	kubeconfig := string(cluster.Spec.Connection.KubeConfig)
+
+	m.Lock()
+	defer m.Unlock()
+	if c, ok := m.caches[cluster.Name]; ok {
+		if c.kubeconfig == kubeconfig {
+			return c, nil
+		}
+		klog.V(4).Infof("rebuild cache of cluster %s, kubeconfig is changed", cluster.Name)
+		c.Stop()
+		delete(m.caches, cluster.Name)
+	}
+
+	c, err := m.newCache(region, name)
+	if err != nil {
+		return nil, err
+	}
+	c.kubeconfig = kubeconfig
+	m.caches[cluster.Name] = c
+	return c, nil
+}
+
+// KubernetesSharedInformerFactory returns the informer factory of a member cluster, informers of
+// resources are started and synced
+func (m *Manager) KubernetesSharedInformerFactory(ctx context.Context, region, name string, resources ...schema.GroupVersionResource) (informers.SharedInformerFactory, error) {
+	c, err := m.Get(region, name)
+	if err != nil {
+		return nil, err
+	}
+	return c.KubernetesSharedInformerFactory(ctx, resources...)
+}
+
+// SnapshotSharedInformerFactory returns the snapshot informer factory of a member cluster, the informer
+// of volumesnapshotclasses is started and synced. nil is returned if the cluster does not serve them
+func (m *Manager) SnapshotSharedInformerFactory(ctx context.Context, region, name string) (snapshotinformers.SharedInformerFactory, error) {
+	c, err := m.Get(region, name)
+	if err != nil {
+		return nil, err
+	}
+	return c.SnapshotSharedInformerFactory(ctx)
+}
+
+// DynamicInformer returns the synced dynamic informer of gvr of a member cluster
+func (m *Manager) DynamicInformer(ctx context.Context, region, name string, gvr schema.GroupVersionResource) (informers.GenericInformer, error) {
+	c, err := m.Get(region, name)
+	if err != nil {
+		return nil, err
+	}
+	return c.DynamicInformer(ctx, gvr)
+}
+
+// Run evicts caches periodically until stopCh is closed, caches are stopped then
+func (m *Manager) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(evictionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			m.Lock()
+			for name, c := range m.caches {
+				c.Stop()
+				delete(m.caches, name)
+			}
+			m.Unlock()
+			return
+		case <-ticker.C:
+			m.evict()
+		}
+	}
+}
+
+// evict stops and removes caches idle for idleTimeout, and caches of clusters removed or changed,
+// so that informers do not keep watching clusters with stale kubeconfigs
+func (m *Manager) evict() {
+	m.Lock()
+	defer m.Unlock()
+	for name, c := range m.caches {
+		kubeconfig, err := m.clients.GetClusterKubeconfig(name)
+		if err == nil && kubeconfig == c.kubeconfig && !c.idle(m.idleTimeout) {
+			continue
+		}
+		klog.V(4).Infof("evict cache of cluster %s", name)
+		c.Stop()
+		delete(m.caches, name)
+	}
+}
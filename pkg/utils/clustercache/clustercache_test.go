@@ -0,0 +1,165 @@
+package clustercache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	snapshotfake "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned/fake"
+	snapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	clusterv1alpha1 "captain/apis/cluster/v1alpha1"
+	"captain/pkg/utils/clusterclient"
+)
+
+// fakeClients serves clusters of kubeconfigs by names, the other methods are not implemented
+type fakeClients struct {
+	clusterclient.ClusterClients
+	kubeconfigs map[string]string
+}
+
+func (c *fakeClients) Get(region, name string) (*clusterv1alpha1.Cluster, error) {
+	kubeconfig, err := c.GetClusterKubeconfig(name)
+	if err != nil {
+		return nil, err
+	}
+	cluster := &clusterv1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	cluster.Spec.Connection.KubeConfig = []byte(kubeconfig)
+	return cluster, nil
+}
+
+func (c *fakeClients) GetClusterKubeconfig(name string) (string, error) {
+	if kubeconfig, ok := c.kubeconfigs[name]; ok {
+		return kubeconfig, nil
+	}
+	return "", fmt.Errorf(clusterclient.ClusterNotExistsFormat, name)
+}
+
+func newTestManager(kubeconfigs map[string]string) (*Manager, *int) {
+	created := 0
+	m := NewManager(&fakeClients{kubeconfigs: kubeconfigs}, time.Minute)
+	m.newCache = func(region, name string) (*Cache, error) {
+		created++
+		return &Cache{stopCh: make(chan struct{}), lastAccess: time.Now().UnixNano()}, nil
+	}
+	return m, &created
+}
+
+func stopped(c *Cache) bool {
+	select {
+	case <-c.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestManagerGet(t *testing.T) {
+	kubeconfigs := map[string]string{"member": "v1"}
+	m, created := newTestManager(kubeconfigs)
+
+	c, err := m.Get("", "member")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := m.Get("", "member"); again != c || *created != 1 {
+		t.Errorf("expected the cache reused, created %d caches", *created)
+	}
+
+	kubeconfigs["member"] = "v2"
+	rebuilt, err := m.Get("", "member")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt == c || !stopped(c) || stopped(rebuilt) {
+		t.Errorf("expected the cache rebuilt and the stale one stopped once the kubeconfig is changed")
+	}
+
+	if _, err := m.Get("", "unknown"); err == nil {
+		t.Errorf("expected an error of unknown clusters")
+	}
+}
+
+func TestManagerEvict(t *testing.T) {
+	kubeconfigs := map[string]string{"idle": "v1", "removed": "v1", "changed": "v1", "active": "v1", "held": "v1"}
+	m, _ := newTestManager(kubeconfigs)
+	caches := make(map[string]*Cache)
+	for name := range kubeconfigs {
+		c, err := m.Get("", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		caches[name] = c
+	}
+
+	caches["idle"].lastAccess = time.Now().Add(-2 * time.Minute).UnixNano()
+	// caches held are not evicted for idleness
+	caches["held"].Hold()
+	caches["held"].lastAccess = time.Now().Add(-2 * time.Minute).UnixNano()
+	delete(kubeconfigs, "removed")
+	kubeconfigs["changed"] = "v2"
+	m.evict()
+
+	for name, c := range caches {
+		_, cached := m.caches[name]
+		if expected := name == "active" || name == "held"; cached != expected || stopped(c) == expected {
+			t.Errorf("expected cache of %s kept %v, got cached %v, stopped %v", name, expected, cached, stopped(c))
+		}
+	}
+}
+
+func TestCacheKubernetesSharedInformerFactory(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "config"}})
+	c := &Cache{informers: informers.NewSharedInformerFactory(client, 0), stopCh: make(chan struct{})}
+	defer c.Stop()
+
+	factory, err := c.KubernetesSharedInformerFactory(context.Background(), corev1.SchemeGroupVersion.WithResource("configmaps"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := factory.Core().V1().ConfigMaps().Lister().ConfigMaps("default").Get("config"); err != nil {
+		t.Errorf("expected configmaps cached, got %v", err)
+	}
+	if c.idle(time.Minute) {
+		t.Errorf("expected the cache accessed")
+	}
+
+	c.Stop()
+	c.Stop()
+	if _, err := c.KubernetesSharedInformerFactory(context.Background(), corev1.SchemeGroupVersion.WithResource("secrets")); err == nil {
+		t.Errorf("expected an error of informers not synced once the cache is stopped")
+	}
+}
+
+func TestCacheSnapshotSharedInformerFactory(t *testing.T) {
+	snapshotClient := snapshotfake.NewSimpleClientset(&snapshotv1.VolumeSnapshotClass{ObjectMeta: metav1.ObjectMeta{Name: "csi"}, Driver: "csi.example.com"})
+	discovery := fake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	c := &Cache{snapshotInformers: snapshotinformers.NewSharedInformerFactory(snapshotClient, 0), discovery: discovery, stopCh: make(chan struct{})}
+	defer c.Stop()
+
+	// clusters without snapshot CRDs have no snapshot classes
+	factory, err := c.SnapshotSharedInformerFactory(context.Background())
+	if err != nil || factory != nil {
+		t.Fatalf("expected no snapshot informers, got %v, %v", factory, err)
+	}
+
+	c = &Cache{snapshotInformers: c.snapshotInformers, discovery: discovery, stopCh: c.stopCh}
+	discovery.Resources = []*metav1.APIResourceList{{
+		GroupVersion: volumeSnapshotClassGVR.GroupVersion().String(),
+		APIResources: []metav1.APIResource{{Name: volumeSnapshotClassGVR.Resource}},
+	}}
+	factory, err = c.SnapshotSharedInformerFactory(context.Background())
+	if err != nil || factory == nil {
+		t.Fatalf("expected snapshot informers, got %v", err)
+	}
+	if _, err := factory.Snapshot().V1().VolumeSnapshotClasses().Lister().Get("csi"); err != nil {
+		t.Errorf("expected volumesnapshotclasses cached, got %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 
 	clusterv1alpha1 "captain/apis/cluster/v1alpha1"
@@ -21,6 +22,10 @@ var (
 	ClusterNotExistsFormat = "cluster %s not exists"
 )
 
+// WrapTransportFunc wraps transports of clients of requests to a member cluster, e.g. to trace requests
+// to member clusters. Clients of informers are not wrapped, since their requests outlive requests to the server
+type WrapTransportFunc func(regionName, clusterName string, rt http.RoundTripper) http.RoundTripper
+
 type innerCluster struct {
 	KubernetesURL *url.URL
 	CaptainURL    *url.URL
@@ -34,6 +39,8 @@ type ClusterClients interface {
 	Get(region, cluster string) (*clusterv1alpha1.Cluster, error)
 	GetInnerCluster(string) *innerCluster
 	GetClientSet(string, string) (*kubernetes.Clientset, error)
+	GetRESTConfig(string, string) (*rest.Config, error)
+	GetRequestRESTConfig(string, string) (*rest.Config, error)
 }
 
 type clusterClients struct {
@@ -43,6 +50,9 @@ type clusterClients struct {
 
 	// build a in memory cluster cache to speed things up
 	innerClusters map[string]*innerCluster
+
+	// wrapTransport wraps transports of clients of requests to member clusters, nil means they are not wrapped
+	wrapTransport WrapTransportFunc
 }
 
 func (c *clusterClients) IsHostCluster(cluster *clusterv1alpha1.Cluster) bool {
@@ -84,6 +94,16 @@ func (c *clusterClients) Get(regionName, clusterName string) (*clusterv1alpha1.C
 	}
 }
 
+// RegionAndName returns region of cluster and its name in the region, the reverse of Get.
+// Names of clusters in a region are prefixed with "{region}-"
+func RegionAndName(cluster *clusterv1alpha1.Cluster) (string, string) {
+	region := cluster.Labels[clusterv1alpha1.ClusterRegion]
+	if len(region) == 0 {
+		return "", cluster.Name
+	}
+	return region, strings.TrimPrefix(cluster.Name, region+"-")
+}
+
 func (c *clusterClients) GetInnerCluster(name string) *innerCluster {
 	c.RLock()
 	defer c.RUnlock()
@@ -95,6 +115,15 @@ func (c *clusterClients) GetInnerCluster(name string) *innerCluster {
 
 func (c *clusterClients) GetClientSet(regionName, clusterName string) (*kubernetes.Clientset, error) {
 	// TODO cache
+	r, err := c.GetRequestRESTConfig(regionName, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(r)
+}
+
+// GetRESTConfig returns the config of clients of a member cluster, requests are sent with its kubeconfig
+func (c *clusterClients) GetRESTConfig(regionName, clusterName string) (*rest.Config, error) {
 	cluster, err := c.Get(regionName, clusterName)
 	if err != nil {
 		return nil, err
@@ -103,41 +132,46 @@ func (c *clusterClients) GetClientSet(regionName, clusterName string) (*kubernet
 	if err != nil {
 		return nil, fmt.Errorf("get cluster kubeconfig restconfig err: %v", err)
 	}
-	return kubernetes.NewForConfig(r)
+	return r, nil
 }
 
-var c *clusterClients
-var lock sync.Mutex
-
-func NewClusterClients(clusterInformer clusterinformer.ClusterInformer) ClusterClients {
-
-	if c == nil {
-		lock.Lock()
-		defer lock.Unlock()
-
-		if c != nil {
-			return c
-		}
-
-		c = &clusterClients{
-			clusterMap:        map[string]*clusterv1alpha1.Cluster{},
-			clusterKubeconfig: map[string]string{},
-			innerClusters:     make(map[string]*innerCluster),
-		}
-
-		clusterInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-			AddFunc: func(obj interface{}) {
-				c.addCluster(obj)
-			},
-			UpdateFunc: func(oldObj, newObj interface{}) {
-				c.removeCluster(oldObj)
-				c.addCluster(newObj)
-			},
-			DeleteFunc: func(obj interface{}) {
-				c.removeCluster(obj)
-			},
+// GetRequestRESTConfig returns the config of clients of requests to a member cluster, transports are
+// wrapped by the wrapper the clients are created with, so are typed clients of GetClientSet
+func (c *clusterClients) GetRequestRESTConfig(regionName, clusterName string) (*rest.Config, error) {
+	r, err := c.GetRESTConfig(regionName, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if c.wrapTransport != nil {
+		r.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return c.wrapTransport(regionName, clusterName, rt)
 		})
 	}
+	return r, nil
+}
+
+// NewClusterClients creates clients of clusters watched by clusterInformer, transports of clients of
+// requests to member clusters are wrapped by wrapTransport if it is not nil
+func NewClusterClients(clusterInformer clusterinformer.ClusterInformer, wrapTransport WrapTransportFunc) ClusterClients {
+	c := &clusterClients{
+		clusterMap:        map[string]*clusterv1alpha1.Cluster{},
+		clusterKubeconfig: map[string]string{},
+		innerClusters:     make(map[string]*innerCluster),
+		wrapTransport:     wrapTransport,
+	}
+
+	clusterInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.addCluster(obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.removeCluster(oldObj)
+			c.addCluster(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.removeCluster(obj)
+		},
+	})
 
 	return c
 }